@@ -0,0 +1,338 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// AssetProvider is implemented by providers that can list the assets of the latest release.
+// It is used by Add to propose the package configuration.
+type AssetProvider interface {
+	GetLatestAssets(ctx context.Context, pkg Package) (version string, assets []string, err error)
+}
+
+var (
+	// common spellings of GOOS values in asset names
+	osAliases = map[string][]string{
+		"linux":   {"linux"},
+		"darwin":  {"darwin", "macos", "mac", "osx", "apple"},
+		"windows": {"windows", "win64", "win32", "win"},
+		"freebsd": {"freebsd"},
+		"openbsd": {"openbsd"},
+		"netbsd":  {"netbsd"},
+		"android": {"android"},
+	}
+	// common spellings of GOARCH values in asset names
+	archAliases = map[string][]string{
+		"amd64":   {"amd64", "x86_64", "x86-64", "x64", "64bit"},
+		"arm64":   {"arm64", "aarch64", "armv8"},
+		"386":     {"386", "i386", "i686", "x86", "32bit"},
+		"arm":     {"armv7", "armv7l", "armhf", "armv6", "arm"},
+		"ppc64le": {"ppc64le"},
+		"s390x":   {"s390x"},
+		"riscv64": {"riscv64"},
+	}
+	// assets that are never a package (checksums, signatures, metadata, installers)
+	ignoredAssetSuffixes = []string{
+		".sha256", ".sha256sum", ".sha512", ".md5", ".sig", ".asc", ".pem", ".cert", ".crt",
+		".sbom", ".spdx", ".json", ".txt", ".yaml", ".yml", ".pub", ".intoto.jsonl",
+		".dmg", ".msi", ".pkg", ".apk", ".snap", ".flatpak", ".zst", ".7z", ".bz2",
+	}
+	// asset suffixes mapped to the archive format (or install type for AppImages)
+	assetFormats = []struct {
+		suffix        string
+		archiveFormat string
+		installType   string
+		score         int
+	}{
+		{suffix: ".tar.gz", archiveFormat: "tar.gz", score: 3},
+		{suffix: ".tgz", archiveFormat: "tar.gz", score: 3},
+		{suffix: ".tar.xz", archiveFormat: "tar.xz", score: 3},
+		{suffix: ".tar", archiveFormat: "tar", score: 3},
+		{suffix: ".zip", archiveFormat: "zip", score: 2},
+		{suffix: ".appimage", installType: InstallTypeAppImage, score: 1},
+		{suffix: ".deb", archiveFormat: "deb", score: 0},
+		{suffix: ".rpm", archiveFormat: "rpm", score: 0},
+	}
+)
+
+// assetCandidate is a release asset that can be installed on this platform.
+type assetCandidate struct {
+	Name          string
+	Score         int
+	ArchiveFormat string
+	InstallType   string
+	// spelling of os and arch inside of the asset name
+	osToken   string
+	archToken string
+}
+
+// aliasPattern matches an alias if it is not part of a longer word.
+func aliasPattern(alias string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(^|[^a-z0-9])(` + regexp.QuoteMeta(alias) + `)([^a-z0-9]|$)`)
+}
+
+// findAlias searches all aliases in name (longest first) and returns the matching key
+// and the spelling inside of name. Found aliases are removed from the name
+// so that shorter aliases (e.g. x86 in x86_64) are not matched afterwards.
+// Aliases of different keys in the same name are reported as conflict.
+func findAlias(name string, aliases map[string][]string) (key string, token string, conflict bool) {
+	type alias struct {
+		key   string
+		alias string
+	}
+	all := []alias{}
+	for key, values := range aliases {
+		for _, value := range values {
+			all = append(all, alias{key: key, alias: value})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if len(all[i].alias) != len(all[j].alias) {
+			return len(all[i].alias) > len(all[j].alias)
+		}
+		return all[i].alias < all[j].alias
+	})
+	rest := name
+	for _, alias := range all {
+		pattern := aliasPattern(alias.alias)
+		match := pattern.FindStringSubmatch(rest)
+		if match == nil {
+			continue
+		}
+		if key == "" {
+			key = alias.key
+			token = match[2]
+		} else if key != alias.key {
+			conflict = true
+		}
+		rest = pattern.ReplaceAllString(rest, "${1} ${3}")
+	}
+	return key, token, conflict
+}
+
+// scoreAsset checks if the asset can be installed on goos/goarch with the given libc.
+// It returns the candidate and false if the asset does not fit.
+func scoreAsset(name string, goos string, goarch string, libc string) (assetCandidate, bool) {
+	candidate := assetCandidate{Name: name}
+	lower := strings.ToLower(name)
+	for _, suffix := range ignoredAssetSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return candidate, false
+		}
+	}
+
+	osKey, osToken, conflict := findAlias(name, osAliases)
+	if conflict || (osKey != "" && osKey != goos) {
+		return candidate, false
+	}
+	if osKey != "" {
+		candidate.Score += 10
+		candidate.osToken = osToken
+	}
+
+	archKey, archToken, conflict := findAlias(name, archAliases)
+	if conflict || (archKey != "" && archKey != goarch) {
+		if !(goos == "darwin" && strings.Contains(lower, "universal")) {
+			return candidate, false
+		}
+	}
+	if archKey == goarch {
+		candidate.Score += 10
+		candidate.archToken = archToken
+	} else if strings.Contains(lower, "universal") {
+		candidate.Score += 5
+	}
+	if osKey == "" && archKey == "" {
+		// nothing in the name hints at the platform
+		candidate.Score -= 5
+	}
+
+	switch {
+	case strings.Contains(lower, "musl"):
+		if libc == LibcMusl {
+			candidate.Score += 3
+		} else {
+			// static musl builds run everywhere, but prefer the native build
+			candidate.Score += 1
+		}
+	case strings.Contains(lower, "gnu") || strings.Contains(lower, "glibc"):
+		if libc == LibcMusl {
+			candidate.Score -= 5
+		} else {
+			candidate.Score += 2
+		}
+	}
+
+	if goos != "windows" && strings.HasSuffix(lower, ".exe") {
+		return candidate, false
+	}
+	for _, format := range assetFormats {
+		if strings.HasSuffix(lower, format.suffix) {
+			candidate.ArchiveFormat = format.archiveFormat
+			candidate.InstallType = format.installType
+			candidate.Score += format.score
+			return candidate, true
+		}
+	}
+	// no known archive, the asset is the binary itself
+	candidate.Score += 1
+	return candidate, true
+}
+
+// rankAssets returns all assets usable on this platform ordered by their score.
+func rankAssets(assets []string, goos string, goarch string, libc string) []assetCandidate {
+	candidates := []assetCandidate{}
+	for _, asset := range assets {
+		candidate, ok := scoreAsset(asset, goos, goarch, libc)
+		if ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates
+}
+
+// applyTo fills the asset pattern, archive format, bin pattern and GOOS/GOARCH maps
+// of the package so that the candidate (and the same asset of future versions) is found.
+func (candidate *assetCandidate) applyTo(pkg *Package, version string) {
+	const (
+		osMarker      = "\x00os\x00"
+		archMarker    = "\x00arch\x00"
+		versionMarker = "\x00version\x00"
+	)
+	pattern := candidate.Name
+	if trimmed := strings.TrimPrefix(version, "v"); trimmed != "" {
+		pattern = strings.Replace(pattern, trimmed, versionMarker, 1)
+	}
+	if candidate.osToken != "" {
+		pattern = replaceToken(pattern, candidate.osToken, osMarker)
+		if candidate.osToken != runtime.GOOS {
+			pkg.GOOS[runtime.GOOS] = candidate.osToken
+		}
+	}
+	if candidate.archToken != "" {
+		pattern = replaceToken(pattern, candidate.archToken, archMarker)
+		if candidate.archToken != runtime.GOARCH {
+			pkg.GOARCH[runtime.GOARCH] = candidate.archToken
+		}
+	}
+	pattern = regexp.QuoteMeta(pattern)
+	pattern = strings.ReplaceAll(pattern, osMarker, "${goos}")
+	pattern = strings.ReplaceAll(pattern, archMarker, "${goarch}")
+	pattern = strings.ReplaceAll(pattern, versionMarker, ".+")
+
+	pkg.AssetPattern = "^" + pattern + "$"
+	pkg.ArchiveFormat = candidate.ArchiveFormat
+	pkg.InstallType = candidate.InstallType
+	if candidate.ArchiveFormat != "" {
+		pkg.BinPattern = `(^|/)${name}(\.exe)?$`
+	} else {
+		pkg.BinPattern = "${name}"
+	}
+}
+
+// replaceToken replaces the first standalone occurrence of token.
+func replaceToken(name string, token string, marker string) string {
+	pattern := regexp.MustCompile(`(^|[^A-Za-z0-9])` + regexp.QuoteMeta(token) + `([^A-Za-z0-9]|$)`)
+	location := pattern.FindStringSubmatchIndex(name)
+	if location == nil {
+		return name
+	}
+	return name[:location[3]] + marker + name[location[4]:]
+}
+
+// newAddPackage returns the minimal package of Add, the provider is the host of the url.
+func newAddPackage(name string, url string) Package {
+	providerName, _, _ := strings.Cut(url, "/")
+	return Package{
+		PackageV2: PackageV2{
+			SchemaVersion: PackageSchemaVersion,
+			Name:          name,
+			URL:           url,
+			Provider:      providerName,
+		},
+	}
+}
+
+// latestCandidates returns the latest release and its assets usable on this platform, best first.
+func latestCandidates(ctx context.Context, provider AssetProvider, pkg Package) (string, []assetCandidate, error) {
+	version, assets, err := provider.GetLatestAssets(ctx, pkg)
+	if err != nil {
+		return "", nil, err
+	}
+	candidates := rankAssets(assets, runtime.GOOS, runtime.GOARCH, hostLibc())
+	if len(candidates) == 0 {
+		return version, nil, fmt.Errorf("%w: no asset of release %s matches %s/%s", ErrProviderFetch, version, runtime.GOOS, runtime.GOARCH)
+	}
+	return version, candidates, nil
+}
+
+// AddCandidates returns the latest release of a new package and its assets matching this platform, best first.
+// Providers which cannot list release assets return no candidates.
+func (manager *ManagerImpl) AddCandidates(ctx context.Context, name string, url string) (string, []string, error) {
+	pkg := newAddPackage(name, url)
+	provider, ok := manager.Providers[pkg.Provider].(AssetProvider)
+	if !ok {
+		return "", nil, nil
+	}
+	version, candidates, err := latestCandidates(ctx, provider, pkg)
+	if err != nil {
+		return version, nil, err
+	}
+	assets := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		assets = append(assets, candidate.Name)
+	}
+	return version, assets, nil
+}
+
+// proposePackage fills the package with the settings for the asset of the release version.
+// The provider is only queried for the latest release if the version or the asset is empty,
+// an empty asset selects the best matching one.
+func (manager *ManagerImpl) proposePackage(ctx context.Context, pkg *Package, provider AssetProvider, version string, asset string) error {
+	var selected assetCandidate
+	if version != "" && asset != "" {
+		candidate, ok := scoreAsset(asset, runtime.GOOS, runtime.GOARCH, hostLibc())
+		if !ok {
+			return fmt.Errorf("%w: asset %s of release %s does not match %s/%s", ErrInvalidInput, asset, version, runtime.GOOS, runtime.GOARCH)
+		}
+		selected = candidate
+	} else {
+		var candidates []assetCandidate
+		var err error
+		version, candidates, err = latestCandidates(ctx, provider, *pkg)
+		if err != nil {
+			return err
+		}
+		selected = candidates[0]
+		if asset != "" {
+			index := slices.IndexFunc(candidates, func(candidate assetCandidate) bool {
+				return candidate.Name == asset
+			})
+			if index < 0 {
+				return fmt.Errorf("%w: asset %s of release %s does not match %s/%s", ErrInvalidInput, asset, version, runtime.GOOS, runtime.GOARCH)
+			}
+			selected = candidates[index]
+		}
+	}
+	manager.logger.Info().Msgf("use asset %s of release %s", selected.Name, version)
+	if pkg.GOOS == nil {
+		pkg.GOOS = make(map[string]string)
+	}
+	if pkg.GOARCH == nil {
+		pkg.GOARCH = make(map[string]string)
+	}
+	selected.applyTo(pkg, version)
+	return nil
+}
@@ -0,0 +1,234 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testRipgrepAssets = []string{
+		"ripgrep-14.1.0-aarch64-apple-darwin.tar.gz",
+		"ripgrep-14.1.0-aarch64-apple-darwin.tar.gz.sha256",
+		"ripgrep-14.1.0-aarch64-unknown-linux-gnu.tar.gz",
+		"ripgrep-14.1.0-i686-pc-windows-msvc.zip",
+		"ripgrep-14.1.0-x86_64-apple-darwin.tar.gz",
+		"ripgrep-14.1.0-x86_64-pc-windows-msvc.zip",
+		"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz",
+		"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz.sha256",
+		"ripgrep_14.1.0-1_amd64.deb",
+	}
+	testGoreleaserAssets = []string{
+		"checksums.txt",
+		"tool_1.2.3_Darwin_arm64.tar.gz",
+		"tool_1.2.3_Linux_arm64.tar.gz",
+		"tool_1.2.3_Linux_i386.tar.gz",
+		"tool_1.2.3_Linux_x86_64.tar.gz",
+		"tool_1.2.3_Windows_x86_64.zip",
+		"tool_1.2.3_linux_amd64.apk",
+	}
+)
+
+func TestRankAssets(t *testing.T) {
+	tests := []struct {
+		name   string
+		assets []string
+		goos   string
+		goarch string
+		libc   string
+		result []string
+	}{
+		{
+			name:   "ripgrep-linux-amd64",
+			assets: testRipgrepAssets,
+			goos:   "linux",
+			goarch: "amd64",
+			libc:   LibcGlibc,
+			result: []string{"ripgrep-14.1.0-x86_64-unknown-linux-musl.tar.gz", "ripgrep_14.1.0-1_amd64.deb"},
+		},
+		{
+			name:   "ripgrep-linux-arm64",
+			assets: testRipgrepAssets,
+			goos:   "linux",
+			goarch: "arm64",
+			libc:   LibcGlibc,
+			result: []string{"ripgrep-14.1.0-aarch64-unknown-linux-gnu.tar.gz"},
+		},
+		{
+			name:   "ripgrep-darwin-arm64",
+			assets: testRipgrepAssets,
+			goos:   "darwin",
+			goarch: "arm64",
+			result: []string{"ripgrep-14.1.0-aarch64-apple-darwin.tar.gz"},
+		},
+		{
+			name:   "goreleaser-linux-amd64",
+			assets: testGoreleaserAssets,
+			goos:   "linux",
+			goarch: "amd64",
+			libc:   LibcGlibc,
+			result: []string{"tool_1.2.3_Linux_x86_64.tar.gz"},
+		},
+		{
+			name:   "goreleaser-linux-386",
+			assets: testGoreleaserAssets,
+			goos:   "linux",
+			goarch: "386",
+			libc:   LibcGlibc,
+			result: []string{"tool_1.2.3_Linux_i386.tar.gz"},
+		},
+		{
+			name:   "goreleaser-windows-amd64",
+			assets: testGoreleaserAssets,
+			goos:   "windows",
+			goarch: "amd64",
+			result: []string{"tool_1.2.3_Windows_x86_64.zip"},
+		},
+		{
+			name:   "nothing-matches",
+			assets: testGoreleaserAssets,
+			goos:   "freebsd",
+			goarch: "amd64",
+			result: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			names := []string{}
+			for _, candidate := range rankAssets(test.assets, test.goos, test.goarch, test.libc) {
+				names = append(names, candidate.Name)
+			}
+			assert.Equal(t, test.result, names)
+		})
+	}
+}
+
+func TestApplyCandidate(t *testing.T) {
+	osName := strings.ToUpper(runtime.GOOS[:1]) + runtime.GOOS[1:]
+	assetName := fmt.Sprintf("tool_1.2.3_%s_%s.tar.gz", osName, runtime.GOARCH)
+	candidates := rankAssets([]string{assetName}, runtime.GOOS, runtime.GOARCH, LibcGlibc)
+	if !assert.Len(t, candidates, 1) {
+		return
+	}
+	pkg := dummyPackage()
+	pkg.Name = "tool"
+	candidates[0].applyTo(pkg, "v1.2.3")
+
+	assert.Equal(t, `^tool_.+_${goos}_${goarch}\.tar\.gz$`, pkg.AssetPattern)
+	assert.Equal(t, "tar.gz", pkg.ArchiveFormat)
+	assert.Equal(t, osName, pkg.GOOS[runtime.GOOS])
+	assert.NotContains(t, pkg.GOARCH, runtime.GOARCH, "the arch is spelled like GOARCH and needs no mapping")
+
+	for _, version := range []string{"v1.2.3", "v2.0.0"} {
+		asset := strings.ReplaceAll(assetName, "1.2.3", strings.TrimPrefix(version, "v"))
+		pattern := regexp.MustCompile(pkg.patternExpand(pkg.AssetPattern, version))
+		assert.True(t, pattern.MatchString(asset), "pattern %s should match %s", pattern, asset)
+	}
+	binPattern := regexp.MustCompile(pkg.patternExpand(pkg.BinPattern, "v1.2.3"))
+	assert.True(t, binPattern.MatchString("tool_1.2.3/tool"))
+	assert.False(t, binPattern.MatchString("tool_1.2.3/tool.md"))
+}
+
+func TestManagerAddPropose(t *testing.T) {
+	assets := []string{
+		fmt.Sprintf("tool-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
+		fmt.Sprintf("tool-%s-%s", runtime.GOOS, runtime.GOARCH),
+		"tool.sha256",
+	}
+	tests := []struct {
+		name          string
+		version       string
+		asset         string
+		archiveFormat string
+		err           error
+	}{
+		{
+			name:          "best-match",
+			archiveFormat: "tar.gz",
+		},
+		{
+			name:          "selected-asset",
+			asset:         assets[1],
+			archiveFormat: "",
+		},
+		{
+			name:  "other-platform",
+			asset: "tool.sha256",
+			err:   ErrInvalidInput,
+		},
+		{
+			name:          "candidate",
+			version:       "v1.0.0",
+			asset:         assets[1],
+			archiveFormat: "",
+		},
+		{
+			name:    "candidate-other-platform",
+			version: "v1.0.0",
+			asset:   "tool.sha256",
+			err:     ErrInvalidInput,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			provider := &DummyProvider{LatestPackages: map[string]string{"tool": "v1.0.0"}}
+			if test.version == "" {
+				// the release of a candidate is not queried again
+				provider.Assets = map[string][]string{"tool": assets}
+			}
+			manager.Providers[dummyProviderName] = provider
+			err := manager.Add(context.Background(), "tool", dummyProviderName+"/owner/tool", test.version, test.asset)
+			if !assert.ErrorIs(t, err, test.err) || test.err != nil {
+				return
+			}
+			pkg := Package{}
+			err = loadYaml(path.Join(manager.config.PackagesFolder, "tool.yaml"), &pkg)
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.archiveFormat, pkg.ArchiveFormat)
+				assert.Equal(t, `^tool-${goos}-${goarch}`+map[string]string{"tar.gz": `\.tar\.gz`}[test.archiveFormat]+"$", pkg.AssetPattern)
+			}
+		})
+	}
+}
+
+func TestManagerAddCandidates(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.Providers[dummyProviderName] = &DummyProvider{
+		LatestPackages: map[string]string{"tool": "v1.0.0"},
+		Assets: map[string][]string{"tool": {
+			fmt.Sprintf("tool-%s-%s", runtime.GOOS, runtime.GOARCH),
+			fmt.Sprintf("tool-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
+			"tool.sha256",
+		}},
+	}
+	version, assets, err := manager.AddCandidates(context.Background(), "tool", dummyProviderName+"/owner/tool")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.0", version)
+	assert.Equal(t, []string{
+		fmt.Sprintf("tool-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH),
+		fmt.Sprintf("tool-%s-%s", runtime.GOOS, runtime.GOARCH),
+	}, assets, "the best match is first")
+
+	_, assets, err = manager.AddCandidates(context.Background(), "tool", "unknown.com/owner/tool")
+	assert.NoError(t, err)
+	assert.Empty(t, assets, "providers without assets have no candidates")
+}
+
+func TestManagerAddProviderError(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.Providers[dummyProviderName] = &DummyProvider{}
+	err := manager.Add(context.Background(), "tool", dummyProviderName+"/owner/tool", "", "")
+	assert.NoError(t, err, "a minimal package should be written if the provider fails")
+	_, err = os.Stat(path.Join(manager.config.PackagesFolder, "tool.yaml"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,241 @@
+package bpm
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	AdoptMethodCommand  = "command"
+	AdoptMethodChecksum = "checksum"
+	// number of releases (newest first) downloaded to compare checksums
+	adoptChecksumReleases = 10
+	// maximum runtime of the version command
+	adoptCommandTimeout = 10 * time.Second
+)
+
+var (
+	defaultVersionCommand = []string{"--version"}
+	defaultVersionRegex   = `v?\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.-]+)?`
+)
+
+// Adopt records the versions of binaries which are already in the bin folder but not installed
+// by bpm, so that update takes over. The version is detected by running the version command of the
+// package and, if that fails, by comparing the checksum with the binaries of the latest releases.
+// Without names all packages with an existing binary that are not installed are adopted.
+// Failures are collected in an *UpdateError, the results are sorted by name.
+func (manager *ManagerImpl) Adopt(ctx context.Context, packageNames []string) ([]AdoptResult, error) {
+	results := []AdoptResult{}
+	adoptErr := &UpdateError{Operation: "adopt"}
+	for _, name := range packageNames {
+		if _, ok := manager.Packages[name]; !ok {
+			return results, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+		}
+	}
+	for _, name := range manager.sortedPackageNames() {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if len(packageNames) > 0 && !manager.inPackageList(name, packageNames) {
+			continue
+		}
+		pkg := manager.Packages[name]
+		logger := manager.logger.With().Str("pkg", name).Logger()
+		if version := manager.StateFile.Packages[name]; version != "" {
+			if len(packageNames) > 0 {
+				adoptErr.Failed = append(adoptErr.Failed, &PackageError{
+					Name: name,
+					Err:  fmt.Errorf("%w: version %s is already installed", ErrPackageAdopt, version),
+				})
+			}
+			continue
+		}
+		if manager.isShimmed(name) {
+			logger.Debug().Msg("shims cannot be adopted")
+			continue
+		}
+		if _, err := os.Stat(manager.binPath(&pkg)); err != nil {
+			if len(packageNames) > 0 {
+				adoptErr.Failed = append(adoptErr.Failed, &PackageError{
+					Name: name,
+					Err:  fmt.Errorf("%w: %s", ErrPackageAdopt, err),
+				})
+			} else {
+				logger.Debug().Msg("no binary to adopt")
+			}
+			continue
+		}
+		result, err := manager.adopt(ctx, &pkg)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		} else if err != nil {
+			logger.Error().Msgf("cannot adopt package: %s. Skipping...", err)
+			result.Error = err.Error()
+			adoptErr.Failed = append(adoptErr.Failed, &PackageError{Name: name, Err: err})
+		} else {
+			adoptErr.Succeeded++
+			adoptErr.Changed++
+		}
+		results = append(results, result)
+	}
+	if len(adoptErr.Failed) > 0 {
+		return results, adoptErr
+	}
+	return results, nil
+}
+
+// adopt detects the version of the existing binary of the package and records it in the state.
+func (manager *ManagerImpl) adopt(ctx context.Context, pkg *Package) (result AdoptResult, err error) {
+	result = AdoptResult{
+		Name: pkg.Name,
+		Path: manager.binPath(pkg),
+	}
+	logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
+	provider, ok := manager.Providers[pkg.Provider]
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+	}
+	releases, err := releaseVersions(ctx, provider, *pkg)
+	if err != nil {
+		return result, err
+	}
+
+	version, err := manager.versionFromCommand(ctx, pkg, result.Path)
+	if err == nil {
+		if tag, ok := matchReleaseVersion(releases, version); ok {
+			result.Version = tag
+			result.Method = AdoptMethodCommand
+		} else {
+			logger.Info().Msgf("version %s of the binary matches no release", version)
+		}
+	} else {
+		logger.Info().Msgf("cannot get version from binary: %s", err)
+	}
+	if result.Version == "" {
+		result.Version, err = manager.versionFromChecksum(ctx, provider, pkg, result.Path, releases)
+		if err != nil {
+			return result, err
+		}
+		result.Method = AdoptMethodChecksum
+	}
+	logger.Info().Msgf("adopt version %s (detected by %s)", result.Version, result.Method)
+	if manager.config.DryRun {
+		return result, nil
+	}
+	manager.StateFile.Packages[pkg.Name] = result.Version
+	return result, manager.recordChecksum(pkg.Name, result.Path)
+}
+
+// versionFromCommand runs the binary with the version command and extracts the version from the output.
+// If the version regex has a capture group, the first group is the version.
+func (manager *ManagerImpl) versionFromCommand(ctx context.Context, pkg *Package, path string) (string, error) {
+	args := pkg.VersionCommand
+	if len(args) == 0 {
+		args = defaultVersionCommand
+	}
+	pattern := pkg.VersionRegex
+	if pattern == "" {
+		pattern = defaultVersionRegex
+	}
+	versionRegex, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid version_regex: %s", ErrPackageAdopt, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, adoptCommandTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s %s: %s", ErrPackageAdopt, path, strings.Join(args, " "), err)
+	}
+	match := versionRegex.FindSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("%w: output of %s %s does not match %s", ErrPackageAdopt, path, strings.Join(args, " "), pattern)
+	}
+	if len(match) > 1 {
+		return string(match[1]), nil
+	}
+	return string(match[0]), nil
+}
+
+// matchReleaseVersion returns the release tag of version. Tags and version are compared
+// as semantic versions if possible, so "1.2.0" matches the tag "v1.2.0".
+func matchReleaseVersion(releases []string, version string) (string, bool) {
+	semVersion, semErr := semver.NewVersion(version)
+	for _, tag := range releases {
+		if tag == version || strings.TrimPrefix(tag, "v") == strings.TrimPrefix(version, "v") {
+			return tag, true
+		}
+		if semErr != nil {
+			continue
+		}
+		tagVersion, err := semver.NewVersion(tag)
+		if err == nil && tagVersion.Equal(semVersion) {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// versionFromChecksum downloads the newest releases and compares the sha256 of their binaries with the binary at path.
+func (manager *ManagerImpl) versionFromChecksum(ctx context.Context, provider PackageProvider, pkg *Package, path string, releases []string) (string, error) {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return "", err
+	}
+	if len(releases) > adoptChecksumReleases {
+		releases = releases[:adoptChecksumReleases]
+	}
+	for _, version := range releases {
+		releaseChecksum, err := manager.releaseChecksum(ctx, provider, pkg, version)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		} else if err != nil {
+			manager.logger.Debug().Str("pkg", pkg.Name).Msgf("cannot fetch %s: %s", version, err)
+			continue
+		}
+		if releaseChecksum == checksum {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("%w: binary matches none of the last %d releases", ErrPackageAdopt, len(releases))
+}
+
+// releaseChecksum fetches the binary of the release into a new tmp dir and returns its sha256.
+func (manager *ManagerImpl) releaseChecksum(ctx context.Context, provider PackageProvider, pkg *Package, version string) (checksum string, err error) {
+	manager.tmpDir, err = os.MkdirTemp("", "bpm-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		os.RemoveAll(manager.tmpDir)
+		manager.tmpDir = ""
+	}()
+	path, err := manager.fetchBinary(ctx, provider, pkg, version)
+	if err != nil {
+		return "", err
+	}
+	return fileChecksum(path)
+}
+
+// fileChecksum returns the hex encoded sha256 of the file.
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
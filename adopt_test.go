@@ -0,0 +1,144 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeBinary writes an executable script into the bin folder of the manager.
+func writeBinary(t *testing.T, manager *ManagerImpl, name string, content string) string {
+	pkg := Package{}
+	pkg.Name = name
+	path := manager.binPath(&pkg)
+	err := os.WriteFile(path, []byte(content), 0o755)
+	assert.NoError(t, err)
+	return path
+}
+
+func readTestFile(t *testing.T, subPaths ...string) string {
+	content, err := os.ReadFile(getTestPath(subPaths...))
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestMatchReleaseVersion(t *testing.T) {
+	releases := []string{"v1.2.0", "v1.1.0", "nightly"}
+	tests := []struct {
+		version string
+		tag     string
+		ok      bool
+	}{
+		{version: "v1.1.0", tag: "v1.1.0", ok: true},
+		{version: "1.2.0", tag: "v1.2.0", ok: true},
+		{version: "1.2", tag: "v1.2.0", ok: true},
+		{version: "nightly", tag: "nightly", ok: true},
+		{version: "1.3.0", ok: false},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			tag, ok := matchReleaseVersion(releases, test.version)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.tag, tag)
+		})
+	}
+}
+
+func TestManagerAdopt(t *testing.T) {
+	tests := []struct {
+		name    string
+		binary  string
+		command []string
+		regex   string
+		result  AdoptResult
+		err     error
+	}{
+		{
+			name:   "command",
+			binary: "#!/bin/sh\necho 'dummy version 1.1.0 (abcdef)'\n",
+			result: AdoptResult{Version: "v1.1.0", Method: AdoptMethodCommand},
+		},
+		{
+			name:    "custom-command",
+			binary:  "#!/bin/sh\nif [ \"$1\" = version ]; then echo 'build 42, release 1.0.0'; fi\n",
+			command: []string{"version"},
+			regex:   `release (\S+)`,
+			result:  AdoptResult{Version: "v1.0.0", Method: AdoptMethodCommand},
+		},
+		{
+			name:   "checksum",
+			binary: readTestFile(t, "files", "dummy-bin.sh"),
+			result: AdoptResult{Version: "v1.2.0", Method: AdoptMethodChecksum},
+		},
+		{
+			name:   "unknown-version",
+			binary: "#!/bin/sh\necho 'dummy 9.9.9'\n",
+			result: AdoptResult{},
+			err:    ErrPackageAdopt,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getTestManager(t, testManagerOptions{provider: dummyReleaseProvider()})
+			pkg := dummyPackage()
+			pkg.VersionCommand = test.command
+			pkg.VersionRegex = test.regex
+			manager.Packages[pkg.Name] = *pkg
+			test.result.Name = pkg.Name
+			test.result.Path = writeBinary(t, manager, pkg.Name, test.binary)
+			results, err := manager.Adopt(context.Background(), []string{pkg.Name})
+			assert.ErrorIs(t, err, test.err)
+			if test.err != nil {
+				test.result.Error = results[0].Error
+				assert.NotEmpty(t, test.result.Error)
+			}
+			assert.Equal(t, []AdoptResult{test.result}, results)
+			assert.Equal(t, test.result.Version, manager.StateFile.Packages[pkg.Name])
+		})
+	}
+}
+
+func TestManagerAdoptAll(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyReleaseProvider()})
+	pkg := dummyPackage()
+	installed := dummyPackage()
+	installed.Name = "installed"
+	manager.Packages[installed.Name] = *installed
+	manager.StateFile.Packages[installed.Name] = "v1.0.0"
+	writeBinary(t, manager, installed.Name, "#!/bin/sh\necho 1.2.0\n")
+	missing := dummyPackage()
+	missing.Name = "missing"
+	manager.Packages[missing.Name] = *missing
+	writeBinary(t, manager, pkg.Name, "#!/bin/sh\necho 1.2.0\n")
+
+	results, err := manager.Adopt(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1, "installed packages and missing binaries are skipped") {
+		assert.Equal(t, pkg.Name, results[0].Name)
+	}
+	assert.Equal(t, "v1.2.0", manager.StateFile.Packages[pkg.Name])
+	assert.Equal(t, "v1.0.0", manager.StateFile.Packages[installed.Name])
+
+	// explicit names fail if the package cannot be adopted
+	results, err = manager.Adopt(context.Background(), []string{installed.Name, missing.Name})
+	assert.ErrorIs(t, err, ErrPackageAdopt)
+	assert.Empty(t, results)
+	assert.ErrorContains(t, err, "2 of 2 packages failed to adopt")
+
+	_, err = manager.Adopt(context.Background(), []string{"unknown"})
+	assert.ErrorIs(t, err, ErrPackageNotFound)
+}
+
+func TestManagerAdoptDryRun(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyReleaseProvider(), dryRun: true})
+	pkg := dummyPackage()
+	writeBinary(t, manager, pkg.Name, "#!/bin/sh\necho 1.2.0\n")
+	results, err := manager.Adopt(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "v1.2.0", results[0].Version)
+	}
+	assert.NotContains(t, manager.StateFile.Packages, pkg.Name)
+}
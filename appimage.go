@@ -0,0 +1,250 @@
+package bpm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	InstallTypeBinary   = ""
+	InstallTypeAppImage = "appimage"
+
+	appImageExtractTimeout = 2 * time.Minute
+)
+
+var (
+	appImageIconExtensions = []string{".png", ".svg", ".xpm"}
+)
+
+// installPackage installs the fetched (and extracted) file depending on the install type of the package.
+func (manager *ManagerImpl) installPackage(ctx context.Context, pkg *Package, version string, sourceFile string, force bool) error {
+	switch pkg.InstallType {
+	case InstallTypeBinary:
+		return manager.install(pkg, version, sourceFile, force)
+	case InstallTypeAppImage:
+		return manager.installAppImage(ctx, pkg, version, sourceFile, force)
+	default:
+		return fmt.Errorf("%w: unknown install type %q", ErrPackageInstall, pkg.InstallType)
+	}
+}
+
+// installAppImage installs the AppImage as binary and optionally adds
+// the bundled desktop file and icon to the data folder.
+// The desktop integration is installed before the binary is replaced, so a failing
+// integration keeps the installed version working.
+func (manager *ManagerImpl) installAppImage(ctx context.Context, pkg *Package, version string, sourceFile string, force bool) error {
+	var files []string
+	if pkg.Desktop {
+		// the AppImage is executed for the extraction, it has to be checked before
+		err := manager.verifyBinary(pkg, sourceFile, force)
+		if err != nil {
+			return err
+		}
+		files, err = manager.installDesktopIntegration(ctx, pkg, sourceFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := manager.install(pkg, version, sourceFile, force)
+	if err != nil {
+		// keep the files of the installed version only
+		for _, file := range files {
+			if !manager.inPackageList(file, manager.StateFile.Files[pkg.Name]) {
+				manager.removeFile(file)
+			}
+		}
+		return err
+	}
+	manager.setPackageFiles(pkg.Name, files)
+	return nil
+}
+
+// installDesktopIntegration extracts the AppImage into the tmp dir and copies the
+// desktop file and the icon into the data folder. It returns the installed files.
+func (manager *ManagerImpl) installDesktopIntegration(ctx context.Context, pkg *Package, sourceFile string) ([]string, error) {
+	logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
+	err := os.Chmod(sourceFile, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	sourceFile, err = filepath.Abs(sourceFile)
+	if err != nil {
+		return nil, err
+	}
+
+	extractDir := filepath.Join(manager.tmpDir, "appimage")
+	err = os.MkdirAll(extractDir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, appImageExtractTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, sourceFile, "--appimage-extract")
+	cmd.Dir = extractDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot extract appimage: %s: %s", ErrPackageInstall, err, output)
+	}
+	root := filepath.Join(extractDir, "squashfs-root")
+
+	desktopFiles, err := filepath.Glob(filepath.Join(root, "*.desktop"))
+	if err != nil {
+		return nil, err
+	}
+	if len(desktopFiles) == 0 {
+		return nil, fmt.Errorf("%w: appimage does not contain a desktop file", ErrPackageInstall)
+	}
+	content, err := os.ReadFile(desktopFiles[0])
+	if err != nil {
+		return nil, err
+	}
+	// the icon is namespaced like the desktop file, so that it does not replace icons of other packages
+	iconSource, iconExt := findAppImageIcon(root, desktopIcon(string(content)))
+	iconName := ""
+	if iconSource != "" {
+		iconName = fmt.Sprintf("bpm-%s", pkg.Name)
+	}
+	binPath := filepath.Join(manager.config.BinFolder, pkg.Name)
+	desktopContent := rewriteDesktopFile(string(content), binPath, iconName)
+
+	files := []string{}
+	applicationsFolder := filepath.Join(manager.config.DataFolder, "applications")
+	err = os.MkdirAll(applicationsFolder, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	desktopPath := filepath.Join(applicationsFolder, fmt.Sprintf("bpm-%s.desktop", pkg.Name))
+	logger.Debug().Msgf("install desktop file to %s", desktopPath)
+	err = os.WriteFile(desktopPath, []byte(desktopContent), 0o644)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, desktopPath)
+
+	if iconSource == "" {
+		logger.Warn().Msgf("appimage does not contain an icon")
+		return files, nil
+	}
+	iconsFolder := filepath.Join(manager.config.DataFolder, "icons")
+	err = os.MkdirAll(iconsFolder, 0o755)
+	if err != nil {
+		return files, err
+	}
+	iconPath := filepath.Join(iconsFolder, iconName+iconExt)
+	logger.Debug().Msgf("install icon to %s", iconPath)
+	iconContent, err := os.ReadFile(iconSource)
+	if err != nil {
+		return files, err
+	}
+	err = os.WriteFile(iconPath, iconContent, 0o644)
+	if err != nil {
+		return files, err
+	}
+	return append(files, iconPath), nil
+}
+
+// rewriteDesktopFile points all Exec and TryExec entries to the installed binary
+// and the Icon entries to the installed icon (if it is not empty).
+func rewriteDesktopFile(content string, binPath string, icon string) string {
+	var builder strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Exec="):
+			args := strings.SplitN(strings.TrimPrefix(line, "Exec="), " ", 2)
+			args[0] = binPath
+			line = "Exec=" + strings.Join(args, " ")
+		case strings.HasPrefix(line, "TryExec="):
+			line = "TryExec=" + binPath
+		case strings.HasPrefix(line, "Icon=") && icon != "":
+			line = "Icon=" + icon
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// desktopIcon returns the name of the first icon of the desktop file.
+func desktopIcon(content string) string {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		if icon, ok := strings.CutPrefix(scanner.Text(), "Icon="); ok {
+			return filepath.Base(icon)
+		}
+	}
+	return ""
+}
+
+// findAppImageIcon searches the icon in the extracted AppImage.
+// It falls back to the .DirIcon which is always a png file.
+func findAppImageIcon(root string, iconName string) (string, string) {
+	if iconName != "" {
+		for _, ext := range appImageIconExtensions {
+			iconPath := filepath.Join(root, iconName+ext)
+			if isFileWithin(root, iconPath) {
+				return iconPath, ext
+			}
+		}
+	}
+	iconPath := filepath.Join(root, ".DirIcon")
+	if isFileWithin(root, iconPath) {
+		return iconPath, ".png"
+	}
+	return "", ""
+}
+
+// isFileWithin reports if path is a regular file which does not resolve outside of root.
+func isFileWithin(root string, path string) bool {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil || !isWithin(resolvedRoot, resolved) {
+		return false
+	}
+	info, err := os.Stat(resolved)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// setPackageFiles records the additional files of a package and removes
+// files of the previous installation which are not part of the new one.
+func (manager *ManagerImpl) setPackageFiles(name string, files []string) {
+	for _, oldFile := range manager.StateFile.Files[name] {
+		if !manager.inPackageList(oldFile, files) {
+			manager.removeFile(oldFile)
+		}
+	}
+	if len(files) == 0 {
+		delete(manager.StateFile.Files, name)
+		return
+	}
+	if manager.StateFile.Files == nil {
+		manager.StateFile.Files = make(map[string][]string)
+	}
+	manager.StateFile.Files[name] = files
+}
+
+// removePackageFiles removes all additional files of a package.
+func (manager *ManagerImpl) removePackageFiles(name string) {
+	for _, file := range manager.StateFile.Files[name] {
+		manager.removeFile(file)
+	}
+	delete(manager.StateFile.Files, name)
+}
+
+func (manager *ManagerImpl) removeFile(path string) {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		manager.logger.Warn().Msgf("cannot remove file %s: %s", path, err)
+	}
+}
@@ -0,0 +1,126 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAppImageScript = `#!/bin/sh
+if [ "$1" = "--appimage-extract" ]; then
+	mkdir -p squashfs-root
+	printf '[Desktop Entry]\nName=Tool\nExec=tool %%U\nTryExec=tool\nIcon=tool\nType=Application\n' > squashfs-root/tool.desktop
+	printf 'icon' > squashfs-root/tool.png
+	exit 0
+fi
+echo tool
+`
+
+func writeTestAppImage(t *testing.T) string {
+	return writeTestAppImageScript(t, testAppImageScript)
+}
+
+func writeTestAppImageScript(t *testing.T, script string) string {
+	appImagePath := path.Join(t.TempDir(), "tool.AppImage")
+	err := os.WriteFile(appImagePath, []byte(script), 0o755)
+	if err != nil {
+		t.Fatalf("cannot write appimage: %s", err)
+	}
+	return appImagePath
+}
+
+func TestRewriteDesktopFile(t *testing.T) {
+	desktop := "[Desktop Entry]\nExec=tool %U\nTryExec=tool\nIcon=icons/tool\n[Desktop Action new]\nExec=tool --new\n"
+	content := rewriteDesktopFile(desktop, "/bin/tool", "bpm-tool")
+	assert.Equal(t, "[Desktop Entry]\nExec=/bin/tool %U\nTryExec=/bin/tool\nIcon=bpm-tool\n[Desktop Action new]\nExec=/bin/tool --new\n", content)
+	assert.Contains(t, rewriteDesktopFile(desktop, "/bin/tool", ""), "\nIcon=icons/tool\n", "the icon is kept without installed icon")
+	assert.Equal(t, "tool", desktopIcon(desktop))
+}
+
+func TestManagerInstallAppImage(t *testing.T) {
+	for _, desktop := range []bool{false, true} {
+		name := "binary-only"
+		if desktop {
+			name = "desktop-integration"
+		}
+		t.Run(name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.StateFile = getDummyState()
+			pkg := dummyPackage()
+			pkg.InstallType = InstallTypeAppImage
+			pkg.Desktop = desktop
+			manager.Packages[pkg.Name] = *pkg
+			manager.Providers[dummyProviderName] = &DummyProvider{
+				LatestPackages: map[string]string{pkg.Name: "v1.0.0"},
+				FetchPackages:  map[string]string{pkg.Name: writeTestAppImage(t)},
+			}
+
+			_, err := manager.Install(context.Background(), pkg.Name, false)
+			if !assert.NoError(t, err) {
+				return
+			}
+			binPath := path.Join(manager.config.BinFolder, pkg.Name)
+			desktopPath := path.Join(manager.config.DataFolder, "applications", "bpm-testName.desktop")
+			iconPath := path.Join(manager.config.DataFolder, "icons", "bpm-testName.png")
+			assert.FileExists(t, binPath)
+			if !desktop {
+				assert.NoFileExists(t, desktopPath)
+				assert.Empty(t, manager.StateFile.Files)
+				return
+			}
+			assert.Equal(t, []string{desktopPath, iconPath}, manager.StateFile.Files[pkg.Name])
+			content, err := os.ReadFile(desktopPath)
+			if assert.NoError(t, err) {
+				assert.Contains(t, string(content), "Exec="+binPath+" %U\n")
+				assert.Contains(t, string(content), "Icon=bpm-testName\n")
+			}
+			assert.FileExists(t, iconPath)
+
+			_, err = manager.Remove(context.Background(), pkg.Name)
+			assert.NoError(t, err)
+			assert.NoFileExists(t, binPath)
+			assert.NoFileExists(t, desktopPath)
+			assert.NoFileExists(t, iconPath)
+			assert.NotContains(t, manager.StateFile.Packages, pkg.Name)
+			assert.NotContains(t, manager.StateFile.Files, pkg.Name)
+		})
+	}
+}
+
+func TestManagerUpdateAppImageFailedIntegration(t *testing.T) {
+	pkg := dummyPackage()
+	pkg.InstallType = InstallTypeAppImage
+	pkg.Desktop = true
+	provider := &DummyProvider{
+		LatestPackages: map[string]string{pkg.Name: "v1.0.0"},
+		FetchPackages:  map[string]string{pkg.Name: writeTestAppImage(t)},
+	}
+	manager := getTestManager(t, testManagerOptions{packages: []Package{*pkg}, provider: provider, install: []string{pkg.Name}})
+	files := manager.StateFile.Files[pkg.Name]
+	assert.Len(t, files, 2)
+
+	provider.LatestPackages[pkg.Name] = "v2.0.0"
+	provider.FetchPackages[pkg.Name] = writeTestAppImageScript(t, "#!/bin/sh\nexit 1\n")
+	_, err := manager.Update(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrPackageInstall)
+	content, err := os.ReadFile(path.Join(manager.config.BinFolder, pkg.Name))
+	if assert.NoError(t, err) {
+		assert.Equal(t, testAppImageScript, string(content), "the installed version must be kept")
+	}
+	assert.Equal(t, "v1.0.0", manager.StateFile.Packages[pkg.Name])
+	assert.Equal(t, files, manager.StateFile.Files[pkg.Name])
+	for _, file := range files {
+		assert.FileExists(t, file)
+	}
+}
+
+func TestInstallPackageUnknownType(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	pkg := dummyPackage()
+	pkg.InstallType = "unknown"
+	err := manager.installPackage(context.Background(), pkg, "v1.0.0", getTestPath("files", "dummy-bin.sh"), false)
+	assert.ErrorIs(t, err, ErrPackageInstall)
+}
@@ -0,0 +1,193 @@
+package bpm
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+)
+
+var (
+	elfTargets = map[string]elfTarget{
+		"386":      {elf.EM_386, elf.ELFCLASS32, elf.ELFDATA2LSB},
+		"amd64":    {elf.EM_X86_64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+		"arm":      {elf.EM_ARM, elf.ELFCLASS32, elf.ELFDATA2LSB},
+		"arm64":    {elf.EM_AARCH64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+		"loong64":  {elf.EM_LOONGARCH, elf.ELFCLASS64, elf.ELFDATA2LSB},
+		"mips":     {elf.EM_MIPS, elf.ELFCLASS32, elf.ELFDATA2MSB},
+		"mipsle":   {elf.EM_MIPS, elf.ELFCLASS32, elf.ELFDATA2LSB},
+		"mips64":   {elf.EM_MIPS, elf.ELFCLASS64, elf.ELFDATA2MSB},
+		"mips64le": {elf.EM_MIPS, elf.ELFCLASS64, elf.ELFDATA2LSB},
+		"ppc64":    {elf.EM_PPC64, elf.ELFCLASS64, elf.ELFDATA2MSB},
+		"ppc64le":  {elf.EM_PPC64, elf.ELFCLASS64, elf.ELFDATA2LSB},
+		"riscv64":  {elf.EM_RISCV, elf.ELFCLASS64, elf.ELFDATA2LSB},
+		"s390x":    {elf.EM_S390, elf.ELFCLASS64, elf.ELFDATA2MSB},
+	}
+	machoCPUs = map[string]macho.Cpu{
+		"386":   macho.Cpu386,
+		"amd64": macho.CpuAmd64,
+		"arm":   macho.CpuArm,
+		"arm64": macho.CpuArm64,
+	}
+	peMachines = map[string]uint16{
+		"386":   pe.IMAGE_FILE_MACHINE_I386,
+		"amd64": pe.IMAGE_FILE_MACHINE_AMD64,
+		"arm":   pe.IMAGE_FILE_MACHINE_ARMNT,
+		"arm64": pe.IMAGE_FILE_MACHINE_ARM64,
+	}
+)
+
+// elfTarget is the machine, the class (32 or 64 bit) and the byte order of the elf executables of a GOARCH.
+// Some GOARCHs share the machine and differ only in class or byte order (e.g. ppc64 and ppc64le).
+type elfTarget struct {
+	machine elf.Machine
+	class   elf.Class
+	data    elf.Data
+}
+
+// binaryKind is the detected format of a file that should be installed.
+type binaryKind string
+
+const (
+	binaryKindELF    binaryKind = "elf"
+	binaryKindMachO  binaryKind = "mach-o"
+	binaryKindPE     binaryKind = "pe"
+	binaryKindScript binaryKind = "script"
+)
+
+// checkBinary checks that the file at path is an executable for goos and goarch.
+// Scripts are accepted, the caller should warn about them.
+func checkBinary(path string, goos string, goarch string) (binaryKind, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	magic := make([]byte, 4)
+	_, err = io.ReadFull(file, magic)
+	if err != nil {
+		return "", fmt.Errorf("%w: file is too small to be an executable", ErrBinaryMismatch)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("#!")):
+		return binaryKindScript, nil
+	case bytes.Equal(magic, []byte(elf.ELFMAG)):
+		return binaryKindELF, checkELF(file, goos, goarch)
+	case bytes.HasPrefix(magic, []byte("MZ")):
+		return binaryKindPE, checkPE(file, goos, goarch)
+	case isMachOMagic(magic):
+		return binaryKindMachO, checkMachO(file, goos, goarch)
+	default:
+		return "", fmt.Errorf("%w: unknown executable format", ErrBinaryMismatch)
+	}
+}
+
+func isMachOMagic(magic []byte) bool {
+	for _, value := range []uint32{macho.Magic32, macho.Magic64, macho.MagicFat} {
+		for _, order := range [][]byte{
+			{byte(value >> 24), byte(value >> 16), byte(value >> 8), byte(value)},
+			{byte(value), byte(value >> 8), byte(value >> 16), byte(value >> 24)},
+		} {
+			if bytes.Equal(magic, order) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkELF(reader io.ReaderAt, goos string, goarch string) error {
+	file, err := elf.NewFile(reader)
+	if err != nil {
+		return fmt.Errorf("%w: invalid elf file: %s", ErrBinaryMismatch, err)
+	}
+	if goos == "darwin" || goos == "windows" {
+		return fmt.Errorf("%w: elf executable cannot run on %s", ErrBinaryMismatch, goos)
+	}
+	if file.Type != elf.ET_EXEC && file.Type != elf.ET_DYN {
+		return fmt.Errorf("%w: elf file is not executable (%s)", ErrBinaryMismatch, file.Type)
+	}
+	target, ok := elfTargets[goarch]
+	if !ok {
+		return nil
+	}
+	if file.Machine != target.machine {
+		return fmt.Errorf("%w: elf executable is built for %s, expected %s", ErrBinaryMismatch, file.Machine, target.machine)
+	}
+	if file.Class != target.class || file.Data != target.data {
+		return fmt.Errorf("%w: elf executable is built as %s %s, expected %s %s", ErrBinaryMismatch, file.Class, file.Data, target.class, target.data)
+	}
+	return nil
+}
+
+func checkMachO(reader io.ReaderAt, goos string, goarch string) error {
+	if goos != "darwin" && goos != "ios" {
+		return fmt.Errorf("%w: mach-o executable cannot run on %s", ErrBinaryMismatch, goos)
+	}
+	cpu, ok := machoCPUs[goarch]
+	if !ok {
+		return nil
+	}
+	fatFile, err := macho.NewFatFile(reader)
+	if err == nil {
+		for _, arch := range fatFile.Arches {
+			if arch.Cpu == cpu {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: universal executable does not contain %s", ErrBinaryMismatch, cpu)
+	}
+	file, err := macho.NewFile(reader)
+	if err != nil {
+		return fmt.Errorf("%w: invalid mach-o file: %s", ErrBinaryMismatch, err)
+	}
+	if file.Type != macho.TypeExec {
+		return fmt.Errorf("%w: mach-o file is not executable (%s)", ErrBinaryMismatch, file.Type)
+	}
+	if file.Cpu != cpu {
+		return fmt.Errorf("%w: mach-o executable is built for %s, expected %s", ErrBinaryMismatch, file.Cpu, cpu)
+	}
+	return nil
+}
+
+func checkPE(reader io.ReaderAt, goos string, goarch string) error {
+	if goos != "windows" {
+		return fmt.Errorf("%w: pe executable cannot run on %s", ErrBinaryMismatch, goos)
+	}
+	file, err := pe.NewFile(reader)
+	if err != nil {
+		return fmt.Errorf("%w: invalid pe file: %s", ErrBinaryMismatch, err)
+	}
+	if file.Characteristics&pe.IMAGE_FILE_EXECUTABLE_IMAGE == 0 || file.Characteristics&pe.IMAGE_FILE_DLL != 0 {
+		return fmt.Errorf("%w: pe file is not executable", ErrBinaryMismatch)
+	}
+	machine, ok := peMachines[goarch]
+	if ok && file.Machine != machine {
+		return fmt.Errorf("%w: pe executable is built for machine 0x%x, expected 0x%x", ErrBinaryMismatch, file.Machine, machine)
+	}
+	return nil
+}
+
+// verifyBinary checks the file before it is activated.
+// Mismatches are only logged if force is set.
+func (manager *ManagerImpl) verifyBinary(pkg *Package, path string, force bool) error {
+	logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
+	kind, err := checkBinary(path, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		if force {
+			logger.Warn().Msgf("installing anyway because of force: %s", err)
+			return nil
+		}
+		return fmt.Errorf("%w (use --force to install anyway)", err)
+	}
+	if kind == binaryKindScript {
+		logger.Warn().Msgf("installed file is a script and not a binary")
+	}
+	return nil
+}
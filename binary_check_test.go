@@ -0,0 +1,224 @@
+package bpm
+
+import (
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestELF writes a minimal 64 bit little endian elf header.
+func writeTestELF(t *testing.T, fileType elf.Type, machine elf.Machine) string {
+	return writeTestELFClass(t, elf.ELFCLASS64, elf.ELFDATA2LSB, fileType, machine)
+}
+
+// writeTestELFClass writes a minimal elf header with the class and byte order.
+func writeTestELFClass(t *testing.T, class elf.Class, data elf.Data, fileType elf.Type, machine elf.Machine) string {
+	header := make([]byte, 64)
+	copy(header, elf.ELFMAG)
+	header[elf.EI_CLASS] = byte(class)
+	header[elf.EI_DATA] = byte(data)
+	header[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	var order binary.ByteOrder = binary.LittleEndian
+	if data == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+	order.PutUint16(header[16:], uint16(fileType))
+	order.PutUint16(header[18:], uint16(machine))
+	order.PutUint32(header[20:], uint32(elf.EV_CURRENT))
+	if class == elf.ELFCLASS32 {
+		order.PutUint16(header[40:], 52)
+	} else {
+		order.PutUint16(header[52:], 64)
+	}
+	return writeTestBinary(t, header)
+}
+
+func writeTestBinary(t *testing.T, content []byte) string {
+	binPath := path.Join(t.TempDir(), "binary")
+	err := os.WriteFile(binPath, content, 0o755)
+	if err != nil {
+		t.Fatalf("cannot write test binary: %s", err)
+	}
+	return binPath
+}
+
+func TestCheckBinary(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		goos   string
+		goarch string
+		kind   binaryKind
+		err    error
+	}{
+		{
+			name:   "elf-amd64",
+			path:   writeTestELF(t, elf.ET_EXEC, elf.EM_X86_64),
+			goos:   "linux",
+			goarch: "amd64",
+			kind:   binaryKindELF,
+		},
+		{
+			name:   "elf-pie-arm64",
+			path:   writeTestELF(t, elf.ET_DYN, elf.EM_AARCH64),
+			goos:   "linux",
+			goarch: "arm64",
+			kind:   binaryKindELF,
+		},
+		{
+			name:   "elf-wrong-arch",
+			path:   writeTestELF(t, elf.ET_EXEC, elf.EM_AARCH64),
+			goos:   "linux",
+			goarch: "amd64",
+			kind:   binaryKindELF,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "elf-wrong-os",
+			path:   writeTestELF(t, elf.ET_EXEC, elf.EM_X86_64),
+			goos:   "darwin",
+			goarch: "amd64",
+			kind:   binaryKindELF,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "elf-object-file",
+			path:   writeTestELF(t, elf.ET_REL, elf.EM_X86_64),
+			goos:   "linux",
+			goarch: "amd64",
+			kind:   binaryKindELF,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "elf-ppc64le-on-ppc64",
+			path:   writeTestELFClass(t, elf.ELFCLASS64, elf.ELFDATA2LSB, elf.ET_EXEC, elf.EM_PPC64),
+			goos:   "linux",
+			goarch: "ppc64",
+			kind:   binaryKindELF,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "elf-ppc64",
+			path:   writeTestELFClass(t, elf.ELFCLASS64, elf.ELFDATA2MSB, elf.ET_EXEC, elf.EM_PPC64),
+			goos:   "linux",
+			goarch: "ppc64",
+			kind:   binaryKindELF,
+		},
+		{
+			name:   "elf-mips-on-mipsle",
+			path:   writeTestELFClass(t, elf.ELFCLASS32, elf.ELFDATA2MSB, elf.ET_EXEC, elf.EM_MIPS),
+			goos:   "linux",
+			goarch: "mipsle",
+			kind:   binaryKindELF,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "elf-mipsle",
+			path:   writeTestELFClass(t, elf.ELFCLASS32, elf.ELFDATA2LSB, elf.ET_EXEC, elf.EM_MIPS),
+			goos:   "linux",
+			goarch: "mipsle",
+			kind:   binaryKindELF,
+		},
+		{
+			name:   "elf-mips64-on-mips",
+			path:   writeTestELFClass(t, elf.ELFCLASS64, elf.ELFDATA2MSB, elf.ET_EXEC, elf.EM_MIPS),
+			goos:   "linux",
+			goarch: "mips",
+			kind:   binaryKindELF,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "elf-32-bit-arm",
+			path:   writeTestELFClass(t, elf.ELFCLASS32, elf.ELFDATA2LSB, elf.ET_EXEC, elf.EM_ARM),
+			goos:   "linux",
+			goarch: "arm",
+			kind:   binaryKindELF,
+		},
+		{
+			name:   "script",
+			path:   getTestPath("files", "dummy-bin.sh"),
+			goos:   "linux",
+			goarch: "amd64",
+			kind:   binaryKindScript,
+		},
+		{
+			name:   "pe-on-linux",
+			path:   writeTestBinary(t, []byte("MZ\x90\x00")),
+			goos:   "linux",
+			goarch: "amd64",
+			kind:   binaryKindPE,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "mach-o-on-linux",
+			path:   writeTestBinary(t, []byte{0xcf, 0xfa, 0xed, 0xfe}),
+			goos:   "linux",
+			goarch: "amd64",
+			kind:   binaryKindMachO,
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "unknown",
+			path:   writeTestBinary(t, []byte("just some text")),
+			goos:   "linux",
+			goarch: "amd64",
+			err:    ErrBinaryMismatch,
+		},
+		{
+			name:   "too-small",
+			path:   writeTestBinary(t, []byte("#")),
+			goos:   "linux",
+			goarch: "amd64",
+			err:    ErrBinaryMismatch,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kind, err := checkBinary(test.path, test.goos, test.goarch)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.kind, kind)
+		})
+	}
+}
+
+func TestCheckBinaryTestExecutable(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Skipf("cannot find test executable: %s", err)
+	}
+	_, err = checkBinary(executable, runtime.GOOS, runtime.GOARCH)
+	assert.NoError(t, err, "the running test binary must be valid for this platform")
+}
+
+func TestManagerInstallBinaryMismatch(t *testing.T) {
+	for _, force := range []bool{false, true} {
+		t.Run(map[bool]string{false: "refuse", true: "force"}[force], func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.StateFile = getDummyState()
+			pkg := dummyPackage()
+			manager.Packages[pkg.Name] = *pkg
+			manager.Providers[dummyProviderName] = &DummyProvider{
+				LatestPackages: map[string]string{pkg.Name: "v1.0.0"},
+				FetchPackages:  map[string]string{pkg.Name: writeTestBinary(t, []byte("no executable"))},
+			}
+			_, err := manager.Install(context.Background(), pkg.Name, force)
+			binPath := path.Join(manager.config.BinFolder, pkg.Name)
+			if force {
+				assert.NoError(t, err)
+				assert.FileExists(t, binPath)
+			} else {
+				assert.ErrorIs(t, err, ErrBinaryMismatch)
+				assert.NoFileExists(t, binPath)
+				assert.NoFileExists(t, binPath+"-v1.0.0")
+				assert.NotContains(t, manager.StateFile.Packages, pkg.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ReleaseNote is the description of a single release.
+type ReleaseNote struct {
+	Version   string    `yaml:"version" json:"version"`
+	Name      string    `yaml:"name,omitempty" json:"name,omitempty"`
+	Published time.Time `yaml:"published,omitempty" json:"published,omitempty"`
+	URL       string    `yaml:"url,omitempty" json:"url,omitempty"`
+	Body      string    `yaml:"body" json:"body"`
+}
+
+// ReleaseNotesProvider is implemented by providers that can fetch release notes.
+type ReleaseNotesProvider interface {
+	// GetReleaseNotes returns the notes of all releases after from up to (including) to, newest first.
+	GetReleaseNotes(ctx context.Context, pkg Package, from string, to string) ([]ReleaseNote, error)
+}
+
+// Changelog returns the release notes of the package between from (exclusive) and to (inclusive), newest first.
+// from defaults to the installed version and to defaults to the latest version.
+func (manager *ManagerImpl) Changelog(ctx context.Context, name string, from string, to string) ([]ReleaseNote, error) {
+	pkg, ok := manager.Packages[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+	provider, ok := manager.Providers[pkg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+	}
+	notesProvider, ok := provider.(ReleaseNotesProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: provider %s does not support release notes", ErrProvider, pkg.Provider)
+	}
+	if from == "" {
+		from = manager.StateFile.Packages[name]
+	}
+	if to == "" {
+		selection, err := manager.selectRelease(ctx, provider, pkg, from)
+		if err != nil {
+			return nil, err
+		}
+		to = selection.Version
+	}
+	if from == to {
+		return []ReleaseNote{}, nil
+	}
+	return notesProvider.GetReleaseNotes(ctx, pkg, from, to)
+}
+
+// releasesBetween returns the tags after from up to (including) to, newest first.
+// The tags must be sorted ascending. If from is not part of the tags it is compared
+// as semantic version, an empty from selects all tags up to to.
+func releasesBetween(tags []string, from string, to string) ([]string, error) {
+	fromVersion, _ := semver.NewVersion(from)
+	afterFrom := from == ""
+	between := []string{}
+	for _, tag := range tags {
+		if tag == from {
+			afterFrom = true
+		} else {
+			if !afterFrom && fromVersion != nil {
+				version, err := semver.NewVersion(tag)
+				afterFrom = err == nil && version.GreaterThan(fromVersion)
+			}
+			if afterFrom {
+				between = append(between, tag)
+			}
+		}
+		if tag == to {
+			for i, j := 0, len(between)-1; i < j; i, j = i+1, j-1 {
+				between[i], between[j] = between[j], between[i]
+			}
+			return between, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: release %s not found", ErrProviderFetch, to)
+}
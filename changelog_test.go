@@ -0,0 +1,78 @@
+package bpm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleasesBetween(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.1.0", "v1.2.0", "v2.0.0"}
+	tests := []struct {
+		name   string
+		from   string
+		to     string
+		result []string
+		err    error
+	}{
+		{name: "range", from: "v1.0.0", to: "v1.2.0", result: []string{"v1.2.0", "v1.1.0"}},
+		{name: "same", from: "v1.2.0", to: "v1.2.0", result: []string{}},
+		{name: "from-empty", from: "", to: "v1.1.0", result: []string{"v1.1.0", "v1.0.0"}},
+		{name: "from-missing-semver", from: "v1.0.5", to: "v2.0.0", result: []string{"v2.0.0", "v1.2.0", "v1.1.0"}},
+		{name: "to-missing", from: "v1.0.0", to: "v3.0.0", err: ErrProviderFetch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := releasesBetween(tags, test.from, test.to)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestManagerChangelog(t *testing.T) {
+	notes := []ReleaseNote{
+		{Version: "v1.2.0", Body: "third"},
+		{Version: "v1.1.0", Body: "second"},
+		{Version: "v1.0.0", Body: "first"},
+	}
+	tests := []struct {
+		name      string
+		installed string
+		from      string
+		to        string
+		result    []ReleaseNote
+		err       error
+	}{
+		{name: "installed-to-latest", installed: "v1.0.0", result: notes[:2]},
+		{name: "explicit", from: "v1.0.0", to: "v1.1.0", result: notes[1:2]},
+		{name: "up-to-date", installed: "v1.2.0", result: []ReleaseNote{}},
+		{name: "not-installed", result: notes},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.StateFile = getDummyState()
+			pkg := dummyPackage()
+			manager.Packages[pkg.Name] = *pkg
+			if test.installed != "" {
+				manager.StateFile.Packages[pkg.Name] = test.installed
+			}
+			manager.Providers[dummyProviderName] = &DummyProvider{
+				LatestPackages: map[string]string{pkg.Name: "v1.2.0"},
+				Notes:          map[string][]ReleaseNote{pkg.Name: notes},
+			}
+			result, err := manager.Changelog(context.Background(), pkg.Name, test.from, test.to)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.result, result)
+		})
+	}
+}
+
+func TestManagerChangelogMissing(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	_, err := manager.Changelog(context.Background(), "missing", "", "")
+	assert.ErrorIs(t, err, ErrPackageNotFound)
+}
@@ -1,16 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"fmt"
 	"github.com/jduepmeier/binary-package-manager"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/rs/zerolog"
 )
 
+// stdin is read for the asset selection of add. It is a variable to be replaced in tests.
+var stdin io.Reader = os.Stdin
+
 type AddSubCommand struct {
+	outputCommand
 	Opts AddSubCommandOpts
 }
 type AddSubCommandOpts struct {
+	Yes  bool `long:"yes" short:"y" description:"do not ask and use the best matching asset"`
 	Args struct {
 		Name string
 		URL  string
@@ -22,10 +34,49 @@ func init() {
 }
 
 func (cmd *AddSubCommand) AddCommand(parser *flags.Parser) error {
-	_, err := parser.AddCommand("add", "add a package", "add a package", &cmd.Opts)
+	_, err := parser.AddCommand("add", "add a package", "add a package. The latest release is inspected to propose the asset pattern and archive format", &cmd.Opts)
 	return err
 }
 
-func (cmd *AddSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.Add(cmd.Opts.Args.Name, cmd.Opts.Args.URL)
+func (cmd *AddSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	version, asset := "", ""
+	if !cmd.Opts.Yes {
+		latest, assets, err := manager.AddCandidates(ctx, cmd.Opts.Args.Name, cmd.Opts.Args.URL)
+		if err != nil {
+			// add writes a minimal package file without candidates
+			logger.Debug().Msgf("cannot get the assets: %s", err)
+		} else if len(assets) > 0 {
+			version, asset = latest, assets[0]
+			if len(assets) > 1 {
+				asset, err = selectAsset(cmd.output.Writer, stdin, latest, assets)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// the release is not queried again with the selected version and asset
+	return manager.Add(ctx, cmd.Opts.Args.Name, cmd.Opts.Args.URL, version, asset)
+}
+
+// selectAsset asks the user to choose one of the assets, the first one is the default.
+func selectAsset(writer io.Writer, reader io.Reader, version string, assets []string) (string, error) {
+	fmt.Fprintf(writer, "assets of release %s matching this platform:\n", version)
+	for i, asset := range assets {
+		fmt.Fprintf(writer, "  %d) %s\n", i+1, asset)
+	}
+	fmt.Fprintf(writer, "select asset [1]: ")
+	input, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && input == "" {
+		return assets[0], nil
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return assets[0], nil
+	}
+	index, err := strconv.Atoi(input)
+	if err != nil || index < 1 || index > len(assets) {
+		return "", fmt.Errorf("%w: invalid selection %q", bpm.ErrInvalidInput, input)
+	}
+	return assets[index-1], nil
 }
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,13 +14,19 @@ import (
 type dummyAddManager struct {
 	*bpm.DummyManager
 	addTestFunc testAddFunc
+	assets      []string
 }
 
-type testAddFunc func(t *testing.T, name string, url string)
+type testAddFunc func(t *testing.T, name string, url string, version string, asset string)
 
-func (manager *dummyAddManager) Add(name string, url string) error {
+func (manager *dummyAddManager) AddCandidates(ctx context.Context, name string, url string) (string, []string, error) {
+	manager.DummyManager.AddCandidates(ctx, name, url)
+	return "v1.0.0", manager.assets, nil
+}
+
+func (manager *dummyAddManager) Add(ctx context.Context, name string, url string, version string, asset string) error {
 	if manager.addTestFunc != nil {
-		manager.addTestFunc(manager.DummyManager.T, name, url)
+		manager.addTestFunc(manager.DummyManager.T, name, url, version, asset)
 	}
 	return nil
 }
@@ -24,6 +34,8 @@ func (manager *dummyAddManager) Add(name string, url string) error {
 type testAddConfig struct {
 	testConfig  testConfig
 	addTestFunc testAddFunc
+	assets      []string
+	input       string
 }
 
 func TestAdd(t *testing.T) {
@@ -51,16 +63,79 @@ func TestAdd(t *testing.T) {
 				args:     []string{"add", "testName", "testURL"},
 				testFunc: emptyTestFunc,
 			},
-			addTestFunc: func(t *testing.T, name, url string) {
+			addTestFunc: func(t *testing.T, name, url string, version string, asset string) {
 				assert.Equal(t, name, "testName")
 				assert.Equal(t, url, "testURL")
+				assert.Equal(t, "v1.0.0", version)
+				assert.Equal(t, "tool.tar.gz", asset, "a single asset is used without asking")
+			},
+			assets: []string{"tool.tar.gz"},
+		},
+		{
+			testConfig: testConfig{
+				name:     "select-default",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"add", "testName", "testURL"},
+				testFunc: testOutputContains("assets of release v1.0.0 matching this platform:\n  1) tool.tar.gz\n  2) tool\nselect asset [1]: "),
+			},
+			addTestFunc: func(t *testing.T, name, url string, version string, asset string) {
+				assert.Equal(t, "tool.tar.gz", asset)
+			},
+			assets: []string{"tool.tar.gz", "tool"},
+			input:  "\n",
+		},
+		{
+			testConfig: testConfig{
+				name:     "select-second",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"add", "testName", "testURL"},
+				testFunc: emptyTestFunc,
+			},
+			addTestFunc: func(t *testing.T, name, url string, version string, asset string) {
+				assert.Equal(t, "v1.0.0", version, "the release is not queried again")
+				assert.Equal(t, "tool", asset)
+			},
+			assets: []string{"tool.tar.gz", "tool"},
+			input:  "2\n",
+		},
+		{
+			testConfig: testConfig{
+				name:     "invalid-selection",
+				exitCode: EXIT_ERROR,
+				args:     []string{"add", "testName", "testURL"},
+				testFunc: testOutputContains("invalid selection"),
+			},
+			addTestFunc: func(t *testing.T, name, url string, version string, asset string) {
+				assert.Fail(t, "add must not be called after an invalid selection")
+			},
+			assets: []string{"tool.tar.gz", "tool"},
+			input:  "3\n",
+		},
+		{
+			testConfig: testConfig{
+				name:     "success with yes",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"add", "--yes", "testName", "testURL"},
+				testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+					return assert.Equal(t, 0, manager.(*dummyAddManager).GetCounter("AddCandidates"), "--yes does not ask")
+				},
+			},
+			addTestFunc: func(t *testing.T, name, url string, version string, asset string) {
+				assert.Empty(t, version)
+				assert.Empty(t, asset, "--yes uses the best matching asset")
 			},
+			assets: []string{"tool.tar.gz", "tool"},
 		},
 	}
+	t.Cleanup(func() {
+		stdin = os.Stdin
+	})
 	for _, testConfig := range tests {
+		stdin = strings.NewReader(testConfig.input)
 		testConfig.testConfig.manager = &dummyAddManager{
 			DummyManager: &bpm.DummyManager{},
 			addTestFunc:  testConfig.addTestFunc,
+			assets:       testConfig.assets,
 		}
 		runTest(t, &testConfig.testConfig)
 	}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type AdoptSubCommand struct {
+	outputCommand
+	Opts AdoptSubCommandOpts
+}
+type AdoptSubCommandOpts struct {
+	All  bool `long:"all" short:"a" description:"adopt all packages with a binary in the bin folder which are not installed"`
+	Args struct {
+		Packages []string
+	} `positional-args:"true"`
+}
+
+// ErrNoPackagesToAdopt is returned if neither packages nor --all are given.
+var ErrNoPackagesToAdopt = errors.New("no packages given (use --all to adopt all packages)")
+
+func init() {
+	subCommands["adopt"] = &AdoptSubCommand{}
+}
+
+func (cmd *AdoptSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("adopt", "adopt existing binaries",
+		"records the version of binaries already in the bin folder, so that update takes over", &cmd.Opts)
+	return err
+}
+
+func (cmd *AdoptSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	if len(cmd.Opts.Args.Packages) == 0 && !cmd.Opts.All {
+		return ErrNoPackagesToAdopt
+	}
+	results, err := manager.Adopt(ctx, cmd.Opts.Args.Packages)
+	renderErr := cmd.output.Render(results, func(writer io.Writer) {
+		fmt.Fprintf(writer, "NAME\tVERSION\tDETECTED BY\n")
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Fprintf(writer, "%s\t-\tfailed: %s\n", result.Name, result.Error)
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Name, result.Version, result.Method)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
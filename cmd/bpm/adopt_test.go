@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyAdoptManager struct {
+	*bpm.DummyManager
+	packages []string
+}
+
+func (manager *dummyAdoptManager) Adopt(ctx context.Context, packages []string) ([]bpm.AdoptResult, error) {
+	manager.packages = packages
+	results := []bpm.AdoptResult{
+		{Name: "tool", Version: "v1.1.0", Method: bpm.AdoptMethodCommand, Path: "/bin/tool"},
+	}
+	if len(packages) > 1 {
+		err := errors.New("binary matches none of the last 10 releases")
+		results = append(results, bpm.AdoptResult{Name: "broken", Path: "/bin/broken", Error: err.Error()})
+		return results, &bpm.UpdateError{Failed: []*bpm.PackageError{{Name: "broken", Err: err}}, Succeeded: 1, Changed: 1, Operation: "adopt"}
+	}
+	return results, nil
+}
+
+func TestAdopt(t *testing.T) {
+	cmd := "adopt"
+	tests := []testConfig{
+		{
+			name:     "no-packages",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Equal(t, 0, manager.(*dummyAdoptManager).GetCounter("SaveState"))
+			},
+		},
+		{
+			name:     "package",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "tool"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				assert.Equal(t, []string{"tool"}, manager.(*dummyAdoptManager).packages)
+				return assert.Contains(t, buf.String(), "NAME  VERSION  DETECTED BY\ntool  v1.1.0   command\n")
+			},
+		},
+		{
+			name:     "partial",
+			exitCode: EXIT_PARTIAL_ERROR,
+			args:     []string{cmd, "tool", "broken"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				assert.Contains(t, buf.String(), "broken  -        failed: binary matches none of the last 10 releases\n")
+				return assert.Equal(t, 1, manager.(*dummyAdoptManager).GetCounter("SaveState"), "the adopted packages must be saved")
+			},
+		},
+		{
+			name:     "all",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--all"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Empty(t, manager.(*dummyAdoptManager).packages, "--all adopts all packages")
+			},
+		},
+	}
+	for _, testConfig := range tests {
+		testConfig.manager = &dummyAdoptManager{DummyManager: &bpm.DummyManager{}}
+		runTest(t, &testConfig)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type ChangelogSubCommand struct {
+	outputCommand
+	Opts ChangelogSubCommandOpts
+}
+type ChangelogSubCommandOpts struct {
+	Args struct {
+		Name string `required:"yes"`
+		From string
+		To   string
+	} `positional-args:"yes"`
+}
+
+func init() {
+	subCommands["changelog"] = &ChangelogSubCommand{}
+}
+
+func (cmd *ChangelogSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("changelog", "show release notes", "show the release notes between two versions. From defaults to the installed version, to defaults to the latest version", &cmd.Opts)
+	return err
+}
+
+func (cmd *ChangelogSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	notes, err := manager.Changelog(ctx, cmd.Opts.Args.Name, cmd.Opts.Args.From, cmd.Opts.Args.To)
+	if err != nil {
+		return err
+	}
+	return cmd.output.Render(notes, func(writer io.Writer) {
+		if len(notes) == 0 {
+			fmt.Fprintf(writer, "no release notes\n")
+		}
+		renderNotes(writer, notes, "")
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyChangelogManager struct {
+	*bpm.DummyManager
+	args []string
+}
+
+func (manager *dummyChangelogManager) Changelog(ctx context.Context, name string, from string, to string) ([]bpm.ReleaseNote, error) {
+	manager.DummyManager.Changelog(ctx, name, from, to)
+	manager.args = []string{name, from, to}
+	return []bpm.ReleaseNote{
+		{Version: "v1.1.0", Name: "Second release", Body: "* fix\tbug\r\n* add feature"},
+		{Version: "v1.0.1", Body: ""},
+	}, nil
+}
+
+func (manager *dummyChangelogManager) Update(ctx context.Context, packages []string) ([]bpm.InstallResult, error) {
+	return []bpm.InstallResult{
+		{Name: "tool", PreviousVersion: "v1.0.0", Version: "v1.1.0", Changed: true},
+		{Name: "other", PreviousVersion: "v2.0.0", Version: "v2.0.0"},
+	}, nil
+}
+
+func testChangelogArgs(args []string, output string) testFunc {
+	return func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+		changelogManager := manager.(*dummyChangelogManager)
+		assert.Equal(t, args, changelogManager.args)
+		return assert.Contains(t, buf.String(), output)
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	notes := "## v1.1.0 - Second release\n* fix    bug\n* add feature\n\n## v1.0.1\n\n"
+	tests := []testConfig{
+		{
+			name:     "empty",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{"changelog"},
+			testFunc: testOutputContains("the required argument `Name` was not provided"),
+		},
+		{
+			name:     "name",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"changelog", "tool"},
+			testFunc: testChangelogArgs([]string{"tool", "", ""}, notes),
+		},
+		{
+			name:     "range",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"changelog", "tool", "v1.0.0", "v1.1.0"},
+			testFunc: testChangelogArgs([]string{"tool", "v1.0.0", "v1.1.0"}, notes),
+		},
+		{
+			name:     "update-show-notes",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"update", "--show-notes"},
+			testFunc: testChangelogArgs([]string{"tool", "v1.0.0", "v1.1.0"}, "tool  v1.0.0  =>  v1.1.0\n  ## v1.1.0 - Second release\n  * fix    bug\n"),
+		},
+	}
+	for _, testConfig := range tests {
+		testConfig.manager = &dummyChangelogManager{DummyManager: &bpm.DummyManager{}}
+		runTest(t, &testConfig)
+	}
+}
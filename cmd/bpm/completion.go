@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type CompletionSubCommand struct {
+	outputCommand
+	Opts CompletionSubCommandOpts
+}
+type CompletionSubCommandOpts struct {
+	Args struct {
+		Shell string `description:"bash, zsh or fish"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// CompleteSubCommand prints the candidates of the last word, it is called by the completion scripts.
+type CompleteSubCommand struct {
+	outputCommand
+	Opts CompleteSubCommandOpts
+}
+type CompleteSubCommandOpts struct {
+	Args struct {
+		Words []string `description:"words of the command line after bpm, the last word is completed"`
+	} `positional-args:"yes"`
+}
+
+// Candidate is a completion of the last word.
+type Candidate struct {
+	Value       string
+	Description string
+}
+
+// completionScripts are the completion scripts of the shells, %[1]s is the bpm command.
+// The candidates are printed by __complete as value<tab>description per line.
+var completionScripts = map[string]string{
+	SHELL_BASH: `_bpm_complete() {
+  local value description i prefix
+  local -a words=()
+  # COMP_WORDBREAKS splits name@version into three words, join them again
+  for ((i = 1; i <= COMP_CWORD; i++)); do
+    if ((${#words[@]} > 0)) && [[ ${COMP_WORDS[i]} == @ || ${COMP_WORDS[i-1]} == @ ]]; then
+      words[${#words[@]}-1]+=${COMP_WORDS[i]}
+    else
+      words+=("${COMP_WORDS[i]}")
+    fi
+  done
+  # bash only replaces the text after the last word break
+  prefix=${words[${#words[@]}-1]}
+  prefix=${prefix%%"${COMP_WORDS[COMP_CWORD]}"}
+  COMPREPLY=()
+  while IFS=$'\t' read -r value description; do
+    COMPREPLY+=("${value#"$prefix"}")
+  done < <(%[1]s __complete -- "${words[@]}" 2>/dev/null)
+}
+complete -o default -F _bpm_complete bpm
+`,
+	SHELL_ZSH: `#compdef bpm
+compdef _bpm bpm
+
+_bpm() {
+  local -a candidates
+  local line value
+  for line in "${(@f)$(%[1]s __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+    [[ -z $line ]] && continue
+    value="${line%%%%$'\t'*}"
+    if [[ $line == *$'\t'* ]]; then
+      candidates+=("${value//:/\\:}:${line#*$'\t'}")
+    else
+      candidates+=("${value//:/\\:}")
+    fi
+  done
+  if (( ${#candidates} )); then
+    _describe -t values bpm candidates
+  else
+    _files
+  fi
+}
+
+# run the completion when the file is autoloaded from fpath
+if [ "$funcstack[1]" = "_bpm" ]; then
+  _bpm "$@"
+fi
+`,
+	SHELL_FISH: `function __bpm_complete
+  set -l words (commandline -opc) (commandline -ct)
+  %[1]s __complete -- $words[2..-1] 2>/dev/null
+end
+complete -c bpm -f -a '(__bpm_complete)'
+`,
+}
+
+// positionalCompleters complete the positional arguments of the commands, identified by the
+// names of the command and its parent commands. The manager is only created for them.
+var positionalCompleters = map[string]func(ctx context.Context, manager bpm.Manager, word string) []Candidate{
+	"install":         completeInstall,
+	"info":            completePackages,
+	"changelog":       completeInstalled,
+	"remove":          completeInstalled,
+	"update":          completeInstalled,
+	"adopt":           completePackages,
+	"shim add":        completePackages,
+	"shim remove":     completeShims,
+	"registry update": completeRegistries,
+}
+
+// positionalValues are the fixed values of the positional arguments of the commands.
+var positionalValues = map[string][]string{
+	"schema":     {"package", "config"},
+	"hook":       {SHELL_BASH, SHELL_ZSH, SHELL_FISH, SHELL_DIRENV},
+	"completion": {SHELL_BASH, SHELL_ZSH, SHELL_FISH},
+}
+
+func init() {
+	subCommands["completion"] = &CompletionSubCommand{}
+	subCommands["__complete"] = &CompleteSubCommand{}
+}
+
+func (cmd *CompletionSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("completion", "print the shell completion of bpm",
+		"prints the completion script of bpm for the shell. It completes the commands, options, package names and "+
+			"versions (install name@). Add eval \"$(bpm completion bash)\" to ~/.bashrc, "+
+			"bpm completion zsh > \"${fpath[1]}/_bpm\" or bpm completion fish > ~/.config/fish/completions/bpm.fish", &cmd.Opts)
+	return err
+}
+
+func (cmd *CompletionSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	shell := cmd.Opts.Args.Shell
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("%w: unknown shell %s (use %s, %s or %s)", bpm.ErrInvalidInput, shell, SHELL_BASH, SHELL_ZSH, SHELL_FISH)
+	}
+	command, err := bpmCommand(manager)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.output.Writer, script, command)
+	return err
+}
+
+func (cmd *CompleteSubCommand) AddCommand(parser *flags.Parser) error {
+	command, err := parser.AddCommand("__complete", "print the completions of the command line",
+		"prints the candidates of the last word, one per line with the description separated by a tab", &cmd.Opts)
+	if err != nil {
+		return err
+	}
+	command.Hidden = true
+	return nil
+}
+
+func (cmd *CompleteSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	return cmd.RunLazy(ctx, logger, func() (bpm.Manager, error) {
+		return manager, nil
+	})
+}
+
+// RunLazy completes commands and options without a manager, it is only created for package names and versions.
+func (cmd *CompleteSubCommand) RunLazy(ctx context.Context, logger zerolog.Logger, newManager ManagerFunc) error {
+	parser, _, err := newParser(&opts{})
+	if err != nil {
+		return err
+	}
+	// the scripts read the lines without the table formatting
+	for _, candidate := range complete(ctx, logger, parser, newManager, cmd.Opts.Args.Words) {
+		if candidate.Description != "" {
+			fmt.Fprintf(cmd.output.Writer, "%s\t%s\n", candidate.Value, candidate.Description)
+		} else {
+			fmt.Fprintf(cmd.output.Writer, "%s\n", candidate.Value)
+		}
+	}
+	return nil
+}
+
+// complete returns the candidates of the last word of words.
+func complete(ctx context.Context, logger zerolog.Logger, parser *flags.Parser, newManager ManagerFunc, words []string) []Candidate {
+	word := ""
+	if len(words) > 0 {
+		word = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	commands := []*flags.Command{parser.Command}
+	positional := 0
+	var value *flags.Option
+	for _, current := range words {
+		if value != nil {
+			value = nil
+			continue
+		}
+		command := commands[len(commands)-1]
+		switch {
+		case current == "--":
+			positional++
+		case strings.HasPrefix(current, "-") && positional == 0:
+			if option := findOption(commands, current); option != nil && takesValue(option) && !strings.Contains(current, "=") {
+				value = option
+			}
+		case positional == 0 && command.Find(current) != nil:
+			commands = append(commands, command.Find(current))
+		default:
+			positional++
+		}
+	}
+	command := commands[len(commands)-1]
+	if value != nil {
+		return completeValues(word, value.Choices...)
+	}
+	if strings.HasPrefix(word, "-") && positional == 0 {
+		return completeOptions(commands, word)
+	}
+	candidates := []Candidate{}
+	if positional == 0 {
+		for _, sub := range command.Commands() {
+			if !sub.Hidden && strings.HasPrefix(sub.Name, word) {
+				candidates = append(candidates, Candidate{Value: sub.Name, Description: sub.ShortDescription})
+			}
+		}
+		slices.SortFunc(candidates, func(a, b Candidate) int {
+			return strings.Compare(a.Value, b.Value)
+		})
+	}
+	names := []string{}
+	for _, parent := range commands[1:] {
+		names = append(names, parent.Name)
+	}
+	if values, ok := positionalValues[strings.Join(names, " ")]; ok {
+		candidates = append(candidates, completeValues(word, values...)...)
+	} else if completer, ok := positionalCompleters[strings.Join(names, " ")]; ok {
+		manager, err := newManager()
+		if err != nil {
+			logger.Debug().Msgf("cannot create manager instance: %s", err)
+			return candidates
+		}
+		candidates = append(candidates, completer(ctx, manager, word)...)
+	}
+	return candidates
+}
+
+// findOption returns the option of the word (--name, --name=value or -n) of the commands.
+func findOption(commands []*flags.Command, word string) *flags.Option {
+	for i := len(commands) - 1; i >= 0; i-- {
+		if name, ok := strings.CutPrefix(word, "--"); ok {
+			name, _, _ = strings.Cut(name, "=")
+			if option := commands[i].FindOptionByLongName(name); option != nil {
+				return option
+			}
+		} else if len(word) == 2 {
+			if option := commands[i].FindOptionByShortName(rune(word[1])); option != nil {
+				return option
+			}
+		}
+	}
+	return nil
+}
+
+// takesValue reports if the option needs a value.
+func takesValue(option *flags.Option) bool {
+	kind := option.Field().Type.Kind()
+	return kind != reflect.Bool && kind != reflect.Func
+}
+
+// completeOptions returns the options of the commands starting with word.
+func completeOptions(commands []*flags.Command, word string) []Candidate {
+	candidates := []Candidate{}
+	for i := len(commands) - 1; i >= 0; i-- {
+		candidates = append(candidates, completeGroupOptions(commands[i].Group, word)...)
+	}
+	return candidates
+}
+
+// completeGroupOptions returns the options of the group and its sub groups starting with word.
+func completeGroupOptions(group *flags.Group, word string) []Candidate {
+	candidates := []Candidate{}
+	for _, option := range group.Options() {
+		if option.Hidden {
+			continue
+		}
+		if option.LongName != "" && strings.HasPrefix("--"+option.LongName, word) {
+			candidates = append(candidates, Candidate{Value: "--" + option.LongName, Description: option.Description})
+		} else if option.ShortName != 0 && strings.HasPrefix("-"+string(option.ShortName), word) {
+			candidates = append(candidates, Candidate{Value: "-" + string(option.ShortName), Description: option.Description})
+		}
+	}
+	for _, sub := range group.Groups() {
+		candidates = append(candidates, completeGroupOptions(sub, word)...)
+	}
+	return candidates
+}
+
+// completeValues returns the values starting with word.
+func completeValues(word string, values ...string) []Candidate {
+	candidates := []Candidate{}
+	for _, value := range values {
+		if strings.HasPrefix(value, word) {
+			candidates = append(candidates, Candidate{Value: value})
+		}
+	}
+	return candidates
+}
+
+// completePackages completes the names of the known packages.
+func completePackages(ctx context.Context, manager bpm.Manager, word string) []Candidate {
+	packages, err := manager.List(ctx)
+	if err != nil {
+		return nil
+	}
+	return completeStatus(packages, word)
+}
+
+// completeInstalled completes the names of the installed packages.
+func completeInstalled(ctx context.Context, manager bpm.Manager, word string) []Candidate {
+	packages, err := manager.Installed(ctx)
+	if err != nil {
+		return nil
+	}
+	return completeStatus(packages, word)
+}
+
+func completeStatus(packages []bpm.PackageStatus, word string) []Candidate {
+	candidates := []Candidate{}
+	for _, pkg := range packages {
+		if strings.HasPrefix(pkg.Name, word) {
+			candidates = append(candidates, Candidate{Value: pkg.Name, Description: pkg.Version})
+		}
+	}
+	return candidates
+}
+
+// completeInstall completes the package names and after name@ the released versions of the package.
+func completeInstall(ctx context.Context, manager bpm.Manager, word string) []Candidate {
+	name, prefix, ok := strings.Cut(word, "@")
+	if !ok {
+		return completePackages(ctx, manager, word)
+	}
+	versions, err := manager.Versions(ctx, name)
+	if err != nil {
+		return nil
+	}
+	candidates := []Candidate{}
+	for _, version := range versions {
+		if strings.HasPrefix(version, prefix) {
+			candidates = append(candidates, Candidate{Value: name + "@" + version})
+		}
+	}
+	return candidates
+}
+
+// completeShims completes the names of the shimmed packages.
+func completeShims(ctx context.Context, manager bpm.Manager, word string) []Candidate {
+	shims, err := manager.Shims(ctx)
+	if err != nil {
+		return nil
+	}
+	candidates := []Candidate{}
+	for _, shim := range shims {
+		if strings.HasPrefix(shim.Name, word) {
+			candidates = append(candidates, Candidate{Value: shim.Name, Description: shim.Version})
+		}
+	}
+	return candidates
+}
+
+// completeRegistries completes the names of the configured registries.
+func completeRegistries(ctx context.Context, manager bpm.Manager, word string) []Candidate {
+	candidates := []Candidate{}
+	for _, registry := range manager.Config().Registries {
+		if strings.HasPrefix(registry.Name, word) {
+			candidates = append(candidates, Candidate{Value: registry.Name})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyCompletionManager struct {
+	*bpm.DummyManager
+}
+
+func (manager *dummyCompletionManager) List(ctx context.Context) ([]bpm.PackageStatus, error) {
+	return []bpm.PackageStatus{{Name: "helm"}, {Name: "kubectl", Version: "v1.30.1"}, {Name: "k9s"}}, nil
+}
+
+func (manager *dummyCompletionManager) Installed(ctx context.Context) ([]bpm.PackageStatus, error) {
+	return []bpm.PackageStatus{{Name: "kubectl", Version: "v1.30.1"}}, nil
+}
+
+func (manager *dummyCompletionManager) Versions(ctx context.Context, name string) ([]string, error) {
+	return []string{"v1.30.1", "v1.30.0", "v1.29.0"}, nil
+}
+
+// testCompletions checks the completed values of the output.
+func testCompletions(values ...string) testFunc {
+	return func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+		completed := []string{}
+		for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+			if value, _, _ := bytes.Cut(line, []byte("\t")); len(value) > 0 {
+				completed = append(completed, string(value))
+			}
+		}
+		return assert.Equal(t, append([]string{}, values...), completed)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	cmd := "completion"
+	tests := []testConfig{
+		{
+			name:     "bash",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "bash"},
+			testFunc: testOutputContains("complete -o default -F _bpm_complete bpm\n"),
+		},
+		{
+			name:     "zsh",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "zsh"},
+			testFunc: testOutputContains("value=\"${line%%$'\\t'*}\"\n"),
+		},
+		{
+			name:     "fish",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "fish"},
+			testFunc: testOutputContains("complete -c bpm -f -a '(__bpm_complete)'\n"),
+		},
+		{
+			name:     "unknown",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd, "tcsh"},
+			testFunc: emptyTestFunc,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
+
+func TestBashCompletionScript(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := t.TempDir()
+	argsPath := filepath.Join(dir, "args")
+	// the stub prints the candidates of __complete and records its arguments
+	stub := filepath.Join(dir, "bpm")
+	err = os.WriteFile(stub, []byte("#!/bin/sh\nshift 2\nprintf '%s\\n' \"$@\" > \"$ARGS_PATH\"\nprintf '%s\\tdescription\\n' $CANDIDATES\n"), 0o755)
+	if !assert.NoError(t, err) {
+		return
+	}
+	script := fmt.Sprintf(completionScripts[SHELL_BASH], stub)
+	tests := []struct {
+		name       string
+		words      []string
+		candidates string
+		args       []string
+		reply      []string
+	}{
+		{name: "command", words: []string{"bpm", "ins"}, candidates: "install", args: []string{"ins"}, reply: []string{"install"}},
+		{name: "name", words: []string{"bpm", "install", "kub"}, candidates: "kubectl", args: []string{"install", "kub"}, reply: []string{"kubectl"}},
+		{name: "at", words: []string{"bpm", "install", "kubectl", "@"}, candidates: "kubectl@v1.30.1 kubectl@v1.29.0",
+			args: []string{"install", "kubectl@"}, reply: []string{"@v1.30.1", "@v1.29.0"}},
+		{name: "version", words: []string{"bpm", "install", "kubectl", "@", "v1.3"}, candidates: "kubectl@v1.30.1",
+			args: []string{"install", "kubectl@v1.3"}, reply: []string{"v1.30.1"}},
+		{name: "empty", words: []string{"bpm", "install", "kubectl", "@", "v1.30.1", ""}, candidates: "helm",
+			args: []string{"install", "kubectl@v1.30.1", ""}, reply: []string{"helm"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quoted := make([]string, 0, len(test.words))
+			for _, word := range test.words {
+				quoted = append(quoted, "'"+word+"'")
+			}
+			command := fmt.Sprintf("%s\nCOMP_WORDS=(%s)\nCOMP_CWORD=%d\n_bpm_complete\nprintf '%%s\\n' \"${COMPREPLY[@]}\"\n",
+				script, strings.Join(quoted, " "), len(test.words)-1)
+			cmd := exec.Command(bash, "--norc", "--noprofile", "-c", command)
+			cmd.Env = append(os.Environ(), "ARGS_PATH="+argsPath, "CANDIDATES="+test.candidates)
+			output, err := cmd.CombinedOutput()
+			if !assert.NoError(t, err, string(output)) {
+				return
+			}
+			assert.Equal(t, test.reply, strings.Split(strings.TrimSuffix(string(output), "\n"), "\n"))
+			args, err := os.ReadFile(argsPath)
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.args, strings.Split(strings.TrimSuffix(string(args), "\n"), "\n"))
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	cmd := "__complete"
+	created := 0
+	failingManager := func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
+		created++
+		return nil, fmt.Errorf("%w: broken config", bpm.ErrManagerCreate)
+	}
+	tests := []testConfig{
+		{
+			name:     "commands-without-manager",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "install", "--f"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return testCompletions("--force")(t, manager, buf) &&
+					assert.Equal(t, 0, created, "commands and options are completed without a manager")
+			},
+			managerCreateFunc: failingManager,
+		},
+		{
+			name:     "packages-without-manager",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "install", "k"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return testCompletions()(t, manager, buf) && assert.Equal(t, 1, created)
+			},
+			managerCreateFunc: failingManager,
+		},
+		{
+			name:     "commands",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "in"},
+			testFunc: testCompletions("info", "init", "install"),
+		},
+		{
+			name:     "hidden-commands",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "shim"},
+			testFunc: testCompletions("shim"),
+		},
+		{
+			name:     "sub-commands",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "shim", ""},
+			testFunc: testCompletions("add", "list", "remove"),
+		},
+		{
+			name:     "global-options",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "--o"},
+			testFunc: testCompletions("--output"),
+		},
+		{
+			name:     "command-options",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "install", "--f"},
+			testFunc: testCompletions("--force"),
+		},
+		{
+			name:     "option-choices",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "-o", "j"},
+			testFunc: testCompletions("json"),
+		},
+		{
+			name:     "option-value",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "--config", "info", "k"},
+			testFunc: testCompletions(),
+		},
+		{
+			name:     "packages",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "-n", "install", "k"},
+			testFunc: testCompletions("kubectl", "k9s"),
+			manager:  &dummyCompletionManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "versions",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "install", "kubectl@v1.30"},
+			testFunc: testCompletions("kubectl@v1.30.1", "kubectl@v1.30.0"),
+			manager:  &dummyCompletionManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "installed",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "update", "helm", ""},
+			testFunc: testCompletions("kubectl"),
+			manager:  &dummyCompletionManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "values",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "hook", ""},
+			testFunc: testCompletions("bash", "zsh", "fish", "direnv"),
+		},
+		{
+			name:     "after-double-dash",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "exec", "--", "-"},
+			testFunc: testCompletions(),
+		},
+		{
+			name:     "descriptions",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "install", "ku"},
+			testFunc: testOutputContains("kubectl\tv1.30.1\n"),
+			manager:  &dummyCompletionManager{DummyManager: &bpm.DummyManager{}},
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+const (
+	SHELL_BASH = "bash"
+	SHELL_ZSH  = "zsh"
+	SHELL_FISH = "fish"
+
+	// ENV_PATH and ENV_MANPATH contain the folders added by env, so they can be replaced by the next call
+	ENV_PATH    = "BPM_PATH"
+	ENV_MANPATH = "BPM_MANPATH"
+)
+
+type EnvSubCommand struct {
+	outputCommand
+	Opts EnvSubCommandOpts
+}
+type EnvSubCommandOpts struct {
+	Shell string `long:"shell" short:"s" description:"shell syntax of the output" choice:"bash" choice:"zsh" choice:"fish" default:"bash"`
+}
+
+// Environment are the variables env sets.
+type Environment struct {
+	// project manifest of the working directory
+	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+	// folders added to PATH and MANPATH
+	Path    []string `yaml:"path" json:"path"`
+	ManPath []string `yaml:"manpath,omitempty" json:"manpath,omitempty"`
+	// folder with the completions of the shell
+	Completions string `yaml:"completions,omitempty" json:"completions,omitempty"`
+}
+
+func init() {
+	subCommands["env"] = &EnvSubCommand{}
+}
+
+func (cmd *EnvSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("env", "print the environment of bpm and the project",
+		"prints the exports of PATH with the bin folder and the tool versions of the project manifest ("+bpm.ProjectFileName+
+			" in the working directory or its parents), MANPATH with the man folder and loads the completions. "+
+			"Use it with eval \"$(bpm env)\" or bpm env --shell fish | source, see also hook", &cmd.Opts)
+	return err
+}
+
+func (cmd *EnvSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	env, err := environment(ctx, logger, manager, cmd.Opts.Shell)
+	if err != nil {
+		return err
+	}
+	return cmd.output.Render(env, func(writer io.Writer) {
+		path := prependList(os.Getenv("PATH"), os.Getenv(ENV_PATH), env.Path)
+		writeExport(writer, cmd.Opts.Shell, "PATH", path)
+		writeExport(writer, cmd.Opts.Shell, ENV_PATH, strings.Join(env.Path, string(os.PathListSeparator)))
+		if len(env.ManPath) > 0 || os.Getenv(ENV_MANPATH) != "" {
+			manPath := prependList(os.Getenv("MANPATH"), os.Getenv(ENV_MANPATH), env.ManPath)
+			if os.Getenv("MANPATH") == "" {
+				// an empty entry keeps the default search path of man
+				manPath += string(os.PathListSeparator)
+			}
+			writeExport(writer, cmd.Opts.Shell, "MANPATH", manPath)
+			writeExport(writer, cmd.Opts.Shell, ENV_MANPATH, strings.Join(env.ManPath, string(os.PathListSeparator)))
+		}
+		if env.Completions != "" {
+			writeCompletions(writer, cmd.Opts.Shell, env.Completions)
+		}
+	})
+}
+
+// environment returns the folders of the config and the project of the working directory.
+// Tools of the project which are not installed are skipped, so that a prompt hook does not fail. They are
+// only logged at debug level because the hooks run env on every prompt.
+func environment(ctx context.Context, logger zerolog.Logger, manager bpm.Manager, shell string) (Environment, error) {
+	config := manager.Config()
+	env := Environment{Path: []string{}}
+	status, err := projectStatus(ctx, manager)
+	if err == nil {
+		env.Project = status.Path
+		dirs, err := status.PathDirs()
+		if err != nil {
+			logger.Debug().Msg(err.Error())
+		}
+		env.Path = append(env.Path, dirs...)
+	} else if !errors.Is(err, bpm.ErrProjectNotFound) {
+		return env, err
+	}
+	if config.BinFolder != "" {
+		env.Path = append(env.Path, config.BinFolder)
+	}
+	if config.ManFolder != "" {
+		env.ManPath = []string{config.ManFolder}
+	}
+	if config.CompletionsFolder != "" {
+		env.Completions = filepath.Join(config.CompletionsFolder, shell)
+	}
+	return env, nil
+}
+
+// projectStatus returns the project of the working directory.
+func projectStatus(ctx context.Context, manager bpm.Manager) (bpm.ProjectStatus, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return bpm.ProjectStatus{}, err
+	}
+	return manager.Project(ctx, dir)
+}
+
+// prependList puts dirs first in the list value and removes the previously added folders.
+func prependList(value string, previous string, dirs []string) string {
+	remove := append(filepath.SplitList(previous), dirs...)
+	entries := slices.Clone(dirs)
+	if value != "" {
+		for _, entry := range strings.Split(value, string(os.PathListSeparator)) {
+			if entry == "" || !slices.Contains(remove, entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return strings.Join(entries, string(os.PathListSeparator))
+}
+
+// writeExport writes the export of the variable in the syntax of the shell.
+func writeExport(writer io.Writer, shell string, name string, value string) {
+	if shell != SHELL_FISH {
+		fmt.Fprintf(writer, "export %s=%s\n", name, bpm.ShellQuote(value))
+		return
+	}
+	// path variables are lists in fish
+	if name == "PATH" || name == "MANPATH" {
+		values := []string{}
+		for _, entry := range strings.Split(value, string(os.PathListSeparator)) {
+			values = append(values, fishQuote(entry))
+		}
+		fmt.Fprintf(writer, "set -gx %s %s\n", name, strings.Join(values, " "))
+		return
+	}
+	fmt.Fprintf(writer, "set -gx %s %s\n", name, fishQuote(value))
+}
+
+// writeCompletions writes the commands loading the completions of the folder.
+func writeCompletions(writer io.Writer, shell string, dir string) {
+	switch shell {
+	case SHELL_FISH:
+		fmt.Fprintf(writer, "contains %[1]s $fish_complete_path; or set -g fish_complete_path %[1]s $fish_complete_path\n", fishQuote(dir))
+	case SHELL_ZSH:
+		fmt.Fprintf(writer, "fpath=(%[1]s ${fpath:#%[1]s})\n", bpm.ShellQuote(dir))
+	default:
+		// load the completions only once per shell
+		fmt.Fprintf(writer, "if [ \"${_bpm_completions:-}\" != %[1]s ]; then for _bpm_file in %[1]s/*; do [ -r \"$_bpm_file\" ] && . \"$_bpm_file\"; done; unset _bpm_file; _bpm_completions=%[1]s; fi\n", bpm.ShellQuote(dir))
+	}
+}
+
+// fishQuote quotes value for fish.
+func fishQuote(value string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), "'", `\'`) + "'"
+}
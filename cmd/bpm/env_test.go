@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyProjectManager struct {
+	*bpm.DummyManager
+	status bpm.ProjectStatus
+	err    error
+}
+
+func (manager *dummyProjectManager) Project(ctx context.Context, dir string) (bpm.ProjectStatus, error) {
+	return manager.status, manager.err
+}
+
+// writeStoreTool writes an executable shell script into dir and returns the installed project tool.
+func writeStoreTool(t *testing.T, dir string, name string, script string) bpm.ProjectTool {
+	err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755)
+	assert.NoError(t, err)
+	return bpm.ProjectTool{Name: name, Version: "v1.0.0", Dir: dir, Installed: true}
+}
+
+// getEnvManager returns a project manager with the folders of the config set.
+func getEnvManager(status bpm.ProjectStatus, err error) *dummyProjectManager {
+	manager := &dummyProjectManager{DummyManager: &bpm.DummyManager{}, status: status, err: err}
+	manager.Config().BinFolder = "/home/user/bin"
+	manager.Config().ManFolder = "/home/user/man"
+	manager.Config().CompletionsFolder = "/home/user/completions"
+	return manager
+}
+
+func TestEnv(t *testing.T) {
+	cmd := "env"
+	t.Setenv("PATH", "/store/old/v0:/usr/bin")
+	t.Setenv(ENV_PATH, "/store/old/v0:/home/user/bin")
+	t.Setenv("MANPATH", "")
+	t.Setenv(ENV_MANPATH, "")
+	noProject := fmt.Errorf("%w: .bpm.yaml", bpm.ErrProjectNotFound)
+	project := bpm.ProjectStatus{Path: "/project/.bpm.yaml", Tools: []bpm.ProjectTool{
+		{Name: "a", Version: "v1", Dir: "/store/a/v1", Installed: true},
+		{Name: "it's", Version: "v2", Dir: "/store/it's/v2", Installed: true},
+		{Name: "c", Version: "v3", Dir: "/store/c/v3"},
+	}}
+	var managerOptions bpm.ManagerOptions
+	stateOnly := func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
+		managerOptions = options
+		return getEnvManager(bpm.ProjectStatus{}, noProject), nil
+	}
+	tests := []testConfig{
+		{
+			name:     "no-project",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: testOutputContains("export PATH='/home/user/bin:/usr/bin'\n" +
+				"export BPM_PATH='/home/user/bin'\n" +
+				"export MANPATH='/home/user/man:'\n" +
+				"export BPM_MANPATH='/home/user/man'\n" +
+				"if [ \"${_bpm_completions:-}\" != '/home/user/completions/bash' ]; then"),
+			manager: getEnvManager(bpm.ProjectStatus{}, noProject),
+		},
+		{
+			name:     "project",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: testOutputContains("export PATH='/store/a/v1:/store/it'\\''s/v2:/home/user/bin:/usr/bin'\n" +
+				"export BPM_PATH='/store/a/v1:/store/it'\\''s/v2:/home/user/bin'\n"),
+			manager: getEnvManager(project, nil),
+		},
+		{
+			name:     "zsh",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--shell", "zsh"},
+			testFunc: testOutputContains("fpath=('/home/user/completions/zsh' ${fpath:#'/home/user/completions/zsh'})\n"),
+			manager:  getEnvManager(bpm.ProjectStatus{}, noProject),
+		},
+		{
+			name:     "fish",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--shell", "fish"},
+			testFunc: testOutputContains("set -gx PATH '/store/a/v1' '/store/it\\'s/v2' '/home/user/bin' '/usr/bin'\n" +
+				"set -gx BPM_PATH '/store/a/v1:/store/it\\'s/v2:/home/user/bin'\n" +
+				"set -gx MANPATH '/home/user/man' ''\n" +
+				"set -gx BPM_MANPATH '/home/user/man'\n" +
+				"contains '/home/user/completions/fish' $fish_complete_path; or set -g fish_complete_path '/home/user/completions/fish' $fish_complete_path\n"),
+			manager: getEnvManager(project, nil),
+		},
+		{
+			name:     "json",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"-o", "json", cmd},
+			testFunc: testOutputContains(`"project": "/project/.bpm.yaml"`),
+			manager:  getEnvManager(project, nil),
+		},
+		{
+			name:     "broken-project",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd},
+			testFunc: emptyTestFunc,
+			manager:  getEnvManager(bpm.ProjectStatus{}, fmt.Errorf("%w: broken", bpm.ErrProjectLoad)),
+		},
+		{
+			name:     "state-only",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.True(t, managerOptions.StateOnly, "the prompt hooks load neither providers nor package files")
+			},
+			managerCreateFunc: stateOnly,
+		},
+		{
+			name:     "read-only",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Equal(t, 0, manager.(*dummyProjectManager).GetCounter("SaveState"), "the prompt hooks must not rewrite the state")
+			},
+			manager: getEnvManager(project, nil),
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
+
+func TestPrependList(t *testing.T) {
+	assert.Equal(t, "/a:/b:/usr/bin", prependList("/usr/bin", "", []string{"/a", "/b"}))
+	assert.Equal(t, "/a:/usr/bin", prependList("/old:/a:/usr/bin", "/old:/a", []string{"/a"}), "previous folders are replaced")
+	assert.Equal(t, "/a", prependList("", "", []string{"/a"}))
+	assert.Equal(t, "/a::/usr/bin", prependList(":/usr/bin", "", []string{"/a"}), "empty entries are kept")
+}
+
+func TestExec(t *testing.T) {
+	cmd := "exec"
+	dir := t.TempDir()
+	tool := writeStoreTool(t, dir, "tool", `echo "tool $@"`)
+	failing := writeStoreTool(t, dir, "failing", "exit 5")
+	status := bpm.ProjectStatus{Tools: []bpm.ProjectTool{tool, failing}}
+	tests := []testConfig{
+		{
+			name:     "run",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "tool", "--flag", "arg"},
+			testFunc: testOutputContains("tool --flag arg\n"),
+			manager:  &dummyProjectManager{DummyManager: &bpm.DummyManager{}, status: status},
+		},
+		{
+			name:     "read-only",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "tool"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Equal(t, 0, manager.(*dummyProjectManager).GetCounter("SaveState"), "the command may have changed the state meanwhile")
+			},
+			manager: &dummyProjectManager{DummyManager: &bpm.DummyManager{}, status: status},
+		},
+		{
+			name:     "path",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--", "sh", "-c", "echo $PATH"},
+			testFunc: testOutputContains(dir + string(os.PathListSeparator)),
+			manager:  &dummyProjectManager{DummyManager: &bpm.DummyManager{}, status: status},
+		},
+		{
+			name:     "exit-code",
+			exitCode: 5,
+			args:     []string{cmd, "--", "failing"},
+			testFunc: emptyTestFunc,
+			manager:  &dummyProjectManager{DummyManager: &bpm.DummyManager{}, status: status},
+		},
+		{
+			name:     "not-found",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd, "--", "bpm-missing-command"},
+			testFunc: testOutputContains("executable file not found"),
+			manager:  &dummyProjectManager{DummyManager: &bpm.DummyManager{}, status: status},
+		},
+		{
+			name:     "missing-command",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{cmd},
+			testFunc: emptyTestFunc,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type ExecSubCommand struct {
+	outputCommand
+	Opts ExecSubCommandOpts
+}
+type ExecSubCommandOpts struct {
+	Args struct {
+		Command []string `required:"1"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func init() {
+	subCommands["exec"] = &ExecSubCommand{}
+}
+
+func (cmd *ExecSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("exec", "run a command with the project tools",
+		"runs the command with the tool versions of the project manifest ("+bpm.ProjectFileName+
+			" in the working directory or its parents) first on PATH. "+
+			"Separate the command with -- (e.g. bpm exec -- helm version). The exit code of the command is returned", &cmd.Opts)
+	return err
+}
+
+func (cmd *ExecSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	status, err := projectStatus(ctx, manager)
+	if err != nil {
+		return err
+	}
+	env, err := bpm.ProjectEnviron(status, os.Environ())
+	if err != nil {
+		return err
+	}
+	args := cmd.Opts.Args.Command
+	path, err := lookPathEnv(args[0], env)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Msgf("run %s", path)
+	// not bound to ctx: the terminal sends ctrl-c to the command itself, which decides how to exit
+	command := exec.Command(path, args[1:]...)
+	command.Env = env
+	command.Stdin = os.Stdin
+	command.Stdout = cmd.output.Writer
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// lookPathEnv searches the executable name in the PATH of env. exec.LookPath
+// cannot be used because it searches the PATH of this process.
+func lookPathEnv(name string, env []string) (string, error) {
+	if strings.Contains(name, string(os.PathSeparator)) {
+		return name, nil
+	}
+	for _, value := range env {
+		path, ok := strings.CutPrefix(value, "PATH=")
+		if !ok {
+			continue
+		}
+		for _, dir := range filepath.SplitList(path) {
+			if dir == "" {
+				continue
+			}
+			file := filepath.Join(dir, name)
+			if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0 {
+				return file, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: %s", exec.ErrNotFound, name)
+}
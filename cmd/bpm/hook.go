@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+const SHELL_DIRENV = "direnv"
+
+type HookSubCommand struct {
+	outputCommand
+	Opts HookSubCommandOpts
+}
+type HookSubCommandOpts struct {
+	Args struct {
+		Shell string `description:"bash, zsh, fish or direnv"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// hookScripts are the hooks of the shells, %[1]s is the bpm command and %[2]s the shell.
+var hookScripts = map[string]string{
+	SHELL_BASH: `_bpm_hook() {
+  local previous_exit_status=$?
+  eval "$(%[1]s env --shell %[2]s)"
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND[*]:-};" != *";_bpm_hook;"* ]]; then
+  PROMPT_COMMAND="_bpm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`,
+	SHELL_ZSH: `_bpm_hook() {
+  eval "$(%[1]s env --shell %[2]s)"
+}
+typeset -ag precmd_functions
+if (( ! ${precmd_functions[(I)_bpm_hook]} )); then
+  precmd_functions=(_bpm_hook $precmd_functions)
+fi
+`,
+	SHELL_FISH: `function _bpm_hook --on-event fish_prompt
+  %[1]s env --shell %[2]s | source
+end
+`,
+	// used in the direnvrc, projects activate it with "use bpm" in the .envrc
+	SHELL_DIRENV: `use_bpm() {
+  watch_file ` + bpm.ProjectFileName + `
+  eval "$(%[1]s env --shell bash)"
+}
+`,
+}
+
+func init() {
+	subCommands["hook"] = &HookSubCommand{}
+}
+
+func (cmd *HookSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("hook", "print the shell hook activating the project tools",
+		"prints a hook which updates the environment (see env) before each prompt. "+
+			"Add eval \"$(bpm hook bash)\" to ~/.bashrc, eval \"$(bpm hook zsh)\" to ~/.zshrc or "+
+			"bpm hook fish | source to ~/.config/fish/config.fish. "+
+			"For direnv add eval \"$(bpm hook direnv)\" to ~/.config/direnv/direnvrc and use bpm to the .envrc", &cmd.Opts)
+	return err
+}
+
+func (cmd *HookSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	shell := cmd.Opts.Args.Shell
+	script, ok := hookScripts[shell]
+	if !ok {
+		return fmt.Errorf("%w: unknown shell %s (use %s, %s, %s or %s)", bpm.ErrInvalidInput, shell, SHELL_BASH, SHELL_ZSH, SHELL_FISH, SHELL_DIRENV)
+	}
+	command, err := bpmCommand(manager)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cmd.output.Writer, script, command, shell)
+	return err
+}
+
+// bpmCommand returns the quoted command calling this binary with the config of the manager.
+func bpmCommand(manager bpm.Manager) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	args := []string{bpm.ShellQuote(executable)}
+	if path := manager.Config().Path; path != "" {
+		args = append(args, "--config", bpm.ShellQuote(path))
+	}
+	return strings.Join(args, " "), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+)
+
+func TestHook(t *testing.T) {
+	cmd := "hook"
+	configManager := &bpm.DummyManager{}
+	configManager.Config().Path = "/home/user/.config/bpm/it's.yaml"
+	tests := []testConfig{
+		{
+			name:     "bash",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "bash"},
+			testFunc: testOutputContains(" env --shell bash)\"\n"),
+		},
+		{
+			name:     "zsh",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "zsh"},
+			testFunc: testOutputContains("precmd_functions=(_bpm_hook $precmd_functions)"),
+		},
+		{
+			name:     "fish",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "fish"},
+			testFunc: testOutputContains(" env --shell fish | source\n"),
+		},
+		{
+			name:     "direnv",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "direnv"},
+			testFunc: testOutputContains("watch_file .bpm.yaml\n"),
+		},
+		{
+			name:     "config",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "bash"},
+			testFunc: testOutputContains(` --config '/home/user/.config/bpm/it'\''s.yaml' env --shell bash`),
+			manager:  configManager,
+		},
+		{
+			name:     "unknown",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd, "tcsh"},
+			testFunc: emptyTestFunc,
+		},
+		{
+			name:     "missing",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{cmd},
+			testFunc: emptyTestFunc,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
+
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -8,6 +12,7 @@ import (
 )
 
 type InfoSubCommand struct {
+	outputCommand
 	Opts InfoSubCommandOpts
 }
 type InfoSubCommandOpts struct {
@@ -25,6 +30,31 @@ func (cmd *InfoSubCommand) AddCommand(parser *flags.Parser) error {
 	return err
 }
 
-func (cmd *InfoSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.Info(cmd.Opts.Args.Name)
+func (cmd *InfoSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	info, err := manager.Info(ctx, cmd.Opts.Args.Name)
+	if err != nil {
+		return err
+	}
+	return cmd.output.Render(info, func(writer io.Writer) {
+		rows := []struct {
+			key   string
+			value string
+		}{
+			{"name", info.Name},
+			{"provider", info.Provider},
+			{"url", info.URL},
+			{"asset pattern", info.AssetPattern},
+			{"archive format", info.ArchiveFormat},
+			{"bin pattern", info.BinPattern},
+			{"download url", info.DownloadURL},
+			{"install type", info.InstallType},
+			{"version", installedVersion(info.Version, info.Version != "")},
+		}
+		for _, row := range rows {
+			if row.value == "" {
+				continue
+			}
+			fmt.Fprintf(writer, "%s:\t%s\n", row.key, row.value)
+		}
+	})
 }
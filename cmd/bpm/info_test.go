@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 	"testing"
 
@@ -14,11 +15,14 @@ type dummyInfoManager struct {
 
 type testInfoFunc func(t *testing.T, name string)
 
-func (manager *dummyInfoManager) Info(name string) error {
+func (manager *dummyInfoManager) Info(ctx context.Context, name string) (bpm.PackageInfo, error) {
 	if manager.infoTestFunc != nil {
 		manager.infoTestFunc(manager.DummyManager.T, name)
 	}
-	return nil
+	info := bpm.PackageInfo{Version: "v1.0.0"}
+	info.Name = name
+	info.Provider = "github"
+	return info, nil
 }
 
 type testInfoConfig struct {
@@ -42,12 +46,28 @@ func TestInfo(t *testing.T) {
 				name:     "success",
 				exitCode: EXIT_SUCCESS,
 				args:     []string{cmd, "testName"},
-				testFunc: emptyTestFunc,
+				testFunc: testOutputContains("version:   v1.0.0\n"),
 			},
 			infoTestFunc: func(t *testing.T, name string) {
 				assert.Equal(t, name, "testName")
 			},
 		},
+		{
+			testConfig: testConfig{
+				name:     "json",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"--output", "json", cmd, "testName"},
+				testFunc: testOutputContains(`"version": "v1.0.0"`),
+			},
+		},
+		{
+			testConfig: testConfig{
+				name:     "yaml",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"-o", "yaml", cmd, "testName"},
+				testFunc: testOutputContains("name: testName\n"),
+			},
+		},
 	}
 	for _, testConfig := range tests {
 		testConfig.testConfig.manager = &dummyInfoManager{
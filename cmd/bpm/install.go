@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -8,13 +11,14 @@ import (
 )
 
 type InstallSubCommand struct {
+	outputCommand
 	Opts InstallSubCommandOpts
 }
 type InstallSubCommandOpts struct {
-	Force bool `long:"force" short:"f" description:"force install"`
+	Force bool `long:"force" short:"f" description:"force install (reinstalls the latest release and ignores the update policy and platform checks of the binary)"`
 	Args  struct {
 		Name string
-	} `positional-args:"yes" required:"yes"`
+	} `positional-args:"yes"`
 }
 
 func init() {
@@ -22,10 +26,33 @@ func init() {
 }
 
 func (cmd *InstallSubCommand) AddCommand(parser *flags.Parser) error {
-	_, err := parser.AddCommand("install", "installs a package", "installs a package", &cmd.Opts)
+	_, err := parser.AddCommand("install", "installs a package",
+		"installs a package. Without a package the tool versions of the project manifest ("+bpm.ProjectFileName+
+			" in the working directory or its parents) are installed into the store", &cmd.Opts)
 	return err
 }
 
-func (cmd *InstallSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.Install(cmd.Opts.Args.Name, cmd.Opts.Force)
+func (cmd *InstallSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	if cmd.Opts.Args.Name == "" {
+		return cmd.installProject(ctx, manager)
+	}
+	result, err := manager.Install(ctx, cmd.Opts.Args.Name, cmd.Opts.Force)
+	if err != nil {
+		return err
+	}
+	return cmd.output.RenderResults(manager, []bpm.InstallResult{result})
+}
+
+// installProject installs the tools of the project manifest of the working directory.
+func (cmd *InstallSubCommand) installProject(ctx context.Context, manager bpm.Manager) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	results, err := manager.InstallProject(ctx, dir)
+	renderErr := cmd.output.RenderResults(manager, results)
+	if err != nil {
+		return err
+	}
+	return renderErr
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 	"testing"
 
@@ -14,11 +16,11 @@ type dummyInstallManager struct {
 
 type testInstallFunc func(t *testing.T, name string, force bool)
 
-func (manager *dummyInstallManager) Install(name string, force bool) error {
+func (manager *dummyInstallManager) Install(ctx context.Context, name string, force bool) (bpm.InstallResult, error) {
 	if manager.installTestFunc != nil {
 		manager.installTestFunc(manager.DummyManager.T, name, force)
 	}
-	return nil
+	return bpm.InstallResult{Name: name, Version: "v1.0.0", Changed: true}, nil
 }
 
 type testInstallConfig struct {
@@ -31,10 +33,14 @@ func TestInstall(t *testing.T) {
 	tests := []testInstallConfig{
 		{
 			testConfig: testConfig{
-				name:     "empty",
-				exitCode: EXIT_CONFIG_ERROR,
+				name:     "project",
+				exitCode: EXIT_SUCCESS,
 				args:     []string{cmd},
-				testFunc: testOutputContains("the required argument `Name` was not provided"),
+				testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+					installManager := manager.(*dummyInstallManager)
+					return assert.Equal(t, 1, installManager.GetCounter("InstallProject"), "without a name the project is installed") &&
+						assert.Equal(t, 0, installManager.GetCounter("Install"))
+				},
 			},
 		},
 		{
@@ -42,13 +48,23 @@ func TestInstall(t *testing.T) {
 				name:     "success",
 				exitCode: EXIT_SUCCESS,
 				args:     []string{cmd, "testName"},
-				testFunc: emptyTestFunc,
+				testFunc: testOutputContains("testName  not installed  =>  v1.0.0\n"),
 			},
 			installTestFunc: func(t *testing.T, name string, force bool) {
 				assert.Equal(t, name, "testName")
 				assert.False(t, force, "force should be false on default")
 			},
 		},
+		{
+			testConfig: testConfig{
+				name:     "dry-run",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"-n", cmd, "testName"},
+				testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+					return assert.True(t, manager.Config().DryRun, "dry run should be set in the config")
+				},
+			},
+		},
 		{
 			testConfig: testConfig{
 				name:     "success with force",
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type LintSubCommand struct {
+	outputCommand
+	Opts LintSubCommandOpts
+}
+type LintSubCommandOpts struct {
+	Args struct {
+		Files []string
+	} `positional-args:"true"`
+}
+
+// ErrLintFailed is returned if lint found problems.
+var ErrLintFailed = errors.New("lint found problems")
+
+func init() {
+	subCommands["lint"] = &LintSubCommand{}
+}
+
+func (cmd *LintSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("lint", "check package files",
+		"checks package files for unknown fields, invalid values, regular expressions, placeholders "+
+			"and providers. If no file is given all package files and templates are checked", &cmd.Opts)
+	return err
+}
+
+func (cmd *LintSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	issues, err := manager.Lint(ctx, cmd.Opts.Args.Files)
+	if err != nil {
+		return err
+	}
+	err = cmd.output.Render(issues, func(writer io.Writer) {
+		if len(issues) == 0 {
+			fmt.Fprintln(writer, "no problems found")
+			return
+		}
+		fmt.Fprintf(writer, "FILE\tFIELD\tMESSAGE\n")
+		for _, issue := range issues {
+			path := issue.Path
+			if issue.Line > 0 {
+				path = fmt.Sprintf("%s:%d", path, issue.Line)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", path, valueOrDash(issue.Field), issue.Message)
+		}
+	})
+	if err == nil && len(issues) > 0 {
+		return fmt.Errorf("%w: %d problems", ErrLintFailed, len(issues))
+	}
+	return err
+}
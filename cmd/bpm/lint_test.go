@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/rs/zerolog"
+)
+
+type dummyLintManager struct {
+	*bpm.DummyManager
+	issues []bpm.LintIssue
+}
+
+func (manager *dummyLintManager) Lint(ctx context.Context, paths []string) ([]bpm.LintIssue, error) {
+	return manager.issues, nil
+}
+
+func TestLint(t *testing.T) {
+	cmd := "lint"
+	issues := []bpm.LintIssue{
+		{Path: "tool.yaml", Line: 4, Field: "archive_fromat", Message: "unknown field archive_fromat"},
+		{Path: "other.yaml", Message: "file is empty"},
+	}
+	brokenPackages := func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
+		return &bpm.DummyManager{}, fmt.Errorf("%w: %w: broken.yaml", bpm.ErrManagerCreate, bpm.ErrPackageFiles)
+	}
+	tests := []testConfig{
+		{
+			name:     "no-problems",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "tool.yaml"},
+			testFunc: testOutputContains("no problems found\n"),
+		},
+		{
+			name:     "problems",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd},
+			testFunc: testOutputContains("FILE         FIELD           MESSAGE\n" +
+				"tool.yaml:4  archive_fromat  unknown field archive_fromat\n" +
+				"other.yaml   -               file is empty\n"),
+			manager: &dummyLintManager{DummyManager: &bpm.DummyManager{}, issues: issues},
+		},
+		{
+			name:              "broken-package-files",
+			exitCode:          EXIT_SUCCESS,
+			args:              []string{cmd},
+			testFunc:          testOutputContains("no problems found\n"),
+			managerCreateFunc: brokenPackages,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
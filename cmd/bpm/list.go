@@ -1,6 +1,10 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
+
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -8,9 +12,11 @@ import (
 )
 
 type ListSubCommand struct {
+	outputCommand
 	Opts ListSubCommandOpts
 }
 type ListSubCommandOpts struct {
+	Installed bool `long:"installed" short:"i" description:"list only installed packages"`
 }
 
 func init() {
@@ -18,10 +24,29 @@ func init() {
 }
 
 func (cmd *ListSubCommand) AddCommand(parser *flags.Parser) error {
-	_, err := parser.AddCommand("list", "list packages", "list packages", &cmd.Opts)
+	_, err := parser.AddCommand("list", "list packages", "list configured packages with their installed version", &cmd.Opts)
 	return err
 }
 
-func (cmd *ListSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.List()
+func (cmd *ListSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	var packages []bpm.PackageStatus
+	var err error
+	if cmd.Opts.Installed {
+		packages, err = manager.Installed(ctx)
+	} else {
+		packages, err = manager.List(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return cmd.output.Render(packages, func(writer io.Writer) {
+		renderPackageStatus(writer, packages)
+	})
+}
+
+func renderPackageStatus(writer io.Writer, packages []bpm.PackageStatus) {
+	fmt.Fprintf(writer, "NAME\tPROVIDER\tVERSION\n")
+	for _, pkg := range packages {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", pkg.Name, pkg.Provider, installedVersion(pkg.Version, pkg.Installed))
+	}
 }
@@ -1,19 +1,77 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/stretchr/testify/assert"
 )
 
+type dummyListManager struct {
+	*bpm.DummyManager
+}
+
+func (manager *dummyListManager) List(ctx context.Context) ([]bpm.PackageStatus, error) {
+	manager.DummyManager.List(ctx)
+	return []bpm.PackageStatus{
+		{Name: "a", Provider: "github", Version: "v1.0.0", Installed: true},
+		{Name: "b", Provider: "github"},
+	}, nil
+}
+
+func (manager *dummyListManager) Installed(ctx context.Context) ([]bpm.PackageStatus, error) {
+	manager.DummyManager.Installed(ctx)
+	return []bpm.PackageStatus{
+		{Name: "a", Provider: "github", Version: "v1.0.0", Installed: true},
+	}, nil
+}
+
+func testListCounter(counter string, output string) testFunc {
+	return func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+		listManager := manager.(*dummyListManager)
+		assert.Equal(t, 1, listManager.GetCounter(counter), "%s should be called one time", counter)
+		return assert.Equal(t, output, buf.String())
+	}
+}
+
 func TestList(t *testing.T) {
 	tests := []testConfig{
 		{
 			name:     "list",
 			exitCode: EXIT_SUCCESS,
 			args:     []string{"list"},
-			testFunc: emptyTestFunc,
+			testFunc: testListCounter("List", "NAME  PROVIDER  VERSION\na     github    v1.0.0\nb     github    not installed\n"),
+		},
+		{
+			name:     "installed",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"list", "--installed"},
+			testFunc: testListCounter("Installed", "NAME  PROVIDER  VERSION\na     github    v1.0.0\n"),
+		},
+		{
+			name:     "json",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"-o", "json", "list", "-i"},
+			testFunc: testListCounter("Installed", "[\n  {\n    \"name\": \"a\",\n    \"provider\": \"github\",\n    \"version\": \"v1.0.0\",\n    \"installed\": true\n  }\n]\n"),
+		},
+		{
+			name:     "yaml",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"--output", "yaml", "list", "-i"},
+			testFunc: testListCounter("Installed", "- name: a\n  provider: github\n  version: v1.0.0\n  installed: true\n"),
+		},
+		{
+			name:     "unknown-format",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{"--output", "xml", "list"},
+			testFunc: testOutputContains("Invalid value `xml'"),
 		},
 	}
 	for _, testConfig := range tests {
+		testConfig.manager = &dummyListManager{DummyManager: &bpm.DummyManager{}}
 		runTest(t, &testConfig)
 	}
 }
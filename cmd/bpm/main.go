@@ -9,5 +9,5 @@ import (
 )
 
 func main() {
-	os.Exit(run(bpm.NewManager, os.Stderr, os.Stderr, os.Args[1:]))
+	os.Exit(run(bpm.NewManager, os.Stdout, os.Stderr, os.Stderr, os.Args[1:]))
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -21,6 +22,6 @@ func (cmd *MigrateSubCommand) AddCommand(parser *flags.Parser) error {
 	return err
 }
 
-func (cmd *MigrateSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
+func (cmd *MigrateSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
 	return manager.Migrate()
 }
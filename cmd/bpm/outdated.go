@@ -1,6 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -8,9 +14,15 @@ import (
 )
 
 type OutdatedSubCommand struct {
+	outputCommand
 	Opts OutdatedSubCommandOpts
 }
-type OutdatedSubCommandOpts struct{}
+type OutdatedSubCommandOpts struct {
+	ExitCode bool `long:"exit-code" description:"exit with code 4 if updates are available"`
+}
+
+// ErrUpdatesAvailable is returned with --exit-code if packages are outdated.
+var ErrUpdatesAvailable = errors.New("updates available")
 
 func init() {
 	subCommands["outdated"] = &OutdatedSubCommand{}
@@ -21,6 +33,66 @@ func (cmd *OutdatedSubCommand) AddCommand(parser *flags.Parser) error {
 	return err
 }
 
-func (cmd *OutdatedSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.Outdated()
+func (cmd *OutdatedSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	entries, err := manager.Outdated(ctx)
+	if err != nil {
+		return err
+	}
+	heldBack := false
+	blocked := false
+	updatable := 0
+	for _, entry := range entries {
+		if entry.HeldBack != "" {
+			heldBack = true
+		}
+		if entry.Blocked != "" {
+			blocked = true
+		}
+		if entry.Updatable() {
+			updatable++
+		}
+	}
+	err = cmd.output.Render(entries, func(writer io.Writer) {
+		// held back and blocked columns are only shown if needed
+		fmt.Fprint(writer, "NAME\tCURRENT\tLATEST")
+		if heldBack {
+			fmt.Fprint(writer, "\tHELD BACK")
+		}
+		if blocked {
+			fmt.Fprint(writer, "\tBLOCKED BY POLICY")
+		}
+		fmt.Fprintln(writer)
+		for _, entry := range entries {
+			fmt.Fprintf(writer, "%s\t%s\t%s", entry.Name, entry.Current, entry.Latest)
+			if heldBack {
+				fmt.Fprintf(writer, "\t%s", formatHeldBack(entry))
+			}
+			if blocked {
+				fmt.Fprintf(writer, "\t%s", valueOrDash(entry.Blocked))
+			}
+			fmt.Fprintln(writer)
+		}
+	})
+	if err == nil && cmd.Opts.ExitCode && updatable > 0 {
+		return fmt.Errorf("%w: %d packages", ErrUpdatesAvailable, updatable)
+	}
+	return err
+}
+
+// formatHeldBack returns the held back version with the date it becomes eligible.
+func formatHeldBack(entry bpm.OutdatedEntry) string {
+	if entry.HeldBack == "" {
+		return "-"
+	}
+	if entry.EligibleAt == nil {
+		return entry.HeldBack
+	}
+	return fmt.Sprintf("%s (eligible %s)", entry.HeldBack, entry.EligibleAt.Format(time.DateOnly))
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
 }
@@ -1,9 +1,23 @@
 package main
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/jduepmeier/binary-package-manager"
 )
 
+type dummyOutdatedManager struct {
+	*bpm.DummyManager
+}
+
+func (manager *dummyOutdatedManager) Outdated(ctx context.Context) ([]bpm.OutdatedEntry, error) {
+	return []bpm.OutdatedEntry{
+		{Name: "tool", Current: "v1.0.0", Latest: "v1.1.0"},
+	}, nil
+}
+
 func TestOutdated(t *testing.T) {
 	cmd := "outdated"
 	tests := []testConfig{
@@ -11,10 +25,106 @@ func TestOutdated(t *testing.T) {
 			name:     cmd,
 			exitCode: EXIT_SUCCESS,
 			args:     []string{cmd},
-			testFunc: emptyTestFunc,
+			testFunc: testOutputContains("NAME  CURRENT  LATEST\ntool  v1.0.0   v1.1.0\n"),
+		},
+		{
+			name:     "json",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"-o", "json", cmd},
+			testFunc: testOutputContains(`"latest": "v1.1.0"`),
+		},
+		{
+			name:     "exit-code",
+			exitCode: EXIT_OUTDATED,
+			args:     []string{cmd, "--exit-code"},
+			testFunc: testOutputContains("tool  v1.0.0   v1.1.0\n"),
+		},
+	}
+	for _, testConfig := range tests {
+		testConfig.manager = &dummyOutdatedManager{DummyManager: &bpm.DummyManager{}}
+		runTest(t, &testConfig)
+	}
+}
+
+type dummyHeldBackManager struct {
+	*bpm.DummyManager
+}
+
+func (manager *dummyHeldBackManager) Outdated(ctx context.Context) ([]bpm.OutdatedEntry, error) {
+	eligibleAt := time.Date(2024, 5, 12, 12, 0, 0, 0, time.UTC)
+	return []bpm.OutdatedEntry{
+		{Name: "held", Current: "v1.0.0", Latest: "v1.0.0", HeldBack: "v1.2.0", EligibleAt: &eligibleAt},
+		{Name: "tool", Current: "v1.0.0", Latest: "v1.1.0"},
+	}, nil
+}
+
+func TestOutdatedHeldBack(t *testing.T) {
+	cmd := "outdated"
+	tests := []testConfig{
+		{
+			name:     cmd,
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: testOutputContains(
+				"NAME  CURRENT  LATEST  HELD BACK\n" +
+					"held  v1.0.0   v1.0.0  v1.2.0 (eligible 2024-05-12)\n" +
+					"tool  v1.0.0   v1.1.0  -\n"),
+		},
+		{
+			name:     "exit-code",
+			exitCode: EXIT_OUTDATED,
+			args:     []string{cmd, "--exit-code"},
+			testFunc: testOutputContains("tool  v1.0.0   v1.1.0  -\n"),
+		},
+	}
+	for _, testConfig := range tests {
+		testConfig.manager = &dummyHeldBackManager{DummyManager: &bpm.DummyManager{}}
+		runTest(t, &testConfig)
+	}
+}
+
+type dummyBlockedManager struct {
+	*bpm.DummyManager
+}
+
+func (manager *dummyBlockedManager) Outdated(ctx context.Context) ([]bpm.OutdatedEntry, error) {
+	return []bpm.OutdatedEntry{
+		{Name: "major", Current: "v1.0.0", Latest: "v1.0.0", Blocked: "v2.0.0"},
+		{Name: "tool", Current: "v1.0.0", Latest: "v1.1.0", Blocked: "v2.0.0"},
+	}, nil
+}
+
+func TestOutdatedBlocked(t *testing.T) {
+	cmd := "outdated"
+	tests := []testConfig{
+		{
+			name:     cmd,
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: testOutputContains(
+				"NAME   CURRENT  LATEST  BLOCKED BY POLICY\n" +
+					"major  v1.0.0   v1.0.0  v2.0.0\n" +
+					"tool   v1.0.0   v1.1.0  v2.0.0\n"),
+		},
+		{
+			name:     "json",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"-o", "json", cmd},
+			testFunc: testOutputContains(`"blocked": "v2.0.0"`),
 		},
 	}
 	for _, testConfig := range tests {
+		testConfig.manager = &dummyBlockedManager{DummyManager: &bpm.DummyManager{}}
 		runTest(t, &testConfig)
 	}
 }
+
+func TestOutdatedExitCodeUpToDate(t *testing.T) {
+	testConfig := testConfig{
+		name:     "up-to-date",
+		exitCode: EXIT_SUCCESS,
+		args:     []string{"outdated", "--exit-code"},
+		testFunc: emptyTestFunc,
+	}
+	runTest(t, &testConfig)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+	OUTPUT_YAML  = "yaml"
+)
+
+// Output renders structured results of a command in the selected format.
+type Output struct {
+	Format string
+	Writer io.Writer
+}
+
+// OutputSubCommand is implemented by commands that render results.
+type OutputSubCommand interface {
+	SetOutput(output *Output)
+}
+
+// outputCommand can be embedded into commands to implement OutputSubCommand.
+type outputCommand struct {
+	output *Output
+}
+
+func (cmd *outputCommand) SetOutput(output *Output) {
+	cmd.output = output
+}
+
+// Render writes value as json or yaml. For tables the table function is called
+// with a tabwriter, each cell has to be terminated with a tab.
+func (output *Output) Render(value any, table func(writer io.Writer)) error {
+	switch output.Format {
+	case OUTPUT_JSON:
+		encoder := json.NewEncoder(output.Writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case OUTPUT_YAML:
+		encoder := yaml.NewEncoder(output.Writer)
+		encoder.SetIndent(2)
+		err := encoder.Encode(value)
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+	case OUTPUT_TABLE, "":
+		writer := tabwriter.NewWriter(output.Writer, 0, 8, 2, ' ', 0)
+		table(writer)
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown output format %s", output.Format)
+	}
+}
+
+// installedVersion returns the version for table output.
+func installedVersion(version string, installed bool) string {
+	if !installed {
+		return "not installed"
+	}
+	return version
+}
+
+// quietTable reports if the table output is suppressed with --quiet.
+func (output *Output) quietTable(manager bpm.Manager) bool {
+	return manager.Config().Quiet && (output.Format == OUTPUT_TABLE || output.Format == "")
+}
+
+// RenderResults renders install and update results. The table only
+// contains changed and failed packages and is suppressed with --quiet.
+// In dry run mode the download url and the target are shown.
+func (output *Output) RenderResults(manager bpm.Manager, results []bpm.InstallResult) error {
+	if output.quietTable(manager) {
+		return nil
+	}
+	dryRun := manager.Config().DryRun
+	return output.Render(results, func(writer io.Writer) {
+		failed := 0
+		for _, result := range results {
+			previous := result.PreviousVersion
+			if previous == "" {
+				previous = "not installed"
+			}
+			if result.Error != "" {
+				failed++
+				fmt.Fprintf(writer, "%s\t%s\tfailed:\t%s\n", result.Name, previous, result.Error)
+				continue
+			}
+			if !result.Changed {
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%s\t=>\t%s\n", result.Name, previous, result.Version)
+			if dryRun {
+				fmt.Fprintf(writer, "  download %s\n  install to %s\n", result.DownloadURL, result.Target)
+			}
+			renderNotes(writer, result.Notes, "  ")
+		}
+		if failed > 0 {
+			fmt.Fprintf(writer, "%d of %d packages failed\n", failed, len(results))
+		}
+	})
+}
+
+// renderNotes writes the release notes with each line prefixed with indent.
+// Tabs are replaced because the notes are written into a tabwriter.
+func renderNotes(writer io.Writer, notes []bpm.ReleaseNote, indent string) {
+	for _, note := range notes {
+		title := note.Version
+		if note.Name != "" && note.Name != note.Version {
+			title = fmt.Sprintf("%s - %s", note.Version, note.Name)
+		}
+		if !note.Published.IsZero() {
+			title = fmt.Sprintf("%s (%s)", title, note.Published.Format("2006-01-02"))
+		}
+		fmt.Fprintf(writer, "%s## %s\n", indent, title)
+		body := strings.TrimSpace(strings.ReplaceAll(strings.ReplaceAll(note.Body, "\r\n", "\n"), "\t", "    "))
+		if body != "" {
+			for _, line := range strings.Split(body, "\n") {
+				fmt.Fprintf(writer, "%s%s\n", indent, line)
+			}
+		}
+		if note.URL != "" {
+			fmt.Fprintf(writer, "%s%s\n", indent, note.URL)
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+// RenderRemove renders the removed files in dry run mode.
+func (output *Output) RenderRemove(manager bpm.Manager, result bpm.RemoveResult) error {
+	if output.quietTable(manager) {
+		return nil
+	}
+	dryRun := manager.Config().DryRun
+	return output.Render(result, func(writer io.Writer) {
+		if !dryRun {
+			return
+		}
+		for _, file := range result.Files {
+			fmt.Fprintf(writer, "remove %s\n", file)
+		}
+	})
+}
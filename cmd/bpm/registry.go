@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type RegistrySubCommand struct {
+	outputCommand
+	command *flags.Command
+	List    struct{}                     `command:"list" description:"list the configured registries"`
+	Update  RegistryUpdateSubCommandOpts `command:"update" description:"fetch registries (all if no registry is given)"`
+}
+type RegistryUpdateSubCommandOpts struct {
+	Args struct {
+		Registries []string
+	} `positional-args:"true"`
+}
+
+func init() {
+	subCommands["registry"] = &RegistrySubCommand{}
+}
+
+func (cmd *RegistrySubCommand) AddCommand(parser *flags.Parser) error {
+	command, err := parser.AddCommand("registry", "manage package registries",
+		"list or fetch the registries configured in the config file", cmd)
+	if err != nil {
+		return err
+	}
+	cmd.command = command
+	return nil
+}
+
+func (cmd *RegistrySubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	var registries []bpm.RegistryStatus
+	var err error
+	switch cmd.command.Active.Name {
+	case "update":
+		registries, err = manager.UpdateRegistries(ctx, cmd.Update.Args.Registries)
+	default:
+		registries, err = manager.Registries(ctx)
+	}
+	renderErr := cmd.output.Render(registries, func(writer io.Writer) {
+		fmt.Fprintf(writer, "NAME\tTYPE\tURL\tPACKAGES\n")
+		for _, registry := range registries {
+			if registry.Error != "" {
+				fmt.Fprintf(writer, "%s\t%s\t%s\tfailed: %s\n", registry.Name, registry.Type, registry.URL, registry.Error)
+				continue
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%d\n", registry.Name, registry.Type, registry.URL, registry.Packages)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
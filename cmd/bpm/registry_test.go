@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyRegistryManager struct {
+	*bpm.DummyManager
+	names []string
+}
+
+func (manager *dummyRegistryManager) Registries(ctx context.Context) ([]bpm.RegistryStatus, error) {
+	return []bpm.RegistryStatus{
+		{Name: "team", Type: bpm.RegistryTypeGit, URL: "https://example.com/packages.git", Packages: 3},
+	}, nil
+}
+
+func (manager *dummyRegistryManager) UpdateRegistries(ctx context.Context, names []string) ([]bpm.RegistryStatus, error) {
+	manager.names = names
+	if len(names) > 0 && names[0] == "broken" {
+		return []bpm.RegistryStatus{
+			{Name: "broken", Type: bpm.RegistryTypeHTTP, URL: "https://example.com/index.yaml", Error: "404 Not Found"},
+		}, errors.New("registry error")
+	}
+	return manager.Registries(ctx)
+}
+
+func TestRegistry(t *testing.T) {
+	tests := []testConfig{
+		{
+			name:     "missing-subcommand",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{"registry"},
+			testFunc: emptyTestFunc,
+		},
+		{
+			name:     "list",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"registry", "list"},
+			testFunc: testOutputContains("NAME  TYPE  URL                               PACKAGES\nteam  git   https://example.com/packages.git  3\n"),
+		},
+		{
+			name:     "update",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"registry", "update", "team"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				assert.Equal(t, []string{"team"}, manager.(*dummyRegistryManager).names)
+				return assert.Contains(t, buf.String(), "team  git   https://example.com/packages.git  3\n")
+			},
+		},
+		{
+			name:     "update-failed",
+			exitCode: EXIT_ERROR,
+			args:     []string{"registry", "update", "broken"},
+			testFunc: testOutputContains("broken  http  https://example.com/index.yaml  failed: 404 Not Found\n"),
+		},
+	}
+	for _, testConfig := range tests {
+		testConfig.manager = &dummyRegistryManager{DummyManager: &bpm.DummyManager{}}
+		runTest(t, &testConfig)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -8,6 +9,7 @@ import (
 )
 
 type RemoveSubCommand struct {
+	outputCommand
 	Opts RemoveSubCommandOpts
 }
 type RemoveSubCommandOpts struct {
@@ -25,6 +27,10 @@ func (cmd *RemoveSubCommand) AddCommand(parser *flags.Parser) error {
 	return err
 }
 
-func (cmd *RemoveSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.Remove(cmd.Opts.Args.Name)
+func (cmd *RemoveSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	result, err := manager.Remove(ctx, cmd.Opts.Args.Name)
+	if err != nil {
+		return err
+	}
+	return cmd.output.RenderRemove(manager, result)
 }
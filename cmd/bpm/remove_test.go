@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 	"testing"
 
@@ -14,11 +15,11 @@ type dummyRemoveManager struct {
 
 type testRemoveFunc func(t *testing.T, name string)
 
-func (manager *dummyRemoveManager) Remove(name string) error {
+func (manager *dummyRemoveManager) Remove(ctx context.Context, name string) (bpm.RemoveResult, error) {
 	if manager.removeTestFunc != nil {
 		manager.removeTestFunc(manager.DummyManager.T, name)
 	}
-	return nil
+	return bpm.RemoveResult{Name: name, Version: "v1.0.0", Files: []string{"/bin/" + name}}, nil
 }
 
 type testRemoveConfig struct {
@@ -47,6 +48,14 @@ func TestRemove(t *testing.T) {
 				assert.Equal(t, name, "testName")
 			},
 		},
+		{
+			testConfig: testConfig{
+				name:     "dry-run",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"--dry-run", "remove", "testName"},
+				testFunc: testOutputContains("remove /bin/testName\n"),
+			},
+		},
 	}
 	for _, testConfig := range tests {
 		testConfig.testConfig.manager = &dummyRemoveManager{
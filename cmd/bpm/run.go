@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"reflect"
+	"syscall"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/rs/zerolog"
@@ -18,39 +24,75 @@ type opts struct {
 	LogLevel string `short:"l" long:"loglevel" description:"loglevel to set"`
 	Config   string `short:"c" long:"config" description:"path to config"`
 	Quiet    bool   `short:"q" long:"quiet" description:"do not output on stdout"`
+	DryRun   bool   `short:"n" long:"dry-run" description:"show planned changes of install, update and remove without changing anything"`
+	Output   string `short:"o" long:"output" description:"output format of results" choice:"table" choice:"json" choice:"yaml"`
 }
 
 type SubCommand interface {
 	AddCommand(parser *flags.Parser) error
-	Run(logger zerolog.Logger, manager bpm.Manager) error
+	Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error
+}
+
+// ManagerFunc creates the manager when a command needs it.
+type ManagerFunc func() (bpm.Manager, error)
+
+// LazyManagerSubCommand is implemented by commands which create the manager only if they need it.
+// The state is not saved after them.
+type LazyManagerSubCommand interface {
+	RunLazy(ctx context.Context, logger zerolog.Logger, newManager ManagerFunc) error
 }
 
 var (
 	subCommands = map[string]SubCommand{}
+	// commands which run with broken package files
+	toleratesPackageFiles = map[string]bool{"verify": true, "lint": true, "__complete": true, "self-update": true}
+	// commands which run on every prompt or call of a shim and need neither providers nor package files
+	stateOnlyCommands = map[string]bool{"shim-exec": true, "env": true}
+	// commands which only read the state, it is not saved after them because other processes may change it meanwhile
+	readOnlyCommands = map[string]bool{"shim-exec": true, "env": true, "exec": true}
 )
 
 const (
 	EXIT_SUCCESS      = 0
 	EXIT_ERROR        = 1
 	EXIT_CONFIG_ERROR = 2
+	// some packages failed, others were updated
+	EXIT_PARTIAL_ERROR = 3
+	// outdated --exit-code found updates
+	EXIT_OUTDATED = 4
 )
 
-func run(managerCreateFunc bpm.ManagerCreateFunc, parserOut io.Writer, loggerOut io.Writer, args []string) int {
+// newParser returns the parser of the options with new instances of all sub commands.
+// The instances are returned by the name of the command, a parse does not change subCommands.
+func newParser(opts *opts) (*flags.Parser, map[string]SubCommand, error) {
+	parser := flags.NewParser(opts, flags.PassDoubleDash|flags.HelpFlag)
+	commands := make(map[string]SubCommand, len(subCommands))
+	for name, registered := range subCommands {
+		cmd := reflect.New(reflect.TypeOf(registered).Elem()).Interface().(SubCommand)
+		err := cmd.AddCommand(parser)
+		if err != nil {
+			return nil, nil, err
+		}
+		commands[name] = cmd
+	}
+	return parser, commands, nil
+}
+
+func run(managerCreateFunc bpm.ManagerCreateFunc, stdout io.Writer, parserOut io.Writer, loggerOut io.Writer, args []string) int {
 	opts := opts{
 		LogLevel: "warn",
 		Config:   "",
 		Quiet:    false,
+		DryRun:   false,
+		Output:   OUTPUT_TABLE,
 	}
 	logger := zerolog.New(loggerOut).With().Timestamp().Logger()
-	parser := flags.NewParser(&opts, flags.PassDoubleDash|flags.HelpFlag)
-	for _, cmd := range subCommands {
-		err := cmd.AddCommand(parser)
-		if err != nil {
-			logger.Err(err).Msg("")
-			return EXIT_CONFIG_ERROR
-		}
+	parser, commands, err := newParser(&opts)
+	if err != nil {
+		logger.Err(err).Msg("")
+		return EXIT_CONFIG_ERROR
 	}
-	_, err := parser.ParseArgs(args)
+	_, err = parser.ParseArgs(args)
 	if err != nil {
 		var flagsErr *flags.Error
 		if errors.As(err, &flagsErr) {
@@ -71,31 +113,95 @@ func run(managerCreateFunc bpm.ManagerCreateFunc, parserOut io.Writer, loggerOut
 	logger = logger.Level(level)
 	logger.Debug().Msg("starting up")
 
-	cmd := subCommands[parser.Active.Name]
-	migrate := false
+	cmd := commands[parser.Active.Name]
+	options := bpm.ManagerOptions{StateOnly: stateOnlyCommands[parser.Active.Name]}
 	if parser.Active.Name == "migrate" {
 		logger.Info().Msgf("migrate active")
-		migrate = true
+		options.Migrate = true
+	}
+	newManager := func() (bpm.Manager, error) {
+		manager, err := managerCreateFunc(opts.Config, logger, options)
+		if err != nil && toleratesPackageFiles[parser.Active.Name] && manager != nil && errors.Is(err, bpm.ErrPackageFiles) {
+			// verify and lint report the broken package files themselves, completion and self-update do not need them
+			logger.Warn().Msg(err.Error())
+		} else if err != nil {
+			return nil, err
+		}
+		manager.Config().Quiet = opts.Quiet
+		manager.Config().DryRun = opts.DryRun
+		return manager, nil
 	}
-	manager, err := managerCreateFunc(opts.Config, logger, migrate)
+
+	if outputCmd, ok := cmd.(OutputSubCommand); ok {
+		outputCmd.SetOutput(&Output{
+			Format: opts.Output,
+			Writer: stdout,
+		})
+	}
+
+	// cancel running operations on ctrl-c or termination
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if lazyCmd, ok := cmd.(LazyManagerSubCommand); ok {
+		logger.Debug().Msgf("execute command %s", parser.Active.Name)
+		err = lazyCmd.RunLazy(ctx, logger, newManager)
+		if err != nil {
+			logger.Err(err).Msg("")
+			return EXIT_ERROR
+		}
+		return EXIT_SUCCESS
+	}
+
+	manager, err := newManager()
 	if err != nil {
 		logger.Err(err).Msg("cannot create manager instance")
 		return EXIT_CONFIG_ERROR
 	}
-	manager.Config().Quiet = opts.Quiet
 
 	logger.Debug().Msgf("execute command %s", parser.Active.Name)
-	err = cmd.Run(logger, manager)
-	if err != nil {
+	err = cmd.Run(ctx, logger, manager)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// exec returns the exit code of the command (-1 if it was killed by a signal)
+		if exitErr.ExitCode() < 0 {
+			return EXIT_ERROR
+		}
+		return exitErr.ExitCode()
+	}
+	if err != nil && !keepState(err) {
 		logger.Err(err).Msg("")
 		return EXIT_ERROR
 	}
 
-	err = manager.SaveState()
-	if err != nil {
-		logger.Err(err).Msg("cannot save state")
+	// the state is also saved after cancellation or failed updates to keep the already installed packages
+	if !readOnlyCommands[parser.Active.Name] {
+		saveErr := manager.SaveState()
+		if saveErr != nil {
+			logger.Err(saveErr).Msg("cannot save state")
+			return EXIT_ERROR
+		}
+	}
+	switch {
+	case err == nil:
+		return EXIT_SUCCESS
+	case errors.Is(err, ErrUpdatesAvailable):
+		logger.Info().Msg(err.Error())
+		return EXIT_OUTDATED
+	case errors.Is(err, context.Canceled):
+		logger.Err(err).Msg("interrupted")
 		return EXIT_ERROR
 	}
+	logger.Err(err).Msg("")
+	var updateErr *bpm.UpdateError
+	if errors.As(err, &updateErr) && updateErr.Partial() {
+		return EXIT_PARTIAL_ERROR
+	}
+	return EXIT_ERROR
+}
 
-	return EXIT_SUCCESS
+// keepState reports if the state has to be saved although the command returned err.
+func keepState(err error) bool {
+	var updateErr *bpm.UpdateError
+	return errors.Is(err, context.Canceled) || errors.Is(err, ErrUpdatesAvailable) || errors.As(err, &updateErr)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 	"bytes"
 	"fmt"
@@ -50,6 +51,15 @@ func (manager *dummyRunErrorManager) SaveState() error {
 	return nil
 }
 
+type dummyCancelledManager struct {
+	*bpm.DummyManager
+}
+
+func (manager *dummyCancelledManager) Init() error {
+	manager.DummyManager.Init()
+	return context.Canceled
+}
+
 func emptyTestFunc(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool { return true }
 
 func testOutputContains(contains string) testFunc {
@@ -64,13 +74,13 @@ func runTest(t *testing.T, testConfig *testConfig) {
 			testConfig.manager = &bpm.DummyManager{}
 		}
 		if testConfig.managerCreateFunc == nil {
-			testConfig.managerCreateFunc = func(configPath string, logger zerolog.Logger, migrate bool) (bpm.Manager, error) {
+			testConfig.managerCreateFunc = func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
 				return testConfig.manager, nil
 			}
 		}
 		testConfig.manager.SetT(t)
 		var buf bytes.Buffer
-		exitCode := run(testConfig.managerCreateFunc, &buf, &buf, testConfig.args)
+		exitCode := run(testConfig.managerCreateFunc, &buf, &buf, &buf, testConfig.args)
 		if assert.Equal(t, testConfig.exitCode, exitCode, testConfig.message, &buf) {
 			testConfig.testFunc(t, testConfig.manager, &buf)
 		}
@@ -130,13 +140,26 @@ func TestMain(t *testing.T) {
 				failSaveState: true,
 			},
 		},
+		{
+			name:     "cancelled",
+			exitCode: EXIT_ERROR,
+			message:  "exit error should be EXIT_ERROR because the command was interrupted",
+			args:     []string{"init"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				realManager := manager.(*dummyCancelledManager)
+				return assert.Equal(t, 1, realManager.GetCounter("SaveState"), "the state must be saved after an interruption")
+			},
+			manager: &dummyCancelledManager{
+				DummyManager: &bpm.DummyManager{},
+			},
+		},
 		{
 			name:     "manager creation failed",
 			exitCode: EXIT_CONFIG_ERROR,
 			message:  "EXIT_CONFIG_ERROR should be returned because the manager cannot be created",
 			args:     []string{"init"},
 			testFunc: emptyTestFunc,
-			managerCreateFunc: func(configPath string, logger zerolog.Logger, migrate bool) (bpm.Manager, error) {
+			managerCreateFunc: func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
 				return nil, fmt.Errorf("manager creation failed")
 			},
 		},
@@ -170,3 +193,21 @@ func TestMain(t *testing.T) {
 	}
 	runTest(t, &testConfig)
 }
+
+func TestNewParserFreshCommands(t *testing.T) {
+	parser, commands, err := newParser(&opts{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = parser.ParseArgs([]string{"outdated", "--exit-code"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, commands["outdated"].(*OutdatedSubCommand).Opts.ExitCode)
+	assert.False(t, subCommands["outdated"].(*OutdatedSubCommand).Opts.ExitCode, "a parse must not change the registered command")
+
+	_, commands, err = newParser(&opts{})
+	if assert.NoError(t, err) {
+		assert.False(t, commands["outdated"].(*OutdatedSubCommand).Opts.ExitCode, "flags of a previous parse must not leak")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+const (
+	SCHEMA_PACKAGE = "package"
+	SCHEMA_CONFIG  = "config"
+)
+
+type SchemaSubCommand struct {
+	outputCommand
+	Opts SchemaSubCommandOpts
+}
+type SchemaSubCommandOpts struct {
+	Args struct {
+		Kind string `description:"package or config"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func init() {
+	subCommands["schema"] = &SchemaSubCommand{}
+}
+
+func (cmd *SchemaSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("schema", "print the JSON schema of package or config files",
+		"prints the JSON schema of package or config files. "+
+			"Editors can use it to validate and complete the yaml files", &cmd.Opts)
+	return err
+}
+
+func (cmd *SchemaSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	var schema map[string]any
+	switch cmd.Opts.Args.Kind {
+	case SCHEMA_PACKAGE:
+		schema = bpm.PackageSchema()
+	case SCHEMA_CONFIG:
+		schema = bpm.ConfigSchema()
+	default:
+		return fmt.Errorf("%w: unknown schema %s (use %s or %s)", bpm.ErrInvalidInput, cmd.Opts.Args.Kind, SCHEMA_PACKAGE, SCHEMA_CONFIG)
+	}
+	// the schema is json also in table output
+	return cmd.output.Render(schema, func(writer io.Writer) {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(schema)
+	})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	cmd := "schema"
+	tests := []testConfig{
+		{
+			name:     "package",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "package"},
+			testFunc: testOutputContains(`"title": "bpm package"`),
+		},
+		{
+			name:     "config",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "config"},
+			testFunc: testOutputContains(`"title": "bpm config"`),
+		},
+		{
+			name:     "config-json",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"--output", "json", cmd, "config"},
+			testFunc: testOutputContains(`"bin_folder": {`),
+		},
+		{
+			name:     "unknown",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd, "state"},
+			testFunc: emptyTestFunc,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
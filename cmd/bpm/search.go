@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type SearchSubCommand struct {
+	outputCommand
+	Opts SearchSubCommandOpts
+}
+type SearchSubCommandOpts struct {
+	Remote bool `long:"remote" short:"r" description:"also search repositories of the providers (e.g. github) with assets for this platform"`
+	Args   struct {
+		Term string
+	} `positional-args:"yes" required:"yes"`
+}
+
+func init() {
+	subCommands["search"] = &SearchSubCommand{}
+}
+
+func (cmd *SearchSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("search", "search packages",
+		"search names and descriptions of local and registry packages. "+
+			"Remote results can be added with `add <name> <url>`", &cmd.Opts)
+	return err
+}
+
+func (cmd *SearchSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	results, err := manager.Search(ctx, cmd.Opts.Args.Term, cmd.Opts.Remote)
+	renderErr := cmd.output.Render(results, func(writer io.Writer) {
+		fmt.Fprintf(writer, "NAME\tSOURCE\tURL\tDESCRIPTION\n")
+		for _, result := range results {
+			name := result.Name
+			if result.Installed {
+				name += " (installed)"
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", name, result.Source, result.URL, result.Description)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
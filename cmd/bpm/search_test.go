@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dummySearchManager struct {
+	*bpm.DummyManager
+	term   string
+	remote bool
+}
+
+func (manager *dummySearchManager) Search(ctx context.Context, term string, remote bool) ([]bpm.SearchResult, error) {
+	manager.term = term
+	manager.remote = remote
+	results := []bpm.SearchResult{
+		{Name: "ripgrep", Description: "recursive grep", Source: "local", URL: "github.com/BurntSushi/ripgrep", Installed: true},
+	}
+	if remote {
+		results = append(results, bpm.SearchResult{Name: "ugrep", Description: "fast grep", Source: "github.com", URL: "github.com/Genivia/ugrep", Asset: "ugrep-linux-x86_64"})
+	}
+	return results, nil
+}
+
+func TestSearch(t *testing.T) {
+	cmd := "search"
+	tests := []testConfig{
+		{
+			name:     "missing-term",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{cmd},
+			testFunc: emptyTestFunc,
+		},
+		{
+			name:     "local",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "grep"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				assert.Equal(t, "grep", manager.(*dummySearchManager).term)
+				assert.False(t, manager.(*dummySearchManager).remote)
+				return assert.Contains(t, buf.String(), "NAME                 SOURCE  URL                            DESCRIPTION\n"+
+					"ripgrep (installed)  local   github.com/BurntSushi/ripgrep  recursive grep\n")
+			},
+		},
+		{
+			name:     "remote",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{"-o", "json", cmd, "--remote", "grep"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				assert.True(t, manager.(*dummySearchManager).remote)
+				return assert.Contains(t, buf.String(), `"asset": "ugrep-linux-x86_64"`)
+			},
+		},
+	}
+	for _, testConfig := range tests {
+		testConfig.manager = &dummySearchManager{DummyManager: &bpm.DummyManager{}}
+		runTest(t, &testConfig)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type SelfUpdateSubCommand struct {
+	outputCommand
+	Opts SelfUpdateSubCommandOpts
+}
+type SelfUpdateSubCommandOpts struct {
+	Force bool `long:"force" short:"f" description:"reinstall the latest release also if it is installed or bpm is a development build"`
+}
+
+func init() {
+	subCommands["self-update"] = &SelfUpdateSubCommand{}
+}
+
+func (cmd *SelfUpdateSubCommand) AddCommand(parser *flags.Parser) error {
+	_, err := parser.AddCommand("self-update", "update bpm itself",
+		"replaces the bpm binary with the latest release for this platform. The download is verified against the checksums of the release", &cmd.Opts)
+	return err
+}
+
+func (cmd *SelfUpdateSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	// replace the binary and not a symlink to it
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return err
+	}
+	result, err := manager.SelfUpdate(ctx, build, executable, cmd.Opts.Force)
+	if err != nil {
+		return err
+	}
+	return cmd.output.RenderResults(manager, []bpm.InstallResult{result})
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummySelfUpdateManager struct {
+	*bpm.DummyManager
+	executable string
+	force      bool
+	err        error
+}
+
+func (manager *dummySelfUpdateManager) SelfUpdate(ctx context.Context, current string, executable string, force bool) (bpm.InstallResult, error) {
+	manager.executable = executable
+	manager.force = force
+	return bpm.InstallResult{Name: "bpm", PreviousVersion: current, Version: "v1.2.0", Changed: true, Target: executable}, manager.err
+}
+
+func TestSelfUpdate(t *testing.T) {
+	cmd := "self-update"
+	tests := []testConfig{
+		{
+			name:     "update",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				selfUpdateManager := manager.(*dummySelfUpdateManager)
+				return assert.Contains(t, buf.String(), "bpm  "+build+"  =>  v1.2.0\n") &&
+					assert.True(t, filepath.IsAbs(selfUpdateManager.executable), "the path of the running binary is replaced") &&
+					assert.False(t, selfUpdateManager.force)
+			},
+			manager: &dummySelfUpdateManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "force",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "--force"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.True(t, manager.(*dummySelfUpdateManager).force)
+			},
+			manager: &dummySelfUpdateManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "error",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd},
+			testFunc: emptyTestFunc,
+			manager:  &dummySelfUpdateManager{DummyManager: &bpm.DummyManager{}, err: fmt.Errorf("%w: checksum mismatch", bpm.ErrSelfUpdate)},
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type ShimSubCommand struct {
+	outputCommand
+	command *flags.Command
+	List    struct{}           `command:"list" description:"list the shimmed packages"`
+	Add     ShimSubCommandOpts `command:"add" description:"replace the binaries of packages with shims"`
+	Remove  ShimSubCommandOpts `command:"remove" description:"replace the shims with the global version of the packages"`
+}
+type ShimSubCommandOpts struct {
+	Args struct {
+		Packages []string `required:"1"`
+	} `positional-args:"true" required:"true"`
+}
+
+type ShimExecSubCommand struct {
+	Opts ShimExecSubCommandOpts
+}
+type ShimExecSubCommandOpts struct {
+	Args struct {
+		Name string
+		Args []string
+	} `positional-args:"true" required:"true"`
+}
+
+var (
+	defaultExecFunc = syscall.Exec
+	// execFunc replaces the process with the binary, it is replaced in tests.
+	execFunc = defaultExecFunc
+)
+
+func init() {
+	subCommands["shim"] = &ShimSubCommand{}
+	subCommands["shim-exec"] = &ShimExecSubCommand{}
+}
+
+func (cmd *ShimSubCommand) AddCommand(parser *flags.Parser) error {
+	command, err := parser.AddCommand("shim", "manage shims",
+		"shims in the bin folder run the version of the package set with "+bpm.ShimVersionEnv("<name>")+
+			", in the project manifest ("+bpm.ProjectFileName+") of the working directory or the global version", cmd)
+	if err != nil {
+		return err
+	}
+	cmd.command = command
+	return nil
+}
+
+func (cmd *ShimSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	var shims []bpm.ShimStatus
+	var err error
+	switch cmd.command.Active.Name {
+	case "add":
+		shims, err = manager.AddShims(ctx, cmd.Add.Args.Packages)
+	case "remove":
+		shims, err = manager.RemoveShims(ctx, cmd.Remove.Args.Packages)
+	default:
+		shims, err = manager.Shims(ctx)
+	}
+	renderErr := cmd.output.Render(shims, func(writer io.Writer) {
+		fmt.Fprintf(writer, "NAME\tGLOBAL VERSION\tSHIM\n")
+		for _, shim := range shims {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", shim.Name, installedVersion(shim.Version, shim.Version != ""), shim.Shim)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
+
+func (cmd *ShimExecSubCommand) AddCommand(parser *flags.Parser) error {
+	command, err := parser.AddCommand("shim-exec", "run the selected version of a package",
+		"used by shims to run the version of the package selected for the working directory", &cmd.Opts)
+	if err != nil {
+		return err
+	}
+	command.Hidden = true
+	return nil
+}
+
+func (cmd *ShimExecSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	shim, err := manager.ResolveShim(ctx, cmd.Opts.Args.Name, dir)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Msgf("run %s %s (%s)", shim.Name, shim.Version, shim.Source)
+	args := append([]string{shim.Name}, cmd.Opts.Args.Args...)
+	return execFunc(shim.Path, args, os.Environ())
+}
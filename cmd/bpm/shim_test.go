@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type dummyShimManager struct {
+	*bpm.DummyManager
+	shims []bpm.ShimStatus
+	names []string
+}
+
+func (manager *dummyShimManager) Shims(ctx context.Context) ([]bpm.ShimStatus, error) {
+	return manager.shims, nil
+}
+
+func (manager *dummyShimManager) AddShims(ctx context.Context, names []string) ([]bpm.ShimStatus, error) {
+	manager.names = names
+	return manager.shims, nil
+}
+
+func (manager *dummyShimManager) ResolveShim(ctx context.Context, name string, dir string) (bpm.ShimStatus, error) {
+	if name != "tool" {
+		return bpm.ShimStatus{}, fmt.Errorf("%w: %s", bpm.ErrPackageNotInstalled, name)
+	}
+	return bpm.ShimStatus{Name: name, Version: "v1.0.0", Source: bpm.ShimSourceGlobal, Path: "/store/tool/v1.0.0/tool"}, nil
+}
+
+func TestShim(t *testing.T) {
+	cmd := "shim"
+	shims := []bpm.ShimStatus{
+		{Name: "tool", Version: "v1.0.0", Shim: "/home/user/bin/tool"},
+		{Name: "other", Shim: "/home/user/bin/other"},
+	}
+	tests := []testConfig{
+		{
+			name:     "list",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "list"},
+			testFunc: testOutputContains("NAME   GLOBAL VERSION  SHIM\n" +
+				"tool   v1.0.0          /home/user/bin/tool\n" +
+				"other  not installed   /home/user/bin/other\n"),
+			manager: &dummyShimManager{DummyManager: &bpm.DummyManager{}, shims: shims},
+		},
+		{
+			name:     "add",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "add", "tool", "other"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Equal(t, []string{"tool", "other"}, manager.(*dummyShimManager).names)
+			},
+			manager: &dummyShimManager{DummyManager: &bpm.DummyManager{}, shims: shims},
+		},
+		{
+			name:     "add-without-package",
+			exitCode: EXIT_CONFIG_ERROR,
+			args:     []string{cmd, "add"},
+			testFunc: emptyTestFunc,
+		},
+		{
+			name:     "remove",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "remove", "tool"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Equal(t, 1, manager.(*bpm.DummyManager).GetCounter("RemoveShims"))
+			},
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
+
+func TestShimExec(t *testing.T) {
+	cmd := "shim-exec"
+	var execArgs []string
+	var execPath string
+	execFunc = func(path string, args []string, env []string) error {
+		execPath = path
+		execArgs = args
+		return nil
+	}
+	t.Cleanup(func() {
+		execFunc = defaultExecFunc
+	})
+	var managerOptions bpm.ManagerOptions
+	stateOnly := func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
+		managerOptions = options
+		return &dummyShimManager{DummyManager: &bpm.DummyManager{}}, nil
+	}
+	tests := []testConfig{
+		{
+			name:     "exec",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "tool", "--", "--version", "-v"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.Equal(t, "/store/tool/v1.0.0/tool", execPath) &&
+					assert.Equal(t, []string{"tool", "--version", "-v"}, execArgs)
+			},
+			manager: &dummyShimManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "not-installed",
+			exitCode: EXIT_ERROR,
+			args:     []string{cmd, "missing", "--"},
+			testFunc: emptyTestFunc,
+			manager:  &dummyShimManager{DummyManager: &bpm.DummyManager{}},
+		},
+		{
+			name:     "state-only",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd, "tool", "--"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				return assert.True(t, managerOptions.StateOnly, "shim-exec loads neither providers nor package files")
+			},
+			managerCreateFunc: stateOnly,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/jduepmeier/binary-package-manager"
 
 	"github.com/jessevdk/go-flags"
@@ -8,10 +9,12 @@ import (
 )
 
 type UpdateSubCommand struct {
+	outputCommand
 	Opts UpdateSubCommandOpts
 }
 type UpdateSubCommandOpts struct {
-	Args struct {
+	ShowNotes bool `long:"show-notes" description:"show the release notes of the updated packages"`
+	Args      struct {
 		Packages []string
 	} `positional-args:"true"`
 }
@@ -25,6 +28,31 @@ func (cmd *UpdateSubCommand) AddCommand(parser *flags.Parser) error {
 	return err
 }
 
-func (cmd *UpdateSubCommand) Run(logger zerolog.Logger, manager bpm.Manager) error {
-	return manager.Update(cmd.Opts.Args.Packages)
+func (cmd *UpdateSubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	results, err := manager.Update(ctx, cmd.Opts.Args.Packages)
+	if cmd.Opts.ShowNotes {
+		addReleaseNotes(ctx, logger, manager, results)
+	}
+	// render the finished packages also if the update was interrupted
+	renderErr := cmd.output.RenderResults(manager, results)
+	if err != nil {
+		return err
+	}
+	return renderErr
+}
+
+// addReleaseNotes fetches the release notes of all changed packages.
+// Missing notes are only logged because the update itself succeeded.
+func addReleaseNotes(ctx context.Context, logger zerolog.Logger, manager bpm.Manager, results []bpm.InstallResult) {
+	for i, result := range results {
+		if !result.Changed || result.PreviousVersion == "" {
+			continue
+		}
+		notes, err := manager.Changelog(ctx, result.Name, result.PreviousVersion, result.Version)
+		if err != nil {
+			logger.Warn().Str("pkg", result.Name).Msgf("cannot get release notes: %s", err)
+			continue
+		}
+		results[i].Notes = notes
+	}
 }
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"github.com/jduepmeier/binary-package-manager"
 	"testing"
 
@@ -14,11 +17,15 @@ type dummyUpdateManager struct {
 
 type testUpdateFunc func(t *testing.T, packages []string)
 
-func (manager *dummyUpdateManager) Update(packages []string) error {
+func (manager *dummyUpdateManager) Update(ctx context.Context, packages []string) ([]bpm.InstallResult, error) {
 	if manager.updateTestFunc != nil {
 		manager.updateTestFunc(manager.DummyManager.T, packages)
 	}
-	return nil
+	results := []bpm.InstallResult{}
+	for _, name := range packages {
+		results = append(results, bpm.InstallResult{Name: name, PreviousVersion: "v1.0.0", Version: "v1.1.0", Changed: true})
+	}
+	return results, nil
 }
 
 type testUpdateConfig struct {
@@ -45,12 +52,30 @@ func TestUpdate(t *testing.T) {
 				name:     "success",
 				exitCode: EXIT_SUCCESS,
 				args:     []string{cmd, "testName"},
-				testFunc: emptyTestFunc,
+				testFunc: testOutputContains("testName  v1.0.0  =>  v1.1.0\n"),
 			},
 			updateTestFunc: func(t *testing.T, packages []string) {
 				assert.ElementsMatch(t, packages, []string{"testName"}, "packages should contain the names given on command line")
 			},
 		},
+		{
+			testConfig: testConfig{
+				name:     "quiet",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"--quiet", cmd, "testName"},
+				testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+					return assert.Empty(t, buf.String(), "quiet must not print updated packages")
+				},
+			},
+		},
+		{
+			testConfig: testConfig{
+				name:     "json",
+				exitCode: EXIT_SUCCESS,
+				args:     []string{"--quiet", "-o", "json", cmd, "testName"},
+				testFunc: testOutputContains(`"previous_version": "v1.0.0"`),
+			},
+		},
 	}
 	for _, testConfig := range tests {
 		testConfig.testConfig.manager = &dummyUpdateManager{
@@ -60,3 +85,49 @@ func TestUpdate(t *testing.T) {
 		runTest(t, &testConfig.testConfig)
 	}
 }
+
+type dummyFailingUpdateManager struct {
+	*bpm.DummyManager
+	succeeded int
+}
+
+func (manager *dummyFailingUpdateManager) Update(ctx context.Context, packages []string) ([]bpm.InstallResult, error) {
+	manager.DummyManager.Update(ctx, packages)
+	results := []bpm.InstallResult{
+		{Name: "broken", PreviousVersion: "v1.0.0", Version: "v1.0.0", Error: "download failed"},
+	}
+	updateErr := &bpm.UpdateError{
+		Failed:    []*bpm.PackageError{{Name: "broken", Err: errors.New("download failed")}},
+		Succeeded: manager.succeeded,
+		Changed:   manager.succeeded,
+	}
+	if manager.succeeded > 0 {
+		results = append(results, bpm.InstallResult{Name: "working", PreviousVersion: "v1.0.0", Version: "v1.1.0", Changed: true})
+	}
+	return results, updateErr
+}
+
+func TestUpdateFailures(t *testing.T) {
+	tests := []testConfig{
+		{
+			name:     "partial",
+			exitCode: EXIT_PARTIAL_ERROR,
+			args:     []string{"update"},
+			testFunc: func(t *testing.T, manager bpm.TestManager, buf *bytes.Buffer) bool {
+				assert.Contains(t, buf.String(), "broken   v1.0.0  failed:  download failed\nworking  v1.0.0  =>       v1.1.0\n1 of 2 packages failed\n")
+				return assert.Equal(t, 1, manager.(*dummyFailingUpdateManager).GetCounter("SaveState"), "the state must be saved after a partial update")
+			},
+			manager: &dummyFailingUpdateManager{DummyManager: &bpm.DummyManager{}, succeeded: 1},
+		},
+		{
+			name:     "all-failed",
+			exitCode: EXIT_ERROR,
+			args:     []string{"update"},
+			testFunc: testOutputContains("1 of 1 packages failed\n"),
+			manager:  &dummyFailingUpdateManager{DummyManager: &bpm.DummyManager{}},
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/rs/zerolog"
+)
+
+type VerifySubCommand struct {
+	outputCommand
+}
+
+// ErrVerifyFailed is returned if verify found errors.
+var ErrVerifyFailed = errors.New("verify found problems")
+
+func init() {
+	subCommands["verify"] = &VerifySubCommand{}
+}
+
+func (cmd *VerifySubCommand) AddCommand(parser *flags.Parser) error {
+	command, err := parser.AddCommand("verify", "check the installation",
+		"checks installed binaries against the recorded checksums, the PATH for the bin folder and "+
+			"shadowed binaries, the package files and the state for orphan entries", cmd)
+	if err != nil {
+		return err
+	}
+	command.Aliases = []string{"doctor"}
+	return nil
+}
+
+func (cmd *VerifySubCommand) Run(ctx context.Context, logger zerolog.Logger, manager bpm.Manager) error {
+	issues, err := manager.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, issue := range issues {
+		if issue.Severity == bpm.VerifySeverityError {
+			failed++
+		}
+	}
+	err = cmd.output.Render(issues, func(writer io.Writer) {
+		if len(issues) == 0 {
+			fmt.Fprintln(writer, "no problems found")
+			return
+		}
+		fmt.Fprintf(writer, "SEVERITY\tCHECK\tPACKAGE\tPATH\tMESSAGE\n")
+		for _, issue := range issues {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", issue.Severity, issue.Check,
+				valueOrDash(issue.Package), valueOrDash(issue.Path), issue.Message)
+		}
+	})
+	if err == nil && failed > 0 {
+		return fmt.Errorf("%w: %d errors", ErrVerifyFailed, failed)
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jduepmeier/binary-package-manager"
+
+	"github.com/rs/zerolog"
+)
+
+type dummyVerifyManager struct {
+	*bpm.DummyManager
+	issues []bpm.VerifyIssue
+}
+
+func (manager *dummyVerifyManager) Verify(ctx context.Context) ([]bpm.VerifyIssue, error) {
+	return manager.issues, nil
+}
+
+func TestVerify(t *testing.T) {
+	cmd := "verify"
+	warning := bpm.VerifyIssue{
+		Check:    bpm.VerifyCheckPath,
+		Severity: bpm.VerifySeverityWarning,
+		Path:     "/home/user/bin",
+		Message:  "bin folder is not in PATH",
+	}
+	failure := bpm.VerifyIssue{
+		Check:    bpm.VerifyCheckChecksum,
+		Severity: bpm.VerifySeverityError,
+		Package:  "tool",
+		Path:     "/home/user/bin/tool",
+		Message:  "binary was modified after installation",
+	}
+	brokenPackages := func(configPath string, logger zerolog.Logger, options bpm.ManagerOptions) (bpm.Manager, error) {
+		return &bpm.DummyManager{}, fmt.Errorf("%w: %w: broken.yaml", bpm.ErrManagerCreate, bpm.ErrPackageFiles)
+	}
+	tests := []testConfig{
+		{
+			name:     "no-problems",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: testOutputContains("no problems found\n"),
+		},
+		{
+			name:     "warning",
+			exitCode: EXIT_SUCCESS,
+			args:     []string{cmd},
+			testFunc: testOutputContains("warning   path   -        /home/user/bin  bin folder is not in PATH\n"),
+			manager:  &dummyVerifyManager{DummyManager: &bpm.DummyManager{}, issues: []bpm.VerifyIssue{warning}},
+		},
+		{
+			name:     "error",
+			exitCode: EXIT_ERROR,
+			args:     []string{"doctor"},
+			testFunc: testOutputContains("error     checksum  tool     /home/user/bin/tool  binary was modified after installation\n"),
+			manager:  &dummyVerifyManager{DummyManager: &bpm.DummyManager{}, issues: []bpm.VerifyIssue{warning, failure}},
+		},
+		{
+			name:              "broken-package-files",
+			exitCode:          EXIT_SUCCESS,
+			args:              []string{cmd},
+			testFunc:          testOutputContains("no problems found\n"),
+			managerCreateFunc: brokenPackages,
+		},
+		{
+			name:              "broken-package-files-other-command",
+			exitCode:          EXIT_CONFIG_ERROR,
+			args:              []string{"list"},
+			testFunc:          emptyTestFunc,
+			managerCreateFunc: brokenPackages,
+		},
+	}
+	for _, testConfig := range tests {
+		runTest(t, &testConfig)
+	}
+}
@@ -17,11 +17,29 @@ var (
 )
 
 type Config struct {
-	BinFolder      string       `yaml:"bin_folder"`
-	StateFolder    string       `yaml:"state_folder"`
-	PackagesFolder string       `yaml:"packages_folder"`
-	Quiet          bool         `yaml:"quiet"`
-	Github         GithubConfig `yaml:"github"`
+	BinFolder      string `yaml:"bin_folder"`
+	StateFolder    string `yaml:"state_folder"`
+	PackagesFolder string `yaml:"packages_folder"`
+	DataFolder     string `yaml:"data_folder"`
+	// StoreFolder contains the versions of project manifests (<store_folder>/<name>/<version>/<name>)
+	StoreFolder string        `yaml:"store_folder"`
+	Quiet       bool          `yaml:"quiet"`
+	Github      GithubConfig  `yaml:"github"`
+	Extract     ExtractLimits `yaml:"extract"`
+	// MinReleaseAge in days, younger releases are not installed
+	MinReleaseAge int `yaml:"min_release_age"`
+	// Registries are remote sources of package files, local package files take precedence
+	Registries []RegistryConfig `yaml:"registries,omitempty"`
+	// Templates are partial package definitions packages can extend
+	Templates map[string]yaml.Node `yaml:"templates,omitempty"`
+	// ManFolder is added to MANPATH by env
+	ManFolder string `yaml:"man_folder"`
+	// CompletionsFolder contains shell completions in bash, zsh and fish subfolders, which are loaded by env
+	CompletionsFolder string `yaml:"completions_folder"`
+	// Path is the absolute path of the config file given on the command line (used for shims and hooks).
+	Path string `yaml:"-"`
+	// DryRun only resolves the planned actions without changing files or the state.
+	DryRun bool `yaml:"-"`
 }
 
 func ReadConfig(path string) (*Config, error) {
@@ -34,6 +52,10 @@ func ReadConfig(path string) (*Config, error) {
 		if err != nil {
 			return config, fmt.Errorf("%w: %s", ErrConfigLoad, err)
 		}
+		config.Path, err = filepath.Abs(path)
+		if err != nil {
+			return config, fmt.Errorf("%w: %s", ErrConfigLoad, err)
+		}
 	} else {
 		for _, path := range DefaultConfigPaths {
 			err := loadYaml(expandPath(path), &config)
@@ -49,10 +71,28 @@ func ReadConfig(path string) (*Config, error) {
 		config.PackagesFolder = filepath.Join(config.StateFolder, "packages")
 	}
 	config.PackagesFolder = expandPath(config.PackagesFolder)
+	if config.DataFolder == "" {
+		config.DataFolder = defaultDataFolder()
+	}
+	config.DataFolder = expandPath(config.DataFolder)
+	if config.StoreFolder == "" {
+		config.StoreFolder = filepath.Join(config.StateFolder, "store")
+	}
+	config.StoreFolder = expandPath(config.StoreFolder)
+	config.ManFolder = expandPath(config.ManFolder)
+	config.CompletionsFolder = expandPath(config.CompletionsFolder)
 
 	return config, nil
 }
 
+// defaultDataFolder returns the XDG data home used for desktop files and icons.
+func defaultDataFolder() string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return dataHome
+	}
+	return "$HOME/.local/share"
+}
+
 func loadYaml(path string, obj interface{}) error {
 	file, err := os.Open(path)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,15 +20,21 @@ func getTestConfig(expand bool) *Config {
 	stateFolder := "$HOME/.config/bpm"
 	binFolder := "$HOME/bin"
 	packagesFolder := ""
+	dataFolder := ""
+	storeFolder := ""
 	if expand {
 		stateFolder = os.ExpandEnv(stateFolder)
 		binFolder = os.ExpandEnv(binFolder)
 		packagesFolder = path.Join(stateFolder, "packages")
+		dataFolder = os.ExpandEnv(defaultDataFolder())
+		storeFolder = path.Join(stateFolder, "store")
 	}
 	return &Config{
 		BinFolder:      binFolder,
 		StateFolder:    stateFolder,
 		PackagesFolder: packagesFolder,
+		DataFolder:     dataFolder,
+		StoreFolder:    storeFolder,
 	}
 }
 
@@ -37,6 +44,8 @@ func getTestTmpDirConfig(t *testing.T) *Config {
 		BinFolder:      path.Join(tmpDir, "bin"),
 		StateFolder:    path.Join(tmpDir, "state"),
 		PackagesFolder: path.Join(tmpDir, "packages"),
+		DataFolder:     path.Join(tmpDir, "data"),
+		StoreFolder:    path.Join(tmpDir, "store"),
 	}
 }
 
@@ -71,6 +80,7 @@ func TestConfigLoad(t *testing.T) {
 				config.BinFolder = os.ExpandEnv("$HOME/bin")
 				config.StateFolder = os.ExpandEnv("$HOME/state")
 				config.PackagesFolder = os.ExpandEnv("$HOME/packages")
+				config.StoreFolder = os.ExpandEnv("$HOME/state/store")
 				return config
 			}(),
 		},
@@ -83,6 +93,7 @@ func TestConfigLoad(t *testing.T) {
 			config, err := ReadConfig(basePath)
 			if test.err == nil {
 				assert.NoError(t, err, test.errTestDescription)
+				test.result.Path, _ = filepath.Abs(basePath)
 			} else {
 				assert.ErrorIs(t, err, test.err, test.errTestDescription)
 			}
@@ -0,0 +1,121 @@
+package bpm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	arMagic      = "!<arch>\n"
+	arHeaderSize = 60
+)
+
+// arWalkFunc is called for every member of an ar archive.
+// Returning done stops the walk without an error.
+type arWalkFunc func(name string, content io.Reader) (done bool, err error)
+
+// walkAr reads a (GNU or BSD) ar archive as used by debian packages.
+func walkAr(reader io.Reader, limits ExtractLimits, visit arWalkFunc) error {
+	limits = limits.withDefaults()
+	bufReader := bufio.NewReader(reader)
+	magic := make([]byte, len(arMagic))
+	_, err := io.ReadFull(bufReader, magic)
+	if err != nil || string(magic) != arMagic {
+		return fmt.Errorf("%w: not an ar archive", ErrArchiveFormat)
+	}
+
+	header := make([]byte, arHeaderSize)
+	for members := 0; ; members++ {
+		if members >= limits.MaxEntries {
+			return fmt.Errorf("%w: more than %d entries", ErrArchiveLimit, limits.MaxEntries)
+		}
+		_, err := io.ReadFull(bufReader, header)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: truncated ar header: %s", ErrArchiveFormat, err)
+		}
+		if string(header[58:60]) != "`\n" {
+			return fmt.Errorf("%w: invalid ar header", ErrArchiveFormat)
+		}
+		name := strings.TrimSpace(string(header[0:16]))
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return fmt.Errorf("%w: invalid ar member size", ErrArchiveFormat)
+		}
+		if size > limits.MaxTotalSize {
+			return fmt.Errorf("%w: ar member %q is larger than %d bytes", ErrArchiveLimit, name, limits.MaxTotalSize)
+		}
+		content := io.LimitReader(bufReader, size)
+
+		// BSD ar stores long names in front of the content
+		if strings.HasPrefix(name, "#1/") {
+			nameLength, err := strconv.ParseInt(name[3:], 10, 64)
+			if err != nil || nameLength < 0 || nameLength > size {
+				return fmt.Errorf("%w: invalid ar member name", ErrArchiveFormat)
+			}
+			longName := make([]byte, nameLength)
+			_, err = io.ReadFull(content, longName)
+			if err != nil {
+				return fmt.Errorf("%w: truncated ar member name", ErrArchiveFormat)
+			}
+			name = strings.TrimRight(string(longName), "\x00")
+		}
+		name = strings.TrimSuffix(name, "/")
+
+		done, err := visit(name, content)
+		if err != nil || done {
+			return err
+		}
+		// skip the rest of the member and the padding to an even offset
+		_, err = io.Copy(io.Discard, content)
+		if err != nil {
+			return err
+		}
+		if size%2 == 1 {
+			_, err = bufReader.Discard(1)
+			if err != nil && err != io.EOF {
+				return err
+			}
+		}
+	}
+}
+
+// debWalker walks over the entries of the data.tar.* member of a debian package.
+func debWalker(reader io.Reader, limits ExtractLimits) archiveWalkFunc {
+	return func(visit archiveVisitFunc) error {
+		found := false
+		err := walkAr(reader, limits, func(name string, content io.Reader) (bool, error) {
+			if !strings.HasPrefix(name, "data.tar") {
+				return false, nil
+			}
+			found = true
+			dataReader, err := decompressReader(content)
+			if err != nil {
+				return true, err
+			}
+			return true, tarWalker(dataReader, limits)(visit)
+		})
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: debian package does not contain a data archive", ErrArchiveFormat)
+		}
+		return nil
+	}
+}
+
+func (manager *ManagerImpl) extractDeb(pkg *Package, version string, sourceFile string) (string, error) {
+	file, err := os.Open(sourceFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return manager.extractMatching(pkg, version, debWalker(file, manager.config.Extract))
+}
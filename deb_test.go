@@ -0,0 +1,119 @@
+package bpm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestZstd(t *testing.T, content []byte) []byte {
+	encoder, err := zstd.NewWriter(nil)
+	assert.NoError(t, err)
+	defer encoder.Close()
+	return encoder.EncodeAll(content, nil)
+}
+
+func buildTestAr(members map[string][]byte, order []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(arMagic)
+	for _, name := range order {
+		content := members[name]
+		fmt.Fprintf(&buf, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", name+"/", 0, 0, 0, "100644", len(content))
+		buf.Write(content)
+		if len(content)%2 == 1 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestWalkAr(t *testing.T) {
+	content := buildTestAr(map[string][]byte{
+		"debian-binary": []byte("2.0\n"),
+		"odd":           []byte("odd"),
+		"last":          []byte("last"),
+	}, []string{"debian-binary", "odd", "last"})
+
+	members := map[string]string{}
+	err := walkAr(bytes.NewReader(content), ExtractLimits{}, func(name string, content io.Reader) (bool, error) {
+		data, err := io.ReadAll(content)
+		members[name] = string(data)
+		return false, err
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{
+			"debian-binary": "2.0\n",
+			"odd":           "odd",
+			"last":          "last",
+		}, members)
+	}
+
+	err = walkAr(bytes.NewReader([]byte("no archive")), ExtractLimits{}, nil)
+	assert.ErrorIs(t, err, ErrArchiveFormat)
+
+	err = walkAr(bytes.NewReader(content), ExtractLimits{MaxEntries: 2}, func(name string, content io.Reader) (bool, error) {
+		return false, nil
+	})
+	assert.ErrorIs(t, err, ErrArchiveLimit)
+}
+
+func TestDebWalker(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		err     error
+	}{
+		{
+			name: "plain-data",
+			content: buildTestAr(map[string][]byte{
+				"debian-binary": []byte("2.0\n"),
+				"data.tar":      buildTestTar(t, []testArchiveEntry{{name: "./usr/bin/tool", content: "binary"}}),
+			}, []string{"debian-binary", "data.tar"}),
+		},
+		{
+			name: "missing-data",
+			content: buildTestAr(map[string][]byte{
+				"debian-binary": []byte("2.0\n"),
+			}, []string{"debian-binary"}),
+			err: ErrArchiveFormat,
+		},
+		{
+			name: "zstd-data",
+			content: buildTestAr(map[string][]byte{
+				"data.tar.zst": buildTestZstd(t, buildTestTar(t, []testArchiveEntry{{name: "./usr/bin/tool", content: "binary"}})),
+			}, []string{"data.tar.zst"}),
+		},
+		{
+			name: "broken-zstd-data",
+			content: buildTestAr(map[string][]byte{
+				"data.tar.zst": {0x28, 0xb5, 0x2f, 0xfd, 0x00, 0x00},
+			}, []string{"data.tar.zst"}),
+			err: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "traversal",
+			content: buildTestAr(map[string][]byte{
+				"data.tar": buildTestTar(t, []testArchiveEntry{{name: "../usr/bin/tool", content: "binary"}}),
+			}, []string{"data.tar"}),
+			err: ErrArchiveUnsafePath,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputPath := t.TempDir() + "/output"
+			err := extractMatchingFile(debWalker(bytes.NewReader(test.content), ExtractLimits{}), regexp.MustCompile("(^|/)usr/bin/tool$"), outputPath)
+			if assert.ErrorIs(t, err, test.err) && test.err == nil {
+				content, err := os.ReadFile(outputPath)
+				assert.NoError(t, err)
+				assert.Equal(t, "binary", string(content))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// DownloadURLProvider is implemented by providers that can resolve the asset URL
+// of a version without downloading it. It is used in dry run mode.
+type DownloadURLProvider interface {
+	GetDownloadURL(ctx context.Context, pkg Package, version string) (url string, err error)
+}
+
+// binPath returns the path of the installed binary of the package.
+// The binary of shimmed packages is in the store.
+func (manager *ManagerImpl) binPath(pkg *Package) string {
+	if version, ok := manager.StateFile.Packages[pkg.Name]; ok {
+		return manager.installTarget(pkg, version)
+	}
+	return filepath.Join(manager.config.BinFolder, pkg.Name)
+}
+
+// planInstall fills the result with the actions an install of version would do.
+func (manager *ManagerImpl) planInstall(ctx context.Context, provider PackageProvider, pkg *Package, version string, result InstallResult) (InstallResult, error) {
+	url, err := manager.resolveDownloadURL(ctx, provider, pkg, version)
+	if err != nil {
+		return result, err
+	}
+	result.Version = version
+	result.DownloadURL = url
+	result.Target = manager.installTarget(pkg, version)
+	result.Changed = true
+	return result, nil
+}
+
+// resolveDownloadURL returns the url the package would be downloaded from.
+// For download urls with libc fallbacks the url for the host libc is returned.
+func (manager *ManagerImpl) resolveDownloadURL(ctx context.Context, provider PackageProvider, pkg *Package, version string) (string, error) {
+	if pkg.DownloadURL != "" {
+		return pkg.patternCandidates(pkg.DownloadURL, version)[0], nil
+	}
+	urlProvider, ok := provider.(DownloadURLProvider)
+	if !ok {
+		return "", fmt.Errorf("%w: provider %s cannot resolve download urls", ErrProviderFetch, pkg.Provider)
+	}
+	return urlProvider.GetDownloadURL(ctx, *pkg, version)
+}
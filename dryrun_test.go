@@ -0,0 +1,89 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerInstallDryRun(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.1.0"), dryRun: true})
+	pkg := dummyPackage()
+	result, err := manager.Install(context.Background(), pkg.Name, false)
+	assert.NoError(t, err)
+	binPath := path.Join(manager.config.BinFolder, pkg.Name)
+	assert.Equal(t, InstallResult{
+		Name:        pkg.Name,
+		Version:     "v1.1.0",
+		Changed:     true,
+		DownloadURL: "file://" + getTestPath("files", "dummy-bin.sh"),
+		Target:      binPath,
+	}, result)
+	assert.NoFileExists(t, binPath)
+	assert.Empty(t, manager.StateFile.Packages)
+}
+
+func TestManagerInstallDryRunDownloadURL(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.1.0"), dryRun: true})
+	pkg := dummyPackage()
+	pkg.DownloadURL = "https://example.com/${name}/${version}/${goos}"
+	manager.Packages[pkg.Name] = *pkg
+	result, err := manager.Install(context.Background(), pkg.Name, false)
+	assert.NoError(t, err)
+	assert.Equal(t, pkg.patternExpand(pkg.DownloadURL, "v1.1.0"), result.DownloadURL)
+}
+
+func TestManagerUpdateDryRun(t *testing.T) {
+	pkg := dummyPackage()
+	manager := getTestManager(t, testManagerOptions{
+		provider: dummyBinProvider("v1.1.0"),
+		state:    map[string]string{pkg.Name: "v1.0.0"},
+		dryRun:   true,
+	})
+	results, err := manager.Update(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "v1.0.0", results[0].PreviousVersion)
+		assert.Equal(t, "v1.1.0", results[0].Version)
+		assert.True(t, results[0].Changed)
+	}
+	assert.Equal(t, "v1.0.0", manager.StateFile.Packages[pkg.Name])
+}
+
+func TestManagerRemoveDryRun(t *testing.T) {
+	pkg := dummyPackage()
+	manager := getTestManager(t, testManagerOptions{
+		provider: dummyBinProvider("v1.1.0"),
+		state:    map[string]string{pkg.Name: "v1.0.0"},
+		dryRun:   true,
+	})
+	binPath := path.Join(manager.config.BinFolder, pkg.Name)
+	err := os.WriteFile(binPath, []byte("binary"), 0o755)
+	if err != nil {
+		t.Fatalf("cannot write binary: %s", err)
+	}
+	manager.StateFile.Files = map[string][]string{pkg.Name: {"/tmp/bpm-testName.desktop"}}
+	result, err := manager.Remove(context.Background(), pkg.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, RemoveResult{
+		Name:    pkg.Name,
+		Version: "v1.0.0",
+		Files:   []string{binPath, "/tmp/bpm-testName.desktop"},
+	}, result)
+	assert.FileExists(t, binPath)
+	assert.Contains(t, manager.StateFile.Packages, pkg.Name)
+}
+
+func TestManagerSaveStateDryRun(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{
+		provider: dummyBinProvider("v1.1.0"),
+		state:    map[string]string{dummyPackage().Name: "v1.0.0"},
+		dryRun:   true,
+	})
+	err := manager.SaveState()
+	assert.NoError(t, err)
+	assert.NoFileExists(t, path.Join(manager.config.StateFolder, "state.yaml"))
+}
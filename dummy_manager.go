@@ -1,6 +1,7 @@
 package bpm
 
 import (
+	"context"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -17,7 +18,7 @@ type TestManager interface {
 	SetT(t *testing.T)
 }
 
-func NewDummyManager(configPath string, logger zerolog.Logger, migrate bool) (Manager, error) {
+func NewDummyManager(configPath string, logger zerolog.Logger, options ManagerOptions) (Manager, error) {
 	return &DummyManager{}, nil
 }
 
@@ -70,44 +71,124 @@ func (manager *DummyManager) LoadState() error {
 	return nil
 }
 
-func (manager *DummyManager) Info(name string) error {
+func (manager *DummyManager) Info(ctx context.Context, name string) (PackageInfo, error) {
 	manager.bumpCounter("Info")
-	return nil
+	return PackageInfo{}, nil
 }
 
-func (manager *DummyManager) Remove(name string) error {
+func (manager *DummyManager) Remove(ctx context.Context, name string) (RemoveResult, error) {
 	manager.bumpCounter("Remove")
-	return nil
+	return RemoveResult{Name: name}, nil
 }
 
-func (manager *DummyManager) List() error {
+func (manager *DummyManager) List(ctx context.Context) ([]PackageStatus, error) {
 	manager.bumpCounter("List")
-	return nil
+	return []PackageStatus{}, nil
 }
 
-func (manager *DummyManager) Installed() error {
+func (manager *DummyManager) Installed(ctx context.Context) ([]PackageStatus, error) {
 	manager.bumpCounter("Installed")
-	return nil
+	return []PackageStatus{}, nil
+}
+
+func (manager *DummyManager) AddCandidates(ctx context.Context, name string, url string) (string, []string, error) {
+	manager.bumpCounter("AddCandidates")
+	return "", nil, nil
 }
 
-func (manager *DummyManager) Add(name string, url string) error {
+func (manager *DummyManager) Add(ctx context.Context, name string, url string, version string, asset string) error {
 	manager.bumpCounter("Add")
 	return nil
 }
 
-func (manager *DummyManager) Outdated() error {
+func (manager *DummyManager) Outdated(ctx context.Context) ([]OutdatedEntry, error) {
 	manager.bumpCounter("Outdated")
-	return nil
+	return []OutdatedEntry{}, nil
 }
 
-func (manager *DummyManager) Install(name string, force bool) error {
+func (manager *DummyManager) Install(ctx context.Context, name string, force bool) (InstallResult, error) {
 	manager.bumpCounter("Install")
-	return nil
+	return InstallResult{Name: name}, nil
 }
 
-func (manager *DummyManager) Update(packageNames []string) error {
+func (manager *DummyManager) Update(ctx context.Context, packageNames []string) ([]InstallResult, error) {
 	manager.bumpCounter("Update")
-	return nil
+	return []InstallResult{}, nil
+}
+
+func (manager *DummyManager) Changelog(ctx context.Context, name string, from string, to string) ([]ReleaseNote, error) {
+	manager.bumpCounter("Changelog")
+	return []ReleaseNote{}, nil
+}
+
+func (manager *DummyManager) Adopt(ctx context.Context, packageNames []string) ([]AdoptResult, error) {
+	manager.bumpCounter("Adopt")
+	return []AdoptResult{}, nil
+}
+
+func (manager *DummyManager) Verify(ctx context.Context) ([]VerifyIssue, error) {
+	manager.bumpCounter("Verify")
+	return []VerifyIssue{}, nil
+}
+
+func (manager *DummyManager) Registries(ctx context.Context) ([]RegistryStatus, error) {
+	manager.bumpCounter("Registries")
+	return []RegistryStatus{}, nil
+}
+
+func (manager *DummyManager) UpdateRegistries(ctx context.Context, names []string) ([]RegistryStatus, error) {
+	manager.bumpCounter("UpdateRegistries")
+	return []RegistryStatus{}, nil
+}
+
+func (manager *DummyManager) Search(ctx context.Context, term string, remote bool) ([]SearchResult, error) {
+	manager.bumpCounter("Search")
+	return []SearchResult{}, nil
+}
+
+func (manager *DummyManager) Lint(ctx context.Context, paths []string) ([]LintIssue, error) {
+	manager.bumpCounter("Lint")
+	return []LintIssue{}, nil
+}
+
+func (manager *DummyManager) Project(ctx context.Context, dir string) (ProjectStatus, error) {
+	manager.bumpCounter("Project")
+	return ProjectStatus{Tools: []ProjectTool{}}, nil
+}
+
+func (manager *DummyManager) InstallProject(ctx context.Context, dir string) ([]InstallResult, error) {
+	manager.bumpCounter("InstallProject")
+	return []InstallResult{}, nil
+}
+
+func (manager *DummyManager) Shims(ctx context.Context) ([]ShimStatus, error) {
+	manager.bumpCounter("Shims")
+	return []ShimStatus{}, nil
+}
+
+func (manager *DummyManager) AddShims(ctx context.Context, names []string) ([]ShimStatus, error) {
+	manager.bumpCounter("AddShims")
+	return []ShimStatus{}, nil
+}
+
+func (manager *DummyManager) RemoveShims(ctx context.Context, names []string) ([]ShimStatus, error) {
+	manager.bumpCounter("RemoveShims")
+	return []ShimStatus{}, nil
+}
+
+func (manager *DummyManager) ResolveShim(ctx context.Context, name string, dir string) (ShimStatus, error) {
+	manager.bumpCounter("ResolveShim")
+	return ShimStatus{Name: name}, nil
+}
+
+func (manager *DummyManager) Versions(ctx context.Context, name string) ([]string, error) {
+	manager.bumpCounter("Versions")
+	return []string{}, nil
+}
+
+func (manager *DummyManager) SelfUpdate(ctx context.Context, current string, executable string, force bool) (InstallResult, error) {
+	manager.bumpCounter("SelfUpdate")
+	return InstallResult{Name: "bpm", PreviousVersion: current, Version: current}, nil
 }
 
 func (manager *DummyManager) Migrate() error {
@@ -115,7 +196,7 @@ func (manager *DummyManager) Migrate() error {
 	return nil
 }
 
-func (manager *DummyManager) FetchFromDownloadURL(pkg Package, version string, cacheDir string) (path string, err error) {
+func (manager *DummyManager) FetchFromDownloadURL(ctx context.Context, pkg Package, version string, cacheDir string) (path string, err error) {
 	manager.bumpCounter("FetchFromDownloadURL")
 	return "", nil
 }
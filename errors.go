@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 )
 
 var (
@@ -11,6 +12,9 @@ var (
 	ErrPackageLoadError          = errors.New("cannot load package")
 	ErrPackageNotInstalled       = errors.New("package is not installed")
 	ErrPackageRemove             = errors.New("cannot remove package")
+	ErrPackageInstall            = errors.New("cannot install package")
+	ErrBinaryMismatch            = errors.New("binary does not match this platform")
+	ErrInvalidInput              = errors.New("invalid input")
 	ErrProviderNotFound          = errors.New("package provider not found")
 	ErrProviderConfig            = errors.New("provider config is not valid")
 	ErrProvider                  = errors.New("provider error")
@@ -21,4 +25,68 @@ var (
 	ErrConfigLoad                = errors.New("cannot load config file")
 	ErrYamlDump                  = errors.New("cannot dump content as yaml")
 	ErrManagerCreate             = errors.New("cannot create new manager")
+	ErrArchiveUnsafePath         = errors.New("archive entry has an unsafe path")
+	ErrArchiveLimit              = errors.New("archive exceeds extraction limits")
+	ErrArchiveNoMatch            = errors.New("archive does not contain a file matching pattern")
+	ErrArchiveFormat             = errors.New("unsupported archive format")
+	ErrNoEligibleRelease         = errors.New("no eligible release")
+	ErrInvalidUpdatePolicy       = errors.New("invalid update policy")
+	ErrPackageAdopt              = errors.New("cannot adopt package")
+	ErrPackageFiles              = errors.New("cannot load package files")
+	ErrRegistry                  = errors.New("registry error")
+	ErrPackageTemplate           = errors.New("cannot apply package template")
+	ErrProjectNotFound           = errors.New("no project manifest found")
+	ErrProjectLoad               = errors.New("cannot load project manifest")
+	ErrShim                      = errors.New("shim error")
+	ErrSelfUpdate                = errors.New("cannot update bpm")
 )
+
+// PackageError is the error of a single package in an operation on multiple packages.
+type PackageError struct {
+	Name string
+	Err  error
+}
+
+func (err *PackageError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Name, err.Err)
+}
+
+func (err *PackageError) Unwrap() error {
+	return err.Err
+}
+
+// UpdateError aggregates the failed packages of an update (or another operation on multiple packages).
+type UpdateError struct {
+	Failed []*PackageError
+	// number of packages that did not fail (including packages that were already up to date)
+	Succeeded int
+	// number of packages that were changed (updated, installed or adopted)
+	Changed int
+	// name of the operation, defaults to update
+	Operation string
+}
+
+func (err *UpdateError) Error() string {
+	messages := make([]string, 0, len(err.Failed))
+	for _, failed := range err.Failed {
+		messages = append(messages, failed.Error())
+	}
+	operation := err.Operation
+	if operation == "" {
+		operation = "update"
+	}
+	return fmt.Sprintf("%d of %d packages failed to %s: %s", len(err.Failed), len(err.Failed)+err.Succeeded, operation, strings.Join(messages, "; "))
+}
+
+func (err *UpdateError) Unwrap() []error {
+	errs := make([]error, 0, len(err.Failed))
+	for _, failed := range err.Failed {
+		errs = append(errs, failed)
+	}
+	return errs
+}
+
+// Partial reports if some packages were changed despite the failures.
+func (err *UpdateError) Partial() bool {
+	return err.Changed > 0
+}
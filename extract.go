@@ -0,0 +1,306 @@
+package bpm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"github.com/ulikunitz/xz/lzma"
+)
+
+const (
+	DefaultExtractMaxFileSize  int64 = 1 << 30
+	DefaultExtractMaxTotalSize int64 = 4 << 30
+	DefaultExtractMaxEntries         = 100000
+)
+
+// ExtractLimits bounds the amount of data read from an archive.
+// Zero values are replaced by the defaults.
+type ExtractLimits struct {
+	MaxFileSize  int64 `yaml:"max_file_size"`
+	MaxTotalSize int64 `yaml:"max_total_size"`
+	MaxEntries   int   `yaml:"max_entries"`
+}
+
+func (limits ExtractLimits) withDefaults() ExtractLimits {
+	if limits.MaxFileSize <= 0 {
+		limits.MaxFileSize = DefaultExtractMaxFileSize
+	}
+	if limits.MaxTotalSize <= 0 {
+		limits.MaxTotalSize = DefaultExtractMaxTotalSize
+	}
+	if limits.MaxEntries <= 0 {
+		limits.MaxEntries = DefaultExtractMaxEntries
+	}
+	return limits
+}
+
+// archiveEntry describes a single entry of an archive.
+// The name is stored as it is in the archive, it has to be sanitized before it is used as path.
+type archiveEntry struct {
+	name string
+	mode fs.FileMode
+	size int64
+}
+
+// archiveVisitFunc is called for every entry of an archive.
+// Returning done stops the walk without an error.
+type archiveVisitFunc func(entry archiveEntry, content io.Reader) (done bool, err error)
+
+// archiveWalkFunc walks all entries of an archive and calls visit for each of them.
+type archiveWalkFunc func(visit archiveVisitFunc) error
+
+// archiveGuard enforces the extraction limits while walking an archive.
+type archiveGuard struct {
+	limits  ExtractLimits
+	entries int
+	total   int64
+}
+
+func newArchiveGuard(limits ExtractLimits) *archiveGuard {
+	return &archiveGuard{
+		limits: limits.withDefaults(),
+	}
+}
+
+// entry checks the entry count and size limits.
+func (guard *archiveGuard) entry(name string, size int64) error {
+	guard.entries++
+	if guard.entries > guard.limits.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrArchiveLimit, guard.limits.MaxEntries)
+	}
+	if size > guard.limits.MaxFileSize {
+		return fmt.Errorf("%w: entry %q is larger than %d bytes", ErrArchiveLimit, name, guard.limits.MaxFileSize)
+	}
+	return nil
+}
+
+// reader wraps the content of an entry so that reading it counts against the limits.
+func (guard *archiveGuard) reader(name string, reader io.Reader) io.Reader {
+	return &guardedReader{
+		guard:  guard,
+		name:   name,
+		reader: reader,
+	}
+}
+
+type guardedReader struct {
+	guard  *archiveGuard
+	name   string
+	reader io.Reader
+	read   int64
+}
+
+func (reader *guardedReader) Read(p []byte) (int, error) {
+	n, err := reader.reader.Read(p)
+	reader.read += int64(n)
+	reader.guard.total += int64(n)
+	if reader.read > reader.guard.limits.MaxFileSize {
+		return n, fmt.Errorf("%w: entry %q is larger than %d bytes", ErrArchiveLimit, reader.name, reader.guard.limits.MaxFileSize)
+	}
+	if reader.guard.total > reader.guard.limits.MaxTotalSize {
+		return n, fmt.Errorf("%w: archive content is larger than %d bytes", ErrArchiveLimit, reader.guard.limits.MaxTotalSize)
+	}
+	return n, err
+}
+
+// sanitizeArchivePath returns a clean relative slash separated path
+// or an error if the name is absolute or leaves the archive root.
+func sanitizeArchivePath(name string) (string, error) {
+	cleanName := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(cleanName, "/") || filepath.VolumeName(cleanName) != "" || strings.ContainsRune(cleanName, 0) {
+		return "", fmt.Errorf("%w: %q", ErrArchiveUnsafePath, name)
+	}
+	cleanName = path.Clean(cleanName)
+	if cleanName == ".." || strings.HasPrefix(cleanName, "../") {
+		return "", fmt.Errorf("%w: %q", ErrArchiveUnsafePath, name)
+	}
+	return cleanName, nil
+}
+
+// isWithin reports if target is the root or inside of it.
+func isWithin(root string, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// decompressReader detects the compression of the stream by its magic bytes
+// and returns a reader for the decompressed content.
+// Uncompressed streams are returned as they are.
+func decompressReader(reader io.Reader) (io.Reader, error) {
+	bufReader := bufio.NewReader(reader)
+	magic, err := bufReader.Peek(6)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(bufReader)
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return xz.NewReader(bufReader)
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return bzip2.NewReader(bufReader), nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		// with a concurrency of 1 the stream is decoded without goroutines, the decoder needs no Close
+		decoder, err := zstd.NewReader(bufReader, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveFormat, err)
+		}
+		return decoder, nil
+	case bytes.HasPrefix(magic, []byte{0x5d, 0x00, 0x00}):
+		return lzma.NewReader(bufReader)
+	default:
+		return bufReader, nil
+	}
+}
+
+func tarWalker(reader io.Reader, limits ExtractLimits) archiveWalkFunc {
+	return func(visit archiveVisitFunc) error {
+		guard := newArchiveGuard(limits)
+		tarReader := tar.NewReader(reader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			err = guard.entry(header.Name, header.Size)
+			if err != nil {
+				return err
+			}
+			entry := archiveEntry{
+				name: header.Name,
+				size: header.Size,
+			}
+			switch header.Typeflag {
+			case tar.TypeReg:
+				entry.mode = fs.FileMode(header.Mode).Perm()
+			case tar.TypeDir:
+				entry.mode = fs.ModeDir | fs.FileMode(header.Mode).Perm()
+			case tar.TypeSymlink:
+				entry.mode = fs.ModeSymlink | 0o777
+			default:
+				// hard links, devices and fifos are never extracted
+				continue
+			}
+
+			done, err := visit(entry, guard.reader(entry.name, tarReader))
+			if err != nil || done {
+				return err
+			}
+		}
+	}
+}
+
+func zipWalker(reader *zip.Reader, limits ExtractLimits) archiveWalkFunc {
+	return func(visit archiveVisitFunc) error {
+		guard := newArchiveGuard(limits)
+		for _, file := range reader.File {
+			size := int64(file.UncompressedSize64)
+			if size < 0 {
+				size = guard.limits.MaxFileSize + 1
+			}
+			err := guard.entry(file.Name, size)
+			if err != nil {
+				return err
+			}
+			mode := file.FileInfo().Mode()
+			if !mode.IsRegular() && !mode.IsDir() && mode&fs.ModeSymlink == 0 {
+				continue
+			}
+			done, err := visitZipFile(guard, visit, file, archiveEntry{
+				name: file.Name,
+				mode: mode,
+				size: size,
+			})
+			if err != nil || done {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func visitZipFile(guard *archiveGuard, visit archiveVisitFunc, file *zip.File, entry archiveEntry) (bool, error) {
+	content, err := file.Open()
+	if err != nil {
+		return false, err
+	}
+	defer content.Close()
+	return visit(entry, guard.reader(entry.name, content))
+}
+
+// extractMatchingFile writes the first regular file whose lower case name in the archive matches binPattern
+// to outputPath. Entries with unsafe names are skipped, they are only rejected if they match.
+func extractMatchingFile(walk archiveWalkFunc, binPattern *regexp.Regexp, outputPath string) error {
+	found := false
+	err := walk(func(entry archiveEntry, content io.Reader) (bool, error) {
+		if !entry.mode.IsRegular() || !binPattern.MatchString(strings.ToLower(entry.name)) {
+			return false, nil
+		}
+		_, err := sanitizeArchivePath(entry.name)
+		if err != nil {
+			return true, err
+		}
+		found = true
+		return true, writeArchiveFile(outputPath, content, 0o644)
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrArchiveNoMatch, binPattern.String())
+	}
+	return nil
+}
+
+// writeArchiveFile writes content into a new file at outputPath.
+// An existing file (or symlink) at this location is replaced and not followed.
+func writeArchiveFile(outputPath string, content io.Reader, perm fs.FileMode) error {
+	err := removeExisting(outputPath)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(outputPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, content)
+	closeErr := file.Close()
+	if err != nil {
+		os.Remove(outputPath)
+		return err
+	}
+	return closeErr
+}
+
+func removeExisting(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%w: %q is an existing folder", ErrArchiveUnsafePath, path)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,252 @@
+package bpm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testArchiveEntry struct {
+	name     string
+	content  string
+	typeflag byte
+	linkname string
+}
+
+func buildTestTar(t testing.TB, entries []testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		typeflag := entry.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		err := writer.WriteHeader(&tar.Header{
+			Name:     entry.name,
+			Mode:     0o755,
+			Size:     int64(len(entry.content)),
+			Typeflag: typeflag,
+			Linkname: entry.linkname,
+		})
+		if err != nil {
+			t.Fatalf("cannot write tar header: %s", err)
+		}
+		_, err = writer.Write([]byte(entry.content))
+		if err != nil {
+			t.Fatalf("cannot write tar content: %s", err)
+		}
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func buildTestZip(t testing.TB, entries []testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name}
+		if entry.typeflag == tar.TypeSymlink {
+			header.SetMode(os.ModeSymlink | 0o777)
+			entry.content = entry.linkname
+		} else {
+			header.SetMode(0o755)
+		}
+		file, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("cannot write zip header: %s", err)
+		}
+		_, err = file.Write([]byte(entry.content))
+		if err != nil {
+			t.Fatalf("cannot write zip content: %s", err)
+		}
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func zipWalkerFromBytes(t testing.TB, content []byte, limits ExtractLimits) (archiveWalkFunc, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	return zipWalker(reader, limits), nil
+}
+
+func TestSanitizeArchivePath(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+		err    error
+	}{
+		{input: "bin/tool", output: "bin/tool"},
+		{input: "./bin/tool", output: "bin/tool"},
+		{input: "bin/../tool", output: "tool"},
+		{input: "bin\\tool", output: "bin/tool"},
+		{input: "../tool", err: ErrArchiveUnsafePath},
+		{input: "bin/../../tool", err: ErrArchiveUnsafePath},
+		{input: "..", err: ErrArchiveUnsafePath},
+		{input: "/etc/passwd", err: ErrArchiveUnsafePath},
+		{input: "\\etc\\passwd", err: ErrArchiveUnsafePath},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			output, err := sanitizeArchivePath(test.input)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.output, output)
+		})
+	}
+}
+
+func TestExtractMatchingFile(t *testing.T) {
+	entries := []testArchiveEntry{
+		{name: "README.md", content: "readme"},
+		{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+		{name: "dist/Tool", content: "binary"},
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		limits  ExtractLimits
+		entries []testArchiveEntry
+		content string
+		err     error
+	}{
+		{
+			name:    "match",
+			pattern: "dist/tool$",
+			entries: entries,
+			content: "binary",
+		},
+		{
+			name:    "skip-symlinks",
+			pattern: "link",
+			entries: entries,
+			err:     ErrArchiveNoMatch,
+		},
+		{
+			name:    "traversal",
+			pattern: "tool",
+			entries: []testArchiveEntry{{name: "../tool", content: "binary"}},
+			err:     ErrArchiveUnsafePath,
+		},
+		{
+			name:    "archive-name",
+			pattern: `^\./dist/tool$`,
+			entries: []testArchiveEntry{{name: "./dist/tool", content: "binary"}},
+			content: "binary",
+		},
+		{
+			name:    "skip-unsafe",
+			pattern: "dist/tool$",
+			entries: []testArchiveEntry{{name: "../README.md", content: "readme"}, {name: "dist/tool", content: "binary"}},
+			content: "binary",
+		},
+		{
+			name:    "file-size-limit",
+			pattern: "tool",
+			entries: entries,
+			limits:  ExtractLimits{MaxFileSize: 3},
+			err:     ErrArchiveLimit,
+		},
+		{
+			name:    "total-size-limit",
+			pattern: "tool",
+			entries: entries,
+			limits:  ExtractLimits{MaxTotalSize: 3},
+			err:     ErrArchiveLimit,
+		},
+		{
+			name:    "entry-limit",
+			pattern: "tool",
+			entries: entries,
+			limits:  ExtractLimits{MaxEntries: 2},
+			err:     ErrArchiveLimit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			walkers := map[string]archiveWalkFunc{
+				"tar": tarWalker(bytes.NewReader(buildTestTar(t, test.entries)), test.limits),
+			}
+			zipWalk, err := zipWalkerFromBytes(t, buildTestZip(t, test.entries), test.limits)
+			if assert.NoError(t, err) {
+				walkers["zip"] = zipWalk
+			}
+			for format, walk := range walkers {
+				t.Run(format, func(t *testing.T) {
+					outputPath := filepath.Join(t.TempDir(), "output")
+					err := extractMatchingFile(walk, regexp.MustCompile(test.pattern), outputPath)
+					if assert.ErrorIs(t, err, test.err) && test.err == nil {
+						content, err := os.ReadFile(outputPath)
+						assert.NoError(t, err)
+						assert.Equal(t, test.content, string(content))
+					}
+				})
+			}
+		})
+	}
+}
+
+// assertOnlyOutput checks that nothing but the output file was written into base.
+func assertOnlyOutput(t *testing.T, base string) {
+	entries, err := os.ReadDir(base)
+	if assert.NoError(t, err) {
+		for _, entry := range entries {
+			assert.Equal(t, "output", entry.Name(), "extraction must only write the output file")
+		}
+	}
+}
+
+func fuzzSeeds(f *testing.F, build func(t testing.TB, entries []testArchiveEntry) []byte) {
+	f.Add(build(f, []testArchiveEntry{{name: "tool", content: "binary"}}))
+	f.Add(build(f, []testArchiveEntry{{name: "../tool", content: "binary"}}))
+	f.Add(build(f, []testArchiveEntry{
+		{name: "a", typeflag: tar.TypeSymlink, linkname: "."},
+		{name: "b", typeflag: tar.TypeSymlink, linkname: "a/.."},
+		{name: "b/tool", content: "binary"},
+	}))
+}
+
+func FuzzExtractTar(f *testing.F) {
+	fuzzSeeds(f, buildTestTar)
+	for _, name := range []string{"dummy-bin.sh.tar"} {
+		content, err := os.ReadFile(getTestPath("files", name))
+		if err == nil {
+			f.Add(content)
+		}
+	}
+	limits := ExtractLimits{MaxFileSize: 1 << 20, MaxTotalSize: 1 << 20, MaxEntries: 100}
+	f.Fuzz(func(t *testing.T, content []byte) {
+		base := t.TempDir()
+		extractMatchingFile(tarWalker(bytes.NewReader(content), limits), regexp.MustCompile("tool"), filepath.Join(base, "output"))
+		assertOnlyOutput(t, base)
+	})
+}
+
+func FuzzExtractZip(f *testing.F) {
+	fuzzSeeds(f, buildTestZip)
+	for _, name := range []string{"dummy-bin.sh.zip"} {
+		content, err := os.ReadFile(getTestPath("files", name))
+		if err == nil {
+			f.Add(content)
+		}
+	}
+	limits := ExtractLimits{MaxFileSize: 1 << 20, MaxTotalSize: 1 << 20, MaxEntries: 100}
+	f.Fuzz(func(t *testing.T, content []byte) {
+		walk, err := zipWalkerFromBytes(t, content, limits)
+		if err != nil {
+			return
+		}
+		base := t.TempDir()
+		extractMatchingFile(walk, regexp.MustCompile("tool"), filepath.Join(base, "output"))
+		assertOnlyOutput(t, base)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/google/go-github/v84/github"
@@ -23,6 +24,9 @@ type GithubConfig struct {
 type GithubProvider struct {
 	client *github.Client
 	logger zerolog.Logger
+	config *Config
+	// rateLimit logs the rate limits once before the first request
+	rateLimit sync.Once
 }
 
 func init() {
@@ -44,16 +48,24 @@ func NewGithubProvider(logger zerolog.Logger, config *Config) PackageProvider {
 	provider := &GithubProvider{
 		client: github.NewClient(client),
 		logger: logger,
-	}
-	limits, _, err := provider.client.RateLimit.Get(context.Background())
-	if err != nil {
-		logger.Err(err).Msgf("cannot get rate limits")
-	} else {
-		logger.Debug().Msgf("got rate limits: %d (remaining %d, resets at %s)", limits.Core.Limit, limits.Core.Remaining, limits.Core.Reset.String())
+		config: config,
 	}
 	return provider
 }
 
+// logRateLimits logs the rate limits of the api on the first call. The limits are not
+// fetched in NewGithubProvider to keep commands without requests free of network calls.
+func (provider *GithubProvider) logRateLimits(ctx context.Context) {
+	provider.rateLimit.Do(func() {
+		limits, _, err := provider.client.RateLimit.Get(ctx)
+		if err != nil {
+			provider.logger.Err(err).Msgf("cannot get rate limits")
+		} else {
+			provider.logger.Debug().Msgf("got rate limits: %d (remaining %d, resets at %s)", limits.Core.Limit, limits.Core.Remaining, limits.Core.Reset.String())
+		}
+	})
+}
+
 // sortReleases sorts github releases inplace stable
 func (provider *GithubProvider) sortReleases(releases []*github.RepositoryRelease) {
 	sort.SliceStable(releases, func(i, j int) bool {
@@ -73,20 +85,28 @@ func (provider *GithubProvider) sortReleases(releases []*github.RepositoryReleas
 	})
 }
 
-func (provider *GithubProvider) getLatestRelease(pkg Package) (*github.RepositoryRelease, error) {
-	ctx := context.TODO()
+// repository returns owner and name of the repository of the package.
+func (provider *GithubProvider) repository(pkg Package) (owner string, repoName string, err error) {
+	splits := strings.SplitN(pkg.URL, "/", 3)
+	if len(splits) < 3 {
+		return "", "", fmt.Errorf("%w: url (%s) has not the correct github format (github.com/<owner>/<repo>)", ErrProviderConfig, pkg.URL)
+	}
+	return splits[1], splits[2], nil
+}
+
+func (provider *GithubProvider) getLatestRelease(ctx context.Context, pkg Package) (*github.RepositoryRelease, error) {
 	tagFilterRegex, err := regexp.Compile(pkg.TagFilter)
 	if err != nil {
 		return nil, fmt.Errorf("%w: tag filter %q is not a valid regex: %s", ErrProviderConfig, pkg.TagFilter, err)
 	}
 
-	splits := strings.SplitN(pkg.URL, "/", 3)
-	if len(splits) < 3 {
-		return nil, fmt.Errorf("%w: url (%s) has not the correct github format (github.com/<owner>/<repo>)", ErrProviderConfig, pkg.URL)
+	owner, repoName, err := provider.repository(pkg)
+	if err != nil {
+		return nil, err
 	}
-	owner := splits[1]
-	repoName := splits[2]
+	provider.logRateLimits(ctx)
 
+	minAge := pkg.minReleaseAge(provider.config)
 	listOptions := &github.ListOptions{
 		Page:    0,
 		PerPage: 10,
@@ -98,12 +118,12 @@ func (provider *GithubProvider) getLatestRelease(pkg Package) (*github.Repositor
 			return nil, fmt.Errorf("%w: cannot get releases: %s", ErrProviderConfig, err)
 		}
 		provider.sortReleases(releases)
-		for i := len(releases) - 1; i > 0; i-- {
+		for i := len(releases) - 1; i >= 0; i-- {
 			release := releases[i]
 			provider.logger.Debug().Msgf("found releases %v", release.GetTagName())
 		}
 
-		for i := len(releases) - 1; i > 0; i-- {
+		for i := len(releases) - 1; i >= 0; i-- {
 			release := releases[i]
 			tag := release.GetTagName()
 			if !tagFilterRegex.Match([]byte(tag)) {
@@ -112,6 +132,10 @@ func (provider *GithubProvider) getLatestRelease(pkg Package) (*github.Repositor
 			if release.GetPrerelease() && !pkg.PreReleases {
 				continue
 			}
+			if !releaseAgeReached(release.GetPublishedAt().Time, minAge) {
+				provider.logger.Debug().Msgf("skip release %s, it is younger than %s", tag, minAge)
+				continue
+			}
 			return release, nil
 		}
 
@@ -124,8 +148,8 @@ func (provider *GithubProvider) getLatestRelease(pkg Package) (*github.Repositor
 	return nil, fmt.Errorf("%w: cannot find a release (TagFilter: %q, PreReleases: %t)", ErrProviderConfig, pkg.TagFilter, pkg.PreReleases)
 }
 
-func (provider *GithubProvider) GetLatest(pkg Package) (version string, err error) {
-	release, err := provider.getLatestRelease(pkg)
+func (provider *GithubProvider) GetLatest(ctx context.Context, pkg Package) (version string, err error) {
+	release, err := provider.getLatestRelease(ctx, pkg)
 	if err != nil {
 		return "", err
 	}
@@ -133,39 +157,192 @@ func (provider *GithubProvider) GetLatest(pkg Package) (version string, err erro
 	return release.GetTagName(), err
 }
 
-func (provider *GithubProvider) FetchPackage(pkg Package, version string, cacheDir string) (path string, err error) {
-	ctx := context.TODO()
-	release, err := provider.getLatestRelease(pkg)
+func (provider *GithubProvider) GetLatestAssets(ctx context.Context, pkg Package) (version string, assets []string, err error) {
+	release, err := provider.getLatestRelease(ctx, pkg)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, asset := range release.Assets {
+		assets = append(assets, asset.GetName())
+	}
+	return release.GetTagName(), assets, nil
+}
+
+func (provider *GithubProvider) FetchPackage(ctx context.Context, pkg Package, version string, cacheDir string) (path string, err error) {
+	asset, err := provider.findAsset(ctx, pkg, version)
 	if err != nil {
 		return "", err
 	}
-	assetPattern, err := regexp.Compile(pkg.patternExpand(pkg.AssetPattern, version))
+	return provider.downloadAsset(ctx, asset, cacheDir)
+}
+
+func (provider *GithubProvider) GetDownloadURL(ctx context.Context, pkg Package, version string) (url string, err error) {
+	asset, err := provider.findAsset(ctx, pkg, version)
 	if err != nil {
 		return "", err
 	}
-	provider.logger.Debug().Msgf("search for pattern %s", assetPattern.String())
-	for _, asset := range release.Assets {
-		name := asset.GetName()
-		provider.logger.Debug().Msgf("try asset %s", name)
-		if assetPattern.Match([]byte(name)) {
-			url := asset.GetBrowserDownloadURL()
-			provider.logger.Debug().Msgf("get asset from %s", url)
-			req, err := provider.client.NewRequest("GET", url, nil)
-			if err != nil {
-				return path, err
+	return asset.GetBrowserDownloadURL(), nil
+}
+
+// findAsset returns the release asset matching the asset pattern of the package.
+func (provider *GithubProvider) findAsset(ctx context.Context, pkg Package, version string) (*github.ReleaseAsset, error) {
+	owner, repoName, err := provider.repository(pkg)
+	if err != nil {
+		return nil, err
+	}
+	provider.logRateLimits(ctx)
+	release, _, err := provider.client.Repositories.GetReleaseByTag(ctx, owner, repoName, version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot get release %s: %s", ErrProviderFetch, version, err)
+	}
+	// try the host libc first and the fallbacks of the package afterwards
+	for _, pattern := range pkg.patternCandidates(pkg.AssetPattern, version) {
+		assetPattern, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		provider.logger.Debug().Msgf("search for pattern %s", assetPattern.String())
+		for _, asset := range release.Assets {
+			name := asset.GetName()
+			provider.logger.Debug().Msgf("try asset %s", name)
+			if assetPattern.Match([]byte(name)) {
+				return asset, nil
 			}
-			path = filepath.Join(cacheDir, asset.GetName())
-			file, err := os.Create(path)
-			if err != nil {
-				return path, err
+		}
+	}
+	return nil, fmt.Errorf("%w: no matching asset found", ErrProviderFetch)
+}
+
+func (provider *GithubProvider) downloadAsset(ctx context.Context, asset *github.ReleaseAsset, cacheDir string) (path string, err error) {
+	url := asset.GetBrowserDownloadURL()
+	provider.logger.Debug().Msgf("get asset from %s", url)
+	req, err := provider.client.NewRequest("GET", url, nil)
+	if err != nil {
+		return path, err
+	}
+	path = filepath.Join(cacheDir, asset.GetName())
+	file, err := os.Create(path)
+	if err != nil {
+		return path, err
+	}
+	_, err = provider.client.Do(ctx, req, file)
+	file.Close()
+	if err != nil {
+		// do not leave partial downloads behind (e.g. after cancellation)
+		os.Remove(path)
+		return path, err
+	}
+	return path, nil
+}
+
+// SearchRepositories returns the repositories matching term, most stars first.
+func (provider *GithubProvider) SearchRepositories(ctx context.Context, term string, limit int) ([]SearchResult, error) {
+	provider.logRateLimits(ctx)
+	result, _, err := provider.client.Search.Repositories(ctx, term, &github.SearchOptions{
+		Sort:        "stars",
+		ListOptions: github.ListOptions{PerPage: limit},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrProviderFetch, err)
+	}
+	results := []SearchResult{}
+	for _, repo := range result.Repositories {
+		if repo.GetArchived() {
+			continue
+		}
+		results = append(results, SearchResult{
+			Name:        repo.GetName(),
+			Description: repo.GetDescription(),
+			URL:         "github.com/" + repo.GetFullName(),
+		})
+	}
+	return results, nil
+}
+
+// filteredReleases returns all releases matching the tag filter and pre release setting sorted ascending.
+func (provider *GithubProvider) filteredReleases(ctx context.Context, pkg Package) ([]*github.RepositoryRelease, error) {
+	tagFilterRegex, err := regexp.Compile(pkg.TagFilter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tag filter %q is not a valid regex: %s", ErrProviderConfig, pkg.TagFilter, err)
+	}
+	owner, repoName, err := provider.repository(pkg)
+	if err != nil {
+		return nil, err
+	}
+	provider.logRateLimits(ctx)
+
+	releases := []*github.RepositoryRelease{}
+	listOptions := &github.ListOptions{
+		Page:    0,
+		PerPage: 100,
+	}
+	for {
+		page, resp, err := provider.client.Repositories.ListReleases(ctx, owner, repoName, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot get releases: %s", ErrProviderFetch, err)
+		}
+		for _, release := range page {
+			if !tagFilterRegex.MatchString(release.GetTagName()) {
+				continue
 			}
-			defer file.Close()
-			_, err = provider.client.Do(ctx, req, file)
-			if err != nil {
-				return path, err
+			if release.GetPrerelease() && !pkg.PreReleases {
+				continue
 			}
-			return path, nil
+			releases = append(releases, release)
 		}
+		if resp.NextPage == 0 {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+	provider.sortReleases(releases)
+	return releases, nil
+}
+
+// ListReleases returns all releases of the package, newest first.
+func (provider *GithubProvider) ListReleases(ctx context.Context, pkg Package) ([]Release, error) {
+	releases, err := provider.filteredReleases(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Release, 0, len(releases))
+	for i := len(releases) - 1; i >= 0; i-- {
+		result = append(result, Release{
+			Version:   releases[i].GetTagName(),
+			Published: releases[i].GetPublishedAt().Time,
+		})
+	}
+	return result, nil
+}
+
+// GetReleaseNotes returns the notes of all releases after from up to (including) to, newest first.
+// Releases are filtered like in GetLatest. An empty from returns all releases up to to.
+func (provider *GithubProvider) GetReleaseNotes(ctx context.Context, pkg Package, from string, to string) ([]ReleaseNote, error) {
+	releases, err := provider.filteredReleases(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(releases))
+	byTag := make(map[string]*github.RepositoryRelease, len(releases))
+	for _, release := range releases {
+		tags = append(tags, release.GetTagName())
+		byTag[release.GetTagName()] = release
+	}
+	between, err := releasesBetween(tags, from, to)
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]ReleaseNote, 0, len(between))
+	for _, tag := range between {
+		release := byTag[tag]
+		notes = append(notes, ReleaseNote{
+			Version:   tag,
+			Name:      release.GetName(),
+			Published: release.GetPublishedAt().Time,
+			URL:       release.GetHTMLURL(),
+			Body:      release.GetBody(),
+		})
 	}
-	return path, fmt.Errorf("%w: no matching asset found", ErrProviderFetch)
+	return notes, nil
 }
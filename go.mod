@@ -13,6 +13,7 @@ require (
 
 require (
 	github.com/google/go-github/v84 v84.0.0
+	github.com/klauspost/compress v1.18.0
 	github.com/stretchr/testify v1.11.1
 	github.com/ulikunitz/xz v0.5.15
 	gopkg.in/yaml.v3 v3.0.1
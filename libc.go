@@ -0,0 +1,131 @@
+package bpm
+
+import (
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	LibcGlibc = "glibc"
+	LibcMusl  = "musl"
+	// LibcEnv overrides the detected libc of the host.
+	LibcEnv = "BPM_LIBC"
+)
+
+var (
+	// hostLibc returns the libc of the running system. It is a variable to be replaced in tests.
+	hostLibc = sync.OnceValue(detectLibc)
+	// binaries used to find the dynamic loader of the system
+	libcProbeBinaries = []string{"/bin/sh", "/usr/bin/env", "/bin/ls"}
+)
+
+// detectLibc detects the libc of the host. It returns an empty string
+// for systems without a (known) libc, e.g. non linux systems.
+func detectLibc() string {
+	if libc := os.Getenv(LibcEnv); libc != "" {
+		return libc
+	}
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+	for _, binary := range libcProbeBinaries {
+		if libc := libcFromInterpreter(elfInterpreter(binary)); libc != "" {
+			return libc
+		}
+	}
+	if libc := libcFromLoaders(); libc != "" {
+		return libc
+	}
+	output, _ := exec.Command("ldd", "--version").CombinedOutput()
+	return libcFromLddOutput(string(output))
+}
+
+// elfInterpreter returns the dynamic loader requested by the elf binary at path.
+func elfInterpreter(path string) string {
+	file, err := elf.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	for _, prog := range file.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		content := make([]byte, prog.Filesz)
+		_, err := prog.ReadAt(content, 0)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimRight(string(content), "\x00")
+	}
+	return ""
+}
+
+func libcFromInterpreter(interpreter string) string {
+	name := filepath.Base(interpreter)
+	switch {
+	case strings.HasPrefix(name, "ld-musl"):
+		return LibcMusl
+	case strings.HasPrefix(name, "ld-linux"), strings.HasPrefix(name, "ld64.so"), strings.HasPrefix(name, "ld.so"):
+		return LibcGlibc
+	default:
+		return ""
+	}
+}
+
+func libcFromLoaders() string {
+	musl, _ := filepath.Glob("/lib/ld-musl-*.so.1")
+	if len(musl) > 0 {
+		return LibcMusl
+	}
+	for _, pattern := range []string{"/lib/ld-linux*.so.*", "/lib64/ld-linux*.so.*", "/lib/*/ld-linux*.so.*"} {
+		glibc, _ := filepath.Glob(pattern)
+		if len(glibc) > 0 {
+			return LibcGlibc
+		}
+	}
+	return ""
+}
+
+func libcFromLddOutput(output string) string {
+	lower := strings.ToLower(output)
+	switch {
+	case strings.Contains(lower, "musl"):
+		return LibcMusl
+	case strings.Contains(lower, "glibc"), strings.Contains(lower, "gnu libc"), strings.Contains(lower, "gnu c library"):
+		return LibcGlibc
+	default:
+		return ""
+	}
+}
+
+// libcValue maps the libc name with the libc map of the package.
+func (pkg *Package) libcValue(libc string) string {
+	value, ok := pkg.Libc[libc]
+	if ok {
+		return value
+	}
+	return libc
+}
+
+// patternCandidates expands the pattern for the host libc followed by the libc fallbacks of the package.
+// Patterns without a libc placeholder have only one candidate.
+func (pkg *Package) patternCandidates(pattern string, version string) []string {
+	candidates := []string{pkg.patternExpandLibc(pattern, version, hostLibc())}
+	if !strings.Contains(pattern, "${libc}") && !strings.Contains(pattern, "$libc") {
+		return candidates
+	}
+	for _, fallback := range pkg.LibcFallback {
+		candidate := pkg.patternExpandLibc(pattern, version, fallback)
+		if !slices.Contains(candidates, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
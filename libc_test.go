@@ -0,0 +1,88 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setTestLibc replaces the detected host libc for the duration of the test.
+func setTestLibc(t *testing.T, libc string) {
+	backup := hostLibc
+	hostLibc = func() string { return libc }
+	t.Cleanup(func() {
+		hostLibc = backup
+	})
+}
+
+func TestLibcFromInterpreter(t *testing.T) {
+	tests := map[string]string{
+		"/lib/ld-musl-x86_64.so.1":    LibcMusl,
+		"/lib/ld-musl-aarch64.so.1":   LibcMusl,
+		"/lib64/ld-linux-x86-64.so.2": LibcGlibc,
+		"/lib/ld-linux-aarch64.so.1":  LibcGlibc,
+		"/lib64/ld64.so.2":            LibcGlibc,
+		"/nix/store/xyz/lib/ld.so.1":  LibcGlibc,
+		"":                            "",
+		"/system/bin/linker64":        "",
+	}
+	for interpreter, libc := range tests {
+		assert.Equal(t, libc, libcFromInterpreter(interpreter), interpreter)
+	}
+}
+
+func TestLibcFromLddOutput(t *testing.T) {
+	assert.Equal(t, LibcMusl, libcFromLddOutput("musl libc (x86_64)\nVersion 1.2.4\n"))
+	assert.Equal(t, LibcGlibc, libcFromLddOutput("ldd (Debian GLIBC 2.36-9+deb12u4) 2.36\n"))
+	assert.Equal(t, LibcGlibc, libcFromLddOutput("ldd (GNU libc) 2.39\n"))
+	assert.Equal(t, "", libcFromLddOutput(""))
+}
+
+func TestDetectLibcEnv(t *testing.T) {
+	t.Setenv(LibcEnv, "custom")
+	assert.Equal(t, "custom", detectLibc())
+}
+
+func TestPatternCandidates(t *testing.T) {
+	setTestLibc(t, LibcGlibc)
+	pkg := dummyPackage()
+	pkg.Libc = map[string]string{
+		LibcGlibc: "gnu",
+	}
+	pkg.LibcFallback = []string{"musl", "gnu", "static"}
+
+	assert.Equal(t, []string{"tool-gnu", "tool-musl", "tool-static"}, pkg.patternCandidates("tool-${libc}", ""))
+	assert.Equal(t, []string{"tool-testName"}, pkg.patternCandidates("tool-${name}", ""), "patterns without libc have no fallbacks")
+}
+
+func TestFetchFromDownloadURLLibcFallback(t *testing.T) {
+	setTestLibc(t, LibcMusl)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tool-static" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "binary")
+	}))
+	defer server.Close()
+
+	manager := getDummyManagerImpl(t)
+	pkg := dummyPackage()
+	pkg.DownloadURL = server.URL + "/tool-${libc}"
+	pkg.LibcFallback = []string{"static"}
+	path, err := manager.FetchFromDownloadURL(context.Background(), *pkg, "v1.0.0", t.TempDir())
+	if assert.NoError(t, err) {
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "binary", string(content))
+	}
+
+	pkg.LibcFallback = nil
+	_, err = manager.FetchFromDownloadURL(context.Background(), *pkg, "v1.0.0", t.TempDir())
+	assert.ErrorIs(t, err, ErrProviderFetch)
+}
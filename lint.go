@@ -0,0 +1,249 @@
+package bpm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ArchiveFormats are the supported values of archive_format.
+	ArchiveFormats = []string{"", "tar", "tar.gz", "tar.xz", "zip", "deb", "rpm"}
+	// InstallTypes are the supported values of install_type.
+	InstallTypes = []string{InstallTypeBinary, InstallTypeAppImage}
+	// UpdatePolicies are the supported values of update_policy.
+	UpdatePolicies = []string{"", UpdatePolicyPatch, UpdatePolicyMinor, UpdatePolicyMajor}
+	// PatternPlaceholders are the placeholders which can be used in asset_pattern, bin_pattern and download_url.
+	PatternPlaceholders = []string{"goos", "goarch", "libc", "name", "version"}
+
+	yamlErrorLine    = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownField = regexp.MustCompile(`^field (\S+) not found`)
+)
+
+// lintSampleVersion is used to expand the version placeholder before compiling patterns.
+const lintSampleVersion = "v1.0.0"
+
+// Lint checks package files for unknown fields, invalid values, regular expressions and placeholders
+// and unknown providers. Without paths all package files and templates are checked.
+// Files in the templates folder are checked as templates which do not need a name or provider.
+func (manager *ManagerImpl) Lint(ctx context.Context, paths []string) ([]LintIssue, error) {
+	if len(paths) == 0 {
+		var err error
+		paths, err = manager.lintPaths()
+		if err != nil {
+			return nil, err
+		}
+	}
+	issues := []LintIssue{}
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return issues, err
+		}
+		template := cleanDir(filepath.Dir(path)) == cleanDir(manager.templatesFolder())
+		issues = append(issues, manager.lintFile(path, template)...)
+	}
+	return issues, nil
+}
+
+// lintPaths returns all yaml files in the packages and templates folder.
+func (manager *ManagerImpl) lintPaths() ([]string, error) {
+	paths := []string{}
+	for _, folder := range []string{manager.config.PackagesFolder, manager.templatesFolder()} {
+		err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(info.Name(), ".yaml") {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
+
+func (manager *ManagerImpl) lintFile(path string, template bool) []LintIssue {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return []LintIssue{{Path: path, Message: err.Error()}}
+	}
+	node := yaml.Node{}
+	err = yaml.Unmarshal(content, &node)
+	if err != nil {
+		return []LintIssue{yamlLintIssue(path, err)}
+	}
+	if len(node.Content) == 0 {
+		return []LintIssue{{Path: path, Message: "file is empty"}}
+	}
+	header := struct {
+		SchemaVersion int `yaml:"schema_version"`
+	}{SchemaVersion: 1}
+	// templates do not need a schema version
+	if err := node.Decode(&header); err == nil && !template && header.SchemaVersion != PackageSchemaVersion {
+		return []LintIssue{{
+			Path:    path,
+			Line:    fieldLine(&node, "schema_version"),
+			Field:   "schema_version",
+			Message: fmt.Sprintf("schema version %d is not supported, run migrate", header.SchemaVersion),
+		}}
+	}
+
+	// decode the file itself strictly to find unknown fields and wrong types
+	issues := []LintIssue{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&PackageV2{})
+	if err != nil {
+		typeErr := &yaml.TypeError{}
+		if !errors.As(err, &typeErr) {
+			return []LintIssue{yamlLintIssue(path, err)}
+		}
+		for _, message := range typeErr.Errors {
+			issues = append(issues, yamlLintIssue(path, errors.New(message)))
+		}
+		return issues
+	}
+
+	// check the values with the applied template
+	pkg := Package{}
+	if template {
+		err = node.Decode(&pkg.PackageV2)
+	} else {
+		pkg, err = manager.decodePackage(&node)
+	}
+	if err != nil {
+		return []LintIssue{{Path: path, Line: fieldLine(&node, "extends"), Message: err.Error()}}
+	}
+	for _, issue := range manager.lintPackage(&pkg, template) {
+		issue.Path = path
+		issue.Line = fieldLine(&node, issue.Field)
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// lintPackage checks the values of pkg. Templates may leave out the required fields.
+func (manager *ManagerImpl) lintPackage(pkg *Package, template bool) []LintIssue {
+	issues := []LintIssue{}
+	add := func(field string, format string, args ...any) {
+		issues = append(issues, LintIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	if !template {
+		if pkg.Name == "" {
+			add("name", "name is required")
+		}
+		if pkg.Provider == "" {
+			add("provider", "provider is required")
+		}
+	}
+	if pkg.Provider != "" {
+		if _, ok := manager.Providers[pkg.Provider]; !ok {
+			add("provider", "unknown provider %q (known: %s)", pkg.Provider, strings.Join(sortedKeys(manager.Providers), ", "))
+		}
+	}
+	enums := []struct {
+		field  string
+		value  string
+		values []string
+	}{
+		{"archive_format", pkg.ArchiveFormat, ArchiveFormats},
+		{"install_type", pkg.InstallType, InstallTypes},
+		{"update_policy", pkg.UpdatePolicy, UpdatePolicies},
+	}
+	for _, enum := range enums {
+		if !slices.Contains(enum.values, enum.value) {
+			add(enum.field, "unknown value %q (allowed: %s)", enum.value, strings.Join(slices.DeleteFunc(slices.Clone(enum.values), func(value string) bool {
+				return value == ""
+			}), ", "))
+		}
+	}
+	patterns := []struct {
+		field   string
+		pattern string
+		regex   bool
+	}{
+		{"asset_pattern", pkg.AssetPattern, true},
+		{"bin_pattern", pkg.BinPattern, true},
+		{"download_url", pkg.DownloadURL, false},
+	}
+	for _, pattern := range patterns {
+		for _, placeholder := range unknownPlaceholders(pattern.pattern) {
+			add(pattern.field, "unknown placeholder ${%s} (allowed: %s)", placeholder, strings.Join(PatternPlaceholders, ", "))
+		}
+		if !pattern.regex {
+			continue
+		}
+		if _, err := regexp.Compile(pkg.patternExpand(pattern.pattern, lintSampleVersion)); err != nil {
+			add(pattern.field, "invalid regular expression: %s", err)
+		}
+	}
+	regexes := []struct {
+		field string
+		regex string
+	}{
+		{"tag_filter", pkg.TagFilter},
+		{"version_regex", pkg.VersionRegex},
+	}
+	for _, regex := range regexes {
+		if _, err := regexp.Compile(regex.regex); err != nil {
+			add(regex.field, "invalid regular expression: %s", err)
+		}
+	}
+	return issues
+}
+
+// unknownPlaceholders returns the placeholders of pattern which are not expanded by patternExpand.
+func unknownPlaceholders(pattern string) []string {
+	unknown := []string{}
+	os.Expand(pattern, func(name string) string {
+		if !slices.Contains(PatternPlaceholders, name) && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+		return ""
+	})
+	sort.Strings(unknown)
+	return unknown
+}
+
+// fieldLine returns the line of the top level key field of the yaml document or 0 if it is not set.
+func fieldLine(node *yaml.Node, field string) int {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == field {
+			return node.Content[i].Line
+		}
+	}
+	return 0
+}
+
+// yamlLintIssue converts a yaml error into an issue with the line of the error.
+func yamlLintIssue(path string, err error) LintIssue {
+	issue := LintIssue{Path: path, Message: err.Error()}
+	if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		issue.Line, _ = strconv.Atoi(match[1])
+		issue.Message = match[2]
+	}
+	if match := yamlUnknownField.FindStringSubmatch(issue.Message); match != nil {
+		issue.Field = match[1]
+		issue.Message = fmt.Sprintf("unknown field %s", match[1])
+	}
+	return issue
+}
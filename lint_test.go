@@ -0,0 +1,160 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		issues  []LintIssue
+	}{
+		{
+			name: "valid",
+			content: `schema_version: 2
+name: tool
+provider: dummy
+url: github.com/example/tool
+asset_pattern: "^${name}_${version}_${goos}_${goarch}\\.tar\\.gz$"
+archive_format: tar.gz
+tag_filter: "^v\\d+"
+`,
+			issues: []LintIssue{},
+		},
+		{
+			name: "template",
+			content: `schema_version: 2
+name: tool
+provider: dummy
+extends: goreleaser-zip
+`,
+			issues: []LintIssue{},
+		},
+		{
+			name: "unknown-field",
+			content: `schema_version: 2
+name: tool
+provider: dummy
+archive_fromat: tar.gz
+`,
+			issues: []LintIssue{{Line: 4, Field: "archive_fromat", Message: "unknown field archive_fromat"}},
+		},
+		{
+			name: "wrong-type",
+			content: `schema_version: 2
+name: tool
+provider: dummy
+goos: linux
+`,
+			issues: []LintIssue{{Line: 4, Message: "cannot unmarshal !!str `linux` into map[string]string"}},
+		},
+		{
+			name: "invalid-values",
+			content: `schema_version: 2
+name: tool
+provider: gitlab.com
+archive_format: tgz
+asset_pattern: "${os}-(${name}"
+bin_pattern: "${name}"
+download_url: "https://example.com/${version}/${arch}"
+tag_filter: "v[0-9"
+update_policy: weekly
+`,
+			issues: []LintIssue{
+				{Line: 3, Field: "provider", Message: `unknown provider "gitlab.com" (known: dummy)`},
+				{Line: 4, Field: "archive_format", Message: `unknown value "tgz" (allowed: tar, tar.gz, tar.xz, zip, deb, rpm)`},
+				{Line: 9, Field: "update_policy", Message: `unknown value "weekly" (allowed: patch, minor, major)`},
+				{Line: 5, Field: "asset_pattern", Message: "unknown placeholder ${os} (allowed: goos, goarch, libc, name, version)"},
+				{Line: 5, Field: "asset_pattern", Message: "invalid regular expression: error parsing regexp: missing closing ): `-(tool`"},
+				{Line: 7, Field: "download_url", Message: "unknown placeholder ${arch} (allowed: goos, goarch, libc, name, version)"},
+				{Line: 8, Field: "tag_filter", Message: "invalid regular expression: error parsing regexp: missing closing ]: `[0-9`"},
+			},
+		},
+		{
+			name:    "missing-fields",
+			content: "schema_version: 2\nurl: github.com/example/tool\n",
+			issues: []LintIssue{
+				{Field: "name", Message: "name is required"},
+				{Field: "provider", Message: "provider is required"},
+			},
+		},
+		{
+			name:    "old-schema",
+			content: "name: tool\nprovider: dummy\n",
+			issues:  []LintIssue{{Field: "schema_version", Message: "schema version 1 is not supported, run migrate"}},
+		},
+		{
+			name:    "unknown-template",
+			content: "schema_version: 2\nname: tool\nprovider: dummy\nextends: missing\n",
+			issues:  []LintIssue{{Line: 4, Message: "cannot apply package template: template missing not found"}},
+		},
+		{
+			name:    "syntax-error",
+			content: "schema_version: 2\nname: [tool\n",
+			issues:  []LintIssue{{Line: 1, Message: "did not find expected ',' or ']'"}},
+		},
+		{
+			name:    "empty",
+			content: "",
+			issues:  []LintIssue{{Message: "file is empty"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getTestManager(t, testManagerOptions{config: testTemplateConfig, templates: testFolderTemplates})
+			manager.Providers[dummyProviderName] = &DummyProvider{}
+			path := writePackageFile(t, manager, "tool", test.content)
+			for i := range test.issues {
+				test.issues[i].Path = path
+			}
+			issues, err := manager.Lint(context.Background(), []string{path})
+			assert.NoError(t, err)
+			assert.Equal(t, test.issues, issues)
+		})
+	}
+}
+
+func TestManagerLintAll(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{config: testTemplateConfig, templates: testFolderTemplates})
+	manager.Providers[dummyProviderName] = &DummyProvider{}
+	writePackageFile(t, manager, "tool", "schema_version: 2\nname: tool\nprovider: dummy\nextends: goreleaser\n")
+	// templates do not need a name or provider but are checked strictly
+	template := filepath.Join(manager.templatesFolder(), "broken.yaml")
+	assert.NoError(t, os.WriteFile(template, []byte("schema_version: 2\nbin_pattern: \"${name\"\nbin_patern: tool\n"), 0o644))
+
+	issues, err := manager.Lint(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []LintIssue{
+		{Path: template, Line: 3, Field: "bin_patern", Message: "unknown field bin_patern"},
+	}, issues)
+}
+
+func TestPackageSchema(t *testing.T) {
+	schema := PackageSchema()
+	assert.Equal(t, jsonSchemaDraft, schema["$schema"])
+	assert.Equal(t, false, schema["additionalProperties"])
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "enum": ArchiveFormats}, properties["archive_format"])
+	assert.Equal(t, map[string]any{"type": "string", "default": "${name}"}, properties["bin_pattern"])
+	assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}, properties["goos"])
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["min_release_age"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, properties["version_command"])
+	assert.NotContains(t, properties, "registry")
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema := ConfigSchema()
+	properties := schema["properties"].(map[string]any)
+	assert.NotContains(t, properties, "dryrun")
+	registries := properties["registries"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, []string{RegistryTypeGit, RegistryTypeHTTP}, registries["properties"].(map[string]any)["type"].(map[string]any)["enum"])
+	template := properties["templates"].(map[string]any)["additionalProperties"].(map[string]any)
+	assert.NotContains(t, template, "required")
+	assert.Contains(t, template["properties"], "extends")
+}
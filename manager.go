@@ -1,9 +1,10 @@
 package bpm
 
 import (
-	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,11 +13,11 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/ulikunitz/xz"
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -27,23 +28,50 @@ type SchemaVersion struct {
 	Version int
 }
 
-type ManagerCreateFunc func(configPath string, logger zerolog.Logger, migrate bool) (Manager, error)
+// ManagerOptions select what NewManager loads.
+type ManagerOptions struct {
+	// Migrate does not load the state, so that old files can be migrated
+	Migrate bool
+	// StateOnly loads the config and the state without providers and package files.
+	// It is used by commands which run on every prompt or call of a shim.
+	StateOnly bool
+}
+
+type ManagerCreateFunc func(configPath string, logger zerolog.Logger, options ManagerOptions) (Manager, error)
 
+// Manager manages the configured packages. The methods return typed results
+// and leave the presentation to the caller (e.g. the bpm command).
 type Manager interface {
 	Config() *Config
 	Init() error
 	SaveState() error
 	LoadState() error
-	Info(name string) error
-	Remove(name string) error
-	List() error
-	Installed() error
-	Add(name string, url string) error
-	Outdated() error
-	Install(name string, force bool) error
-	Update(packageNames []string) error
+	Info(ctx context.Context, name string) (PackageInfo, error)
+	Remove(ctx context.Context, name string) (RemoveResult, error)
+	List(ctx context.Context) ([]PackageStatus, error)
+	Installed(ctx context.Context) ([]PackageStatus, error)
+	AddCandidates(ctx context.Context, name string, url string) (version string, assets []string, err error)
+	Add(ctx context.Context, name string, url string, version string, asset string) error
+	Outdated(ctx context.Context) ([]OutdatedEntry, error)
+	Install(ctx context.Context, name string, force bool) (InstallResult, error)
+	Update(ctx context.Context, packageNames []string) ([]InstallResult, error)
+	Changelog(ctx context.Context, name string, from string, to string) ([]ReleaseNote, error)
+	Adopt(ctx context.Context, packageNames []string) ([]AdoptResult, error)
+	Verify(ctx context.Context) ([]VerifyIssue, error)
+	Registries(ctx context.Context) ([]RegistryStatus, error)
+	UpdateRegistries(ctx context.Context, names []string) ([]RegistryStatus, error)
+	Search(ctx context.Context, term string, remote bool) ([]SearchResult, error)
+	Lint(ctx context.Context, paths []string) ([]LintIssue, error)
+	Project(ctx context.Context, dir string) (ProjectStatus, error)
+	InstallProject(ctx context.Context, dir string) ([]InstallResult, error)
+	Shims(ctx context.Context) ([]ShimStatus, error)
+	AddShims(ctx context.Context, names []string) ([]ShimStatus, error)
+	RemoveShims(ctx context.Context, names []string) ([]ShimStatus, error)
+	ResolveShim(ctx context.Context, name string, dir string) (ShimStatus, error)
+	Versions(ctx context.Context, name string) ([]string, error)
+	SelfUpdate(ctx context.Context, current string, executable string, force bool) (InstallResult, error)
 	Migrate() error
-	FetchFromDownloadURL(pkg Package, version string, cacheDir string) (path string, err error)
+	FetchFromDownloadURL(ctx context.Context, pkg Package, version string, cacheDir string) (path string, err error)
 }
 
 type ManagerImpl struct {
@@ -55,9 +83,11 @@ type ManagerImpl struct {
 	// Place to write stdout message to. Defaults to os.Stdout. Used for testing.
 	stdout io.Writer
 	tmpDir string
+	// package files are not loaded (see ManagerOptions)
+	stateOnly bool
 }
 
-func NewManager(configPath string, logger zerolog.Logger, migrate bool) (Manager, error) {
+func NewManager(configPath string, logger zerolog.Logger, options ManagerOptions) (Manager, error) {
 	config, err := ReadConfig(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrManagerCreate, err)
@@ -69,20 +99,23 @@ func NewManager(configPath string, logger zerolog.Logger, migrate bool) (Manager
 		Packages:  make(map[string]Package),
 		logger:    logger.With().Str("module", "manage").Logger(),
 		stdout:    os.Stdout,
+		stateOnly: options.StateOnly,
 	}
 
-	for name, providerFunc := range PackageProviders {
-		manager.Providers[name] = providerFunc(manager.logger, config)
+	if !options.StateOnly {
+		for name, providerFunc := range PackageProviders {
+			manager.Providers[name] = providerFunc(manager.logger, config)
+		}
 	}
 	err = manager.Init()
 	if err != nil {
 		return manager, fmt.Errorf("%w: %s", ErrManagerCreate, err)
 	}
 
-	if !migrate {
+	if !options.Migrate {
 		err = manager.LoadState()
 		if err != nil {
-			err = fmt.Errorf("%w: %s", ErrManagerCreate, err)
+			err = fmt.Errorf("%w: %w", ErrManagerCreate, err)
 		}
 	}
 	return manager, err
@@ -110,6 +143,10 @@ func (manager *ManagerImpl) Init() error {
 }
 
 func (manager *ManagerImpl) SaveState() error {
+	if manager.config.DryRun {
+		manager.logger.Debug().Msg("dry run: do not save state")
+		return nil
+	}
 	stateFile := filepath.Join(manager.config.StateFolder, "state.yaml")
 	return dumpYaml(stateFile, &manager.StateFile)
 }
@@ -132,7 +169,12 @@ func (manager *ManagerImpl) LoadState() error {
 	} else if err != nil {
 		return err
 	}
+	if manager.stateOnly {
+		return nil
+	}
 
+	// broken package files are skipped, so that all of them are reported at once
+	packageErrs := []error{}
 	err = filepath.Walk(manager.config.PackagesFolder, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -141,10 +183,10 @@ func (manager *ManagerImpl) LoadState() error {
 			return nil
 		}
 		if strings.HasSuffix(info.Name(), ".yaml") {
-			pkg := Package{}
-			err = loadYaml(path, &pkg)
+			pkg, err := manager.loadPackageFile(path)
 			if err != nil {
-				return err
+				packageErrs = append(packageErrs, fmt.Errorf("%s: %w", path, err))
+				return nil
 			}
 			manager.logger.Info().Msgf("found package %s", pkg.Name)
 			manager.Packages[pkg.Name] = pkg
@@ -152,7 +194,11 @@ func (manager *ManagerImpl) LoadState() error {
 		return nil
 	})
 	if os.IsNotExist(err) {
-		return nil
+		err = nil
+	}
+	manager.loadRegistryPackages()
+	if err == nil && len(packageErrs) > 0 {
+		err = fmt.Errorf("%w: %w", ErrPackageFiles, errors.Join(packageErrs...))
 	}
 	if err != nil {
 		manager.logger.Warn().Msgf("got error from loading packages: %s", err)
@@ -160,52 +206,59 @@ func (manager *ManagerImpl) LoadState() error {
 	return err
 }
 
-func (manager *ManagerImpl) Info(name string) error {
-	pkgName, ok := manager.Packages[name]
-	if !ok {
-		return fmt.Errorf("%w: %s", ErrPackageNotFound, name)
-	}
-	encoder := yaml.NewEncoder(manager.stdout)
-	encoder.Encode(pkgName)
-	version, ok := manager.StateFile.Packages[name]
+// Info returns the configuration and the installed version of the package.
+func (manager *ManagerImpl) Info(ctx context.Context, name string) (PackageInfo, error) {
+	pkg, ok := manager.Packages[name]
 	if !ok {
-		version = "not installed"
+		return PackageInfo{}, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
 	}
-	fmt.Fprintf(manager.stdout, "version: %s\n", version)
-	return nil
+	return PackageInfo{
+		Package: pkg,
+		Version: manager.StateFile.Packages[name],
+	}, nil
 }
 
-func (manager *ManagerImpl) List() error {
-	for name := range manager.Packages {
-		fmt.Fprintf(manager.stdout, "- %s\n", name)
+// List returns all configured packages sorted by name.
+func (manager *ManagerImpl) List(ctx context.Context) ([]PackageStatus, error) {
+	packages := []PackageStatus{}
+	for _, name := range manager.sortedPackageNames() {
+		packages = append(packages, manager.packageStatus(name))
 	}
-	return nil
+	return packages, nil
 }
 
-func (manager *ManagerImpl) Installed() error {
-	for name, version := range manager.StateFile.Packages {
-		fmt.Fprintf(manager.stdout, "%s - %s\n", name, version)
+// Installed returns all installed packages sorted by name.
+func (manager *ManagerImpl) Installed(ctx context.Context) ([]PackageStatus, error) {
+	packages := []PackageStatus{}
+	for _, name := range sortedKeys(manager.StateFile.Packages) {
+		packages = append(packages, manager.packageStatus(name))
 	}
-	return nil
+	return packages, nil
 }
 
-func (manager *ManagerImpl) Add(name string, url string) error {
-	splitted := strings.Split(url, "/")
-	provider := splitted[0]
-	pkg := Package{
-		PackageV2: PackageV2{
-			SchemaVersion: PackageSchemaVersion,
-			Name:          name,
-			URL:           url,
-			Provider:      provider,
-		},
+// Add creates a new package file. If the provider can list release assets
+// the asset pattern and archive settings are proposed from the latest release.
+// The version and the asset are returned by AddCandidates, so the release is not queried again.
+// If they are empty the latest release is queried and the best matching asset is selected.
+func (manager *ManagerImpl) Add(ctx context.Context, name string, url string, version string, asset string) error {
+	pkg := newAddPackage(name, url)
+	if assetProvider, ok := manager.Providers[pkg.Provider].(AssetProvider); ok {
+		err := manager.proposePackage(ctx, &pkg, assetProvider, version, asset)
+		if errors.Is(err, ErrInvalidInput) {
+			return err
+		} else if err != nil {
+			manager.logger.Warn().Msgf("cannot propose package settings, write minimal package file: %s", err)
+		}
 	}
 	manager.Packages[name] = pkg
 	return dumpYaml(filepath.Join(manager.config.PackagesFolder, name+".yaml"), &pkg)
 }
 
-func (manager *ManagerImpl) Outdated() error {
-	for _, pkg := range manager.Packages {
+// Outdated returns all installed packages with a newer version sorted by name.
+func (manager *ManagerImpl) Outdated(ctx context.Context) ([]OutdatedEntry, error) {
+	entries := []OutdatedEntry{}
+	for _, name := range manager.sortedPackageNames() {
+		pkg := manager.Packages[name]
 		logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
 		currentVersion, ok := manager.StateFile.Packages[pkg.Name]
 		if !ok || currentVersion == "" {
@@ -213,77 +266,102 @@ func (manager *ManagerImpl) Outdated() error {
 		}
 		provider, ok := manager.Providers[pkg.Provider]
 		if !ok {
-			return fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+			return entries, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
 		}
-		version, err := provider.GetLatest(pkg)
-		if err != nil {
-			return err
+		selection, err := manager.selectRelease(ctx, provider, pkg, currentVersion)
+		if errors.Is(err, ErrNoEligibleRelease) {
+			// only releases which are too young or not allowed, the installed version stays the latest
+			selection.Version = currentVersion
+		} else if err != nil {
+			return entries, err
+		}
+		entry := OutdatedEntry{
+			Name:    pkg.Name,
+			Current: currentVersion,
+			Latest:  selection.Version,
+		}
+		if selection.HeldBack != nil && selection.HeldBack.Version != currentVersion {
+			eligibleAt := selection.HeldBack.Published.Add(pkg.minReleaseAge(manager.config))
+			entry.HeldBack = selection.HeldBack.Version
+			entry.EligibleAt = &eligibleAt
 		}
-		if version == currentVersion {
+		if selection.Blocked != nil && selection.Blocked.Version != currentVersion {
+			entry.Blocked = selection.Blocked.Version
+		}
+		if entry.HeldBack == "" && entry.Blocked == "" && selection.Version == currentVersion {
 			continue
 		}
-		logger.Info().Msgf("find package version %s", version)
-		fmt.Fprintf(manager.stdout, "%s: %s => %s\n", pkg.Name, currentVersion, version)
+		logger.Info().Msgf("find package version %s", selection.Version)
+		entries = append(entries, entry)
 	}
-	return nil
+	return entries, nil
 }
 
-func (manager *ManagerImpl) Install(name string, force bool) (err error) {
+// Install installs the latest version of the package or the version given as name@version.
+// Installed packages are only reinstalled with force or if another version is requested.
+func (manager *ManagerImpl) Install(ctx context.Context, name string, force bool) (result InstallResult, err error) {
+	name, requested, _ := strings.Cut(name, "@")
+	result.Name = name
 	pkg, ok := manager.Packages[name]
 	if !ok {
-		return fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+		return result, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+	if requested != "" && !validVersion(requested) {
+		return result, fmt.Errorf("%w: invalid version %q of %s", ErrInvalidInput, requested, name)
 	}
 	provider, ok := manager.Providers[pkg.Provider]
 	if !ok {
-		return fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+		return result, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
 	}
 	currentVersion, ok := manager.StateFile.Packages[name]
+	result.PreviousVersion = currentVersion
 	var version string
-	if !ok || currentVersion == "" {
-		version, err = provider.GetLatest(pkg)
+	reinstall := force
+	if requested != "" {
+		version = requested
+		// another version than the installed one is installed without force
+		reinstall = force || requested != currentVersion
+	} else if !ok || currentVersion == "" || force {
+		// an explicit install ignores the update policy
+		selection, err := manager.selectRelease(ctx, provider, pkg, "")
 		if err != nil {
-			return err
+			return result, err
 		}
+		version = selection.Version
 	}
 	manager.logger.Info().Msgf("find package version %s", version)
-	if currentVersion != "" && !force {
+	if currentVersion != "" && !reinstall {
 		manager.logger.Info().Msgf("version is already installed :)")
-		return nil
+		result.Version = currentVersion
+		return result, nil
+	}
+	if manager.config.DryRun {
+		return manager.planInstall(ctx, provider, &pkg, version, result)
 	}
 
 	manager.tmpDir, err = os.MkdirTemp("", "bpm-*")
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer func() {
 		os.RemoveAll(manager.tmpDir)
 		manager.tmpDir = ""
 	}()
 
-	var path string
-	if pkg.DownloadURL != "" {
-		path, err = manager.FetchFromDownloadURL(pkg, version, manager.tmpDir)
-	} else {
-		path, err = provider.FetchPackage(pkg, version, manager.tmpDir)
-	}
+	path, err := manager.fetchBinary(ctx, provider, &pkg, version)
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	if pkg.ArchiveFormat != "" {
-		path, err = manager.extractPackage(&pkg, version, path)
-		if err != nil {
-			return err
-		}
-	}
-
-	err = manager.install(&pkg, version, path)
+	err = manager.installPackage(ctx, &pkg, version, path, force)
 	if err != nil {
-		return nil
+		return result, err
 	}
 
 	manager.StateFile.Packages[name] = version
-	return nil
+	result.Version = version
+	result.Changed = true
+	return result, nil
 }
 
 func (manager *ManagerImpl) inPackageList(pkgName string, pkgNames []string) bool {
@@ -295,33 +373,70 @@ func (manager *ManagerImpl) inPackageList(pkgName string, pkgNames []string) boo
 	return false
 }
 
-func (manager *ManagerImpl) Update(packageNames []string) (err error) {
-	var selectedPackages []Package
-	if len(packageNames) > 0 {
-		for _, pkg := range manager.Packages {
-			if manager.inPackageList(pkg.Name, packageNames) {
-				selectedPackages = append(selectedPackages, pkg)
-			}
+// Update updates the given installed packages (all if no names are given).
+// Packages that cannot be updated are skipped, their results contain the error
+// and an *UpdateError with all failures is returned. The results are sorted by name.
+// If the context is cancelled the update stops and the results of the finished packages are returned.
+func (manager *ManagerImpl) Update(ctx context.Context, packageNames []string) ([]InstallResult, error) {
+	results := []InstallResult{}
+	updateErr := &UpdateError{}
+	for _, name := range manager.sortedPackageNames() {
+		// stop on cancellation, the results contain the already updated packages
+		if err := ctx.Err(); err != nil {
+			return results, err
 		}
-	} else {
-		for _, pkg := range manager.Packages {
-			selectedPackages = append(selectedPackages, pkg)
+		pkg := manager.Packages[name]
+		if len(packageNames) > 0 && !manager.inPackageList(pkg.Name, packageNames) {
+			continue
 		}
-	}
-	for _, pkg := range selectedPackages {
-		err := manager.update(&pkg)
-		if err != nil {
-			logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
+		logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
+		currentVersion, ok := manager.StateFile.Packages[pkg.Name]
+		if !ok || currentVersion == "" {
+			logger.Info().Msg("package is not installed")
+			continue
+		}
+		result, err := manager.update(ctx, &pkg, currentVersion)
+		if err != nil && ctx.Err() != nil {
+			// the package was interrupted, finished packages are returned by the check above
+			return results, ctx.Err()
+		} else if err != nil {
 			logger.Error().Msgf("cannot update package: %s. Skipping...", err)
+			result.Error = err.Error()
+			updateErr.Failed = append(updateErr.Failed, &PackageError{Name: pkg.Name, Err: err})
+		} else {
+			updateErr.Succeeded++
+			if result.Changed {
+				updateErr.Changed++
+			}
 		}
+		results = append(results, result)
 	}
-	return nil
+	if len(updateErr.Failed) > 0 {
+		return results, updateErr
+	}
+	return results, nil
 }
 
-func (manager *ManagerImpl) FetchFromDownloadURL(pkg Package, version string, cacheDir string) (path string, err error) {
-	url := pkg.patternExpand(pkg.DownloadURL, version)
-
-	resp, err := http.DefaultClient.Get(url)
+func (manager *ManagerImpl) FetchFromDownloadURL(ctx context.Context, pkg Package, version string, cacheDir string) (path string, err error) {
+	var resp *http.Response
+	// try the host libc first and the fallbacks of the package afterwards
+	for _, url := range pkg.patternCandidates(pkg.DownloadURL, version) {
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return path, err
+		}
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			return path, err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			break
+		}
+		resp.Body.Close()
+		err = fmt.Errorf("%w: %s returned %s", ErrProviderFetch, url, resp.Status)
+		manager.logger.Debug().Msgf("cannot fetch %s: %s", url, resp.Status)
+	}
 	if err != nil {
 		return path, err
 	}
@@ -338,76 +453,103 @@ func (manager *ManagerImpl) FetchFromDownloadURL(pkg Package, version string, ca
 	if err != nil {
 		return path, err
 	}
-	defer file.Close()
 	_, err = io.Copy(file, resp.Body)
+	file.Close()
+	if err != nil {
+		// do not leave partial downloads behind (e.g. after cancellation)
+		os.Remove(path)
+		return path, err
+	}
+	return path, nil
+}
+
+// fetchBinary downloads the version of the package into the tmp dir and extracts the binary if needed.
+func (manager *ManagerImpl) fetchBinary(ctx context.Context, provider PackageProvider, pkg *Package, version string) (path string, err error) {
+	if pkg.DownloadURL != "" {
+		path, err = manager.FetchFromDownloadURL(ctx, *pkg, version, manager.tmpDir)
+	} else {
+		path, err = provider.FetchPackage(ctx, *pkg, version, manager.tmpDir)
+	}
 	if err != nil {
 		return path, err
 	}
+	if pkg.ArchiveFormat != "" {
+		return manager.extractPackage(pkg, version, path)
+	}
 	return path, nil
 }
 
-func (manager *ManagerImpl) update(pkg *Package) (err error) {
+func (manager *ManagerImpl) update(ctx context.Context, pkg *Package, currentVersion string) (result InstallResult, err error) {
+	result = InstallResult{
+		Name:            pkg.Name,
+		PreviousVersion: currentVersion,
+		Version:         currentVersion,
+	}
 	logger := manager.logger.With().Str("pkg", pkg.Name).Logger()
 	provider, ok := manager.Providers[pkg.Provider]
 	if !ok {
-		return fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+		return result, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
 	}
-	currentVersion, ok := manager.StateFile.Packages[pkg.Name]
-	if !ok || currentVersion == "" {
-		logger.Info().Msg("package is not installed")
-		return nil
-	}
-	version, err := provider.GetLatest(*pkg)
-	if err != nil {
-		return err
+	selection, err := manager.selectRelease(ctx, provider, *pkg, currentVersion)
+	if errors.Is(err, ErrNoEligibleRelease) {
+		logger.Info().Msgf("no update: %s", err)
+		return result, nil
+	} else if err != nil {
+		return result, err
 	}
+	version := selection.Version
 	logger.Info().Msgf("find package version %s", version)
 	if version == currentVersion {
 		logger.Info().Msgf("version is up to date :)")
-		return nil
+		return result, nil
 	}
-
-	if !manager.config.Quiet {
-		fmt.Printf("%s %s => %s\n", pkg.Name, currentVersion, version)
+	if manager.config.DryRun {
+		return manager.planInstall(ctx, provider, pkg, version, result)
 	}
 
 	manager.tmpDir, err = os.MkdirTemp("", "bpm-*")
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer func() {
 		os.RemoveAll(manager.tmpDir)
 		manager.tmpDir = ""
 	}()
-	var path string
-	if pkg.DownloadURL != "" {
-		path, err = manager.FetchFromDownloadURL(*pkg, version, manager.tmpDir)
-	} else {
-		path, err = provider.FetchPackage(*pkg, version, manager.tmpDir)
-	}
+	path, err := manager.fetchBinary(ctx, provider, pkg, version)
 	if err != nil {
-		return err
-	}
-
-	if pkg.ArchiveFormat != "" {
-		path, err = manager.extractPackage(pkg, version, path)
-		if err != nil {
-			return err
-		}
+		return result, err
 	}
 
-	err = manager.install(pkg, version, path)
+	err = manager.installPackage(ctx, pkg, version, path, false)
 	if err != nil {
-		return nil
+		return result, err
 	}
 
 	manager.StateFile.Packages[pkg.Name] = version
-	return nil
+	result.Version = version
+	result.Changed = true
+	return result, nil
 }
 
-func (manager *ManagerImpl) install(pkg *Package, version string, sourceFile string) error {
-	targetFile := filepath.Join(manager.config.BinFolder, pkg.Name)
+// install copies the file into the bin folder and activates it.
+// The file is checked to be executable on this platform before, mismatches are ignored with force.
+func (manager *ManagerImpl) install(pkg *Package, version string, sourceFile string, force bool) error {
+	targetFile := manager.installTarget(pkg, version)
 	manager.logger.Debug().Msgf("install file %s to %s", sourceFile, targetFile)
+	err := os.MkdirAll(filepath.Dir(targetFile), 0o755)
+	if err != nil {
+		return err
+	}
+	targetPathWithVersion := filepath.Join(filepath.Dir(targetFile), fmt.Sprintf("%s-%s", pkg.Name, version))
+	err = manager.copyBinary(pkg, sourceFile, targetPathWithVersion, targetFile, force)
+	if err != nil {
+		return err
+	}
+	return manager.recordChecksum(pkg.Name, targetFile)
+}
+
+// copyBinary copies the file to tmpFile, makes it executable, verifies it and renames it to targetFile.
+func (manager *ManagerImpl) copyBinary(pkg *Package, sourceFile string, tmpFile string, targetFile string, force bool) error {
 	// first copy the new file to target file
 	inputFile, err := os.Open(sourceFile)
 	if err != nil {
@@ -416,31 +558,31 @@ func (manager *ManagerImpl) install(pkg *Package, version string, sourceFile str
 	defer func() {
 		inputFile.Close()
 	}()
-	targetPathWithVersion := filepath.Join(manager.config.BinFolder, fmt.Sprintf("%s-%s", pkg.Name, version))
-	outputFile, err := os.Create(targetPathWithVersion)
+	outputFile, err := os.Create(tmpFile)
 	if err != nil {
 		return err
 	}
 	_, err = io.Copy(outputFile, inputFile)
 	outputFile.Close()
 	if err != nil {
-		os.Remove(targetPathWithVersion)
+		os.Remove(tmpFile)
 		return err
 	}
 	// make it executable
-	err = os.Chmod(targetPathWithVersion, 0o755)
+	err = os.Chmod(tmpFile, 0o755)
 	if err != nil {
-		os.Remove(targetPathWithVersion)
+		os.Remove(tmpFile)
 		return err
 	}
 
-	// then we can rename the file
-	err = os.Rename(targetPathWithVersion, targetFile)
+	err = manager.verifyBinary(pkg, tmpFile, force)
 	if err != nil {
+		os.Remove(tmpFile)
 		return err
 	}
 
-	return nil
+	// then we can rename the file
+	return os.Rename(tmpFile, targetFile)
 }
 
 func (manager *ManagerImpl) extractPackage(pkg *Package, version string, sourceFile string) (string, error) {
@@ -454,8 +596,12 @@ func (manager *ManagerImpl) extractPackage(pkg *Package, version string, sourceF
 		return manager.extractTarXZ(pkg, version, sourceFile)
 	case "zip":
 		return manager.extractZip(pkg, version, sourceFile)
+	case "deb":
+		return manager.extractDeb(pkg, version, sourceFile)
+	case "rpm":
+		return manager.extractRpm(pkg, version, sourceFile)
 	default:
-		return "", fmt.Errorf("unknown archive format %s", pkg.ArchiveFormat)
+		return "", fmt.Errorf("%w: %s", ErrArchiveFormat, pkg.ArchiveFormat)
 	}
 }
 
@@ -469,38 +615,21 @@ func (manager *ManagerImpl) extractTar(pkg *Package, version string, sourcePath
 }
 
 func (manager *ManagerImpl) extractTarReader(pkg *Package, version string, reader io.Reader) (string, error) {
-	tarReader := tar.NewReader(reader)
-	var outputPath string
+	return manager.extractMatching(pkg, version, tarWalker(reader, manager.config.Extract))
+}
+
+// extractMatching writes the binary matching the bin pattern of the package into the tmp dir.
+func (manager *ManagerImpl) extractMatching(pkg *Package, version string, walk archiveWalkFunc) (string, error) {
 	binPattern, err := regexp.Compile(pkg.patternExpand(pkg.BinPattern, version))
 	if err != nil {
-		return outputPath, err
+		return "", err
 	}
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			return outputPath, fmt.Errorf("archive does not contain a file matchin pattern %s", pkg.BinPattern)
-		} else if err != nil {
-			return outputPath, err
-		}
-
-		// search for regular files
-		if header.Typeflag != tar.TypeReg {
-			continue
-		}
-
-		name := strings.ToLower(header.Name)
-		if binPattern.Match([]byte(name)) {
-			outputPath = filepath.Join(manager.tmpDir, fmt.Sprintf("output-%s", pkg.Name))
-			file, err := os.Create(outputPath)
-			if err != nil {
-				return outputPath, err
-			}
-			defer file.Close()
-			_, err = io.Copy(file, tarReader)
-			return outputPath, err
-		}
+	outputPath := filepath.Join(manager.tmpDir, fmt.Sprintf("output-%s", pkg.Name))
+	err = extractMatchingFile(walk, binPattern, outputPath)
+	if err != nil {
+		return outputPath, err
 	}
+	return outputPath, nil
 }
 
 func (manager *ManagerImpl) extractTarXZ(pkg *Package, version string, sourceFile string) (string, error) {
@@ -533,37 +662,13 @@ func (manager *ManagerImpl) extractTarGZ(pkg *Package, version string, sourceFil
 }
 
 func (manager *ManagerImpl) extractZip(pkg *Package, version string, sourceFile string) (string, error) {
-	var outputPath string
-	binPattern, err := regexp.Compile(pkg.patternExpand(pkg.BinPattern, version))
-	if err != nil {
-		return outputPath, err
-	}
-
 	reader, err := zip.OpenReader(sourceFile)
 	if err != nil {
-		return outputPath, err
+		return "", err
 	}
 	defer reader.Close()
-	for _, file := range reader.File {
-		name := strings.ToLower(file.Name)
-		if file.FileInfo().Mode().IsRegular() && binPattern.Match([]byte(name)) {
-			outputPath = filepath.Join(manager.tmpDir, fmt.Sprintf("output-%s", pkg.Name))
-			outputFile, err := os.Create(outputPath)
-			if err != nil {
-				return outputPath, err
-			}
-			defer outputFile.Close()
-			zipFile, err := file.Open()
-			if err != nil {
-				return outputPath, err
-			}
-			defer zipFile.Close()
-			_, err = io.Copy(outputFile, zipFile)
-			return outputPath, err
-		}
-	}
 
-	return outputPath, fmt.Errorf("archive does not contain a file matching pattern %s", pkg.BinPattern)
+	return manager.extractMatching(pkg, version, zipWalker(&reader.Reader, manager.config.Extract))
 }
 
 func (manager *ManagerImpl) migrateStateFile() error {
@@ -674,19 +779,42 @@ func (manager *ManagerImpl) Migrate() error {
 	return manager.LoadState()
 }
 
-func (manager *ManagerImpl) Remove(pkgname string) error {
-	_, ok := manager.StateFile.Packages[pkgname]
+// Remove removes the binary and all additional files of the package.
+// The result contains the removed files (or the files to remove in dry run mode).
+func (manager *ManagerImpl) Remove(ctx context.Context, pkgname string) (RemoveResult, error) {
+	version, ok := manager.StateFile.Packages[pkgname]
 	if !ok {
-		return fmt.Errorf("%w: %s", ErrPackageNotInstalled, pkgname)
+		return RemoveResult{Name: pkgname}, fmt.Errorf("%w: %s", ErrPackageNotInstalled, pkgname)
+	}
+	binPath := path.Join(manager.config.BinFolder, pkgname)
+	result := RemoveResult{
+		Name:    pkgname,
+		Version: version,
+		Files:   append([]string{binPath}, manager.StateFile.Files[pkgname]...),
+	}
+	if manager.config.DryRun {
+		return result, nil
 	}
 
-	err := os.Remove(path.Join(manager.config.BinFolder, pkgname))
+	err := os.Remove(binPath)
 	if os.IsNotExist(err) {
-		delete(manager.StateFile.Packages, pkgname)
-		return fmt.Errorf("%w: %s %s", ErrPackageRemove, pkgname, " does not exist in binary folder. Delete entry from state file")
+		manager.forgetPackage(pkgname)
+		return result, fmt.Errorf("%w: %s %s", ErrPackageRemove, pkgname, " does not exist in binary folder. Delete entry from state file")
 	} else if err != nil {
-		return fmt.Errorf("%w: %s: %s", ErrPackageRemove, pkgname, err)
+		return result, fmt.Errorf("%w: %s: %s", ErrPackageRemove, pkgname, err)
 	}
 
-	return nil
+	manager.forgetPackage(pkgname)
+	return result, nil
+}
+
+// forgetPackage removes the installed files and all state entries of the package.
+// The versions of shimmed packages are kept in the store for projects.
+func (manager *ManagerImpl) forgetPackage(pkgname string) {
+	manager.removePackageFiles(pkgname)
+	delete(manager.StateFile.Packages, pkgname)
+	delete(manager.StateFile.Checksums, pkgname)
+	manager.StateFile.Shims = slices.DeleteFunc(manager.StateFile.Shims, func(shim string) bool {
+		return shim == pkgname
+	})
 }
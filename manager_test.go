@@ -2,8 +2,11 @@ package bpm
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"testing"
@@ -55,8 +58,11 @@ func generateTestConfig(t *testing.T) (string, *Config, *StateFile) {
 		StateFolder:    testDir,
 		PackagesFolder: path.Join(testDir, "packages"),
 		BinFolder:      path.Join(testDir, "bin"),
+		DataFolder:     path.Join(testDir, "data"),
+		StoreFolder:    path.Join(testDir, "store"),
 	}
 	configPath := writeTestConfig(t, config)
+	config.Path = configPath
 
 	return configPath, config, state
 }
@@ -74,14 +80,86 @@ func getDummyManagerImpl(t *testing.T) *ManagerImpl {
 	return manager
 }
 
+// testManagerOptions configures the manager of getTestManager.
+type testManagerOptions struct {
+	// packages of the manager, defaults to the dummy package
+	packages []Package
+	// provider of the packages, registered as the dummy provider
+	provider PackageProvider
+	// versions of the state by package name
+	state map[string]string
+	// packages installed with Install
+	install []string
+	// yaml decoded into the config
+	config string
+	// templates folder files by template name
+	templates  map[string]string
+	registries []RegistryConfig
+	dryRun     bool
+}
+
+// getTestManager returns a manager with a state configured by options.
+func getTestManager(t *testing.T, options testManagerOptions) *ManagerImpl {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	if options.config != "" {
+		assert.NoError(t, yaml.Unmarshal([]byte(options.config), manager.config))
+	}
+	if len(options.templates) > 0 {
+		assert.NoError(t, os.MkdirAll(manager.templatesFolder(), 0o755))
+		for name, content := range options.templates {
+			assert.NoError(t, os.WriteFile(path.Join(manager.templatesFolder(), name+".yaml"), []byte(content), 0o644))
+		}
+	}
+	if options.registries != nil {
+		manager.config.Registries = options.registries
+	}
+	packages := options.packages
+	if packages == nil {
+		packages = []Package{*dummyPackage()}
+	}
+	for _, pkg := range packages {
+		manager.Packages[pkg.Name] = pkg
+	}
+	if options.provider != nil {
+		manager.Providers[dummyProviderName] = options.provider
+	}
+	for _, name := range options.install {
+		_, err := manager.Install(context.Background(), name, false)
+		assert.NoError(t, err)
+	}
+	for name, version := range options.state {
+		manager.StateFile.Packages[name] = version
+	}
+	manager.config.DryRun = options.dryRun
+	return manager
+}
+
+// dummyBinProvider returns a provider which fetches the dummy binary for the dummy package.
+// The latest version is only set if latest is not empty.
+func dummyBinProvider(latest string) *DummyProvider {
+	name := dummyPackage().Name
+	provider := &DummyProvider{
+		FetchPackages: map[string]string{name: getTestPath("files", "dummy-bin.sh")},
+	}
+	if latest != "" {
+		provider.LatestPackages = map[string]string{name: latest}
+	}
+	return provider
+}
+
 type DummyProvider struct {
 	// name: version
 	LatestPackages map[string]string
 	// name: path-to-file
 	FetchPackages map[string]string
+	// name: asset names of the latest release
+	Assets map[string][]string
+	// name: release notes of all releases, newest first
+	Notes map[string][]ReleaseNote
 }
 
-func (provider *DummyProvider) GetLatest(pkg Package) (version string, err error) {
+func (provider *DummyProvider) GetLatest(ctx context.Context, pkg Package) (version string, err error) {
 	if provider.LatestPackages != nil {
 		if version, ok := provider.LatestPackages[pkg.Name]; ok {
 			return version, nil
@@ -90,7 +168,50 @@ func (provider *DummyProvider) GetLatest(pkg Package) (version string, err error
 	return "", ErrProviderFetch
 }
 
-func (provider *DummyProvider) FetchPackage(pkg Package, version string, cacheDir string) (outPath string, err error) {
+func (provider *DummyProvider) GetLatestAssets(ctx context.Context, pkg Package) (version string, assets []string, err error) {
+	if provider.Assets != nil {
+		if assets, ok := provider.Assets[pkg.Name]; ok {
+			version, err := provider.GetLatest(ctx, pkg)
+			return version, assets, err
+		}
+	}
+	return "", nil, ErrProviderFetch
+}
+
+func (provider *DummyProvider) GetReleaseNotes(ctx context.Context, pkg Package, from string, to string) ([]ReleaseNote, error) {
+	notes, ok := provider.Notes[pkg.Name]
+	if !ok {
+		return nil, ErrProviderFetch
+	}
+	tags := []string{}
+	for i := len(notes) - 1; i >= 0; i-- {
+		tags = append(tags, notes[i].Version)
+	}
+	between, err := releasesBetween(tags, from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := []ReleaseNote{}
+	for _, tag := range between {
+		for _, note := range notes {
+			if note.Version == tag {
+				result = append(result, note)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (provider *DummyProvider) GetDownloadURL(ctx context.Context, pkg Package, version string) (url string, err error) {
+	if provider.FetchPackages != nil {
+		if inPath, ok := provider.FetchPackages[pkg.Name]; ok {
+			return "file://" + inPath, nil
+		}
+	}
+	return "", ErrProviderFetch
+}
+
+func (provider *DummyProvider) FetchPackage(ctx context.Context, pkg Package, version string, cacheDir string) (outPath string, err error) {
 	if provider.FetchPackages != nil {
 		if inPath, ok := provider.FetchPackages[pkg.Name]; ok {
 			inFile, err := os.Open(inPath)
@@ -115,7 +236,7 @@ func TestNewManager(t *testing.T) {
 	logger := getDummyLogger()
 	configPath, config, state := generateTestConfig(t)
 	t.Run("default", func(t *testing.T) {
-		manager, err := NewManager(configPath, logger, false)
+		manager, err := NewManager(configPath, logger, ManagerOptions{})
 		if assert.NoError(t, err) {
 			managerReal := manager.(*ManagerImpl)
 			assert.EqualValues(t, manager.Config(), config)
@@ -129,7 +250,7 @@ func TestNewManager(t *testing.T) {
 	t.Run("with-package", func(t *testing.T) {
 		pkg := dummyPackage()
 		dumpYaml(path.Join(config.PackagesFolder, "test.yaml"), &pkg)
-		manager, err := NewManager(configPath, logger, false)
+		manager, err := NewManager(configPath, logger, ManagerOptions{})
 		if assert.NoError(t, err) {
 			managerReal := manager.(*ManagerImpl)
 			assert.Contains(t, managerReal.Packages, pkg.Name)
@@ -137,9 +258,35 @@ func TestNewManager(t *testing.T) {
 		}
 	})
 
+	t.Run("broken-package", func(t *testing.T) {
+		brokenPath := path.Join(config.PackagesFolder, "broken.yaml")
+		err := os.WriteFile(brokenPath, []byte("name: [broken\n"), 0o644)
+		assert.NoError(t, err)
+		defer os.Remove(brokenPath)
+		manager, err := NewManager(configPath, logger, ManagerOptions{})
+		assert.ErrorIs(t, err, ErrManagerCreate)
+		assert.ErrorIs(t, err, ErrPackageFiles)
+		assert.ErrorContains(t, err, brokenPath)
+		assert.Contains(t, manager.(*ManagerImpl).Packages, dummyPackage().Name, "the other packages are loaded")
+	})
+
+	t.Run("state-only", func(t *testing.T) {
+		brokenPath := path.Join(config.PackagesFolder, "broken.yaml")
+		err := os.WriteFile(brokenPath, []byte("name: [broken\n"), 0o644)
+		assert.NoError(t, err)
+		defer os.Remove(brokenPath)
+		manager, err := NewManager(configPath, logger, ManagerOptions{StateOnly: true})
+		if assert.NoError(t, err, "package files are not loaded") {
+			managerReal := manager.(*ManagerImpl)
+			assert.EqualValues(t, state, managerReal.StateFile)
+			assert.Empty(t, managerReal.Providers)
+			assert.Empty(t, managerReal.Packages)
+		}
+	})
+
 	t.Run("missing-config", func(t *testing.T) {
 		configPath := "/tmp/missing-config"
-		_, err := NewManager(configPath, logger, false)
+		_, err := NewManager(configPath, logger, ManagerOptions{})
 		assert.ErrorIs(t, err, ErrManagerCreate)
 	})
 
@@ -153,7 +300,7 @@ func TestNewManager(t *testing.T) {
 			StateFolder: tmpFile,
 		}
 		configPath := writeTestConfig(t, config)
-		_, err = NewManager(configPath, logger, false)
+		_, err = NewManager(configPath, logger, ManagerOptions{})
 		assert.ErrorIs(t, err, ErrManagerCreate)
 	})
 
@@ -165,9 +312,9 @@ func TestNewManager(t *testing.T) {
 		config := getTestTmpDirConfig(t)
 		config.StateFolder = path.Dir(statePath)
 		configPath := writeTestConfig(t, config)
-		_, err := NewManager(configPath, logger, false)
+		_, err := NewManager(configPath, logger, ManagerOptions{})
 		assert.ErrorIs(t, err, ErrManagerCreate)
-		_, err = NewManager(configPath, logger, true)
+		_, err = NewManager(configPath, logger, ManagerOptions{Migrate: true})
 		assert.NoError(t, err, "in migration mode the LoadState function should not be called")
 	})
 }
@@ -227,19 +374,13 @@ func TestManagerSaveState(t *testing.T) {
 
 func TestManagerInfo(t *testing.T) {
 	testPkg := *dummyPackage()
-	var testPkgString bytes.Buffer
-	encoder := yaml.NewEncoder(&testPkgString)
-	err := encoder.Encode(&testPkg)
-	if err != nil {
-		t.Fatalf("cannot create dummy package output: %s", err)
-	}
 	tests := []outputTest{
 		{
 			name:        "missing",
 			packageName: "missing",
 			pkg:         &testPkg,
 			state:       getDummyState(),
-			output:      "",
+			result:      PackageInfo{},
 			err:         ErrPackageNotFound,
 		},
 		{
@@ -247,7 +388,7 @@ func TestManagerInfo(t *testing.T) {
 			pkg:         &testPkg,
 			packageName: testPkg.Name,
 			state:       getDummyState(),
-			output:      fmt.Sprintf("%sversion: not installed\n", testPkgString.String()),
+			result:      PackageInfo{Package: testPkg},
 			err:         nil,
 		},
 		{
@@ -259,13 +400,15 @@ func TestManagerInfo(t *testing.T) {
 				state.Packages[testPkg.Name] = "v1.0.0"
 				return state
 			}(),
-			output: fmt.Sprintf("%sversion: v1.0.0\n", testPkgString.String()),
+			result: PackageInfo{Package: testPkg, Version: "v1.0.0"},
 			err:    nil,
 		},
 	}
 
 	runOutputTests(t, tests, func(t *testing.T, test *outputTest, manager *ManagerImpl) error {
-		return manager.Info(test.packageName)
+		info, err := manager.Info(context.Background(), test.packageName)
+		assert.Equal(t, test.result, info)
+		return err
 	})
 }
 
@@ -277,11 +420,11 @@ func TestManagerList(t *testing.T) {
 			err:      nil,
 			state:    getDummyState(),
 			provider: &DummyProvider{},
-			output:   "",
+			result:   []PackageStatus{},
 		},
 		{
 			name:   "one-package",
-			output: fmt.Sprintf("- %s\n", dummyPackage().Name),
+			result: []PackageStatus{{Name: dummyPackage().Name, Provider: dummyProviderName}},
 			state:  getDummyState(),
 			err:    nil,
 			pkg:    dummyPackage(),
@@ -289,10 +432,28 @@ func TestManagerList(t *testing.T) {
 	}
 
 	runOutputTests(t, tests, func(t *testing.T, test *outputTest, manager *ManagerImpl) error {
-		return manager.List()
+		packages, err := manager.List(context.Background())
+		assert.Equal(t, test.result, packages)
+		return err
 	})
 }
 
+func TestManagerListSorted(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	manager.StateFile.Packages["b"] = "v1.0.0"
+	for _, name := range []string{"c", "a", "b"} {
+		manager.Packages[name] = Package{PackageV2: PackageV2{Name: name, Provider: dummyProviderName}}
+	}
+	packages, err := manager.List(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []PackageStatus{
+		{Name: "a", Provider: dummyProviderName},
+		{Name: "b", Provider: dummyProviderName, Version: "v1.0.0", Installed: true},
+		{Name: "c", Provider: dummyProviderName},
+	}, packages)
+}
+
 func TestManagerInstalled(t *testing.T) {
 	tests := []outputTest{
 		{
@@ -301,21 +462,25 @@ func TestManagerInstalled(t *testing.T) {
 			err:      nil,
 			state:    getDummyState(),
 			provider: &DummyProvider{},
-			output:   "",
+			result:   []PackageStatus{},
 		},
 		{
 			name:   "not-installed",
-			output: "",
+			result: []PackageStatus{},
 			state:  getDummyState(),
 			err:    nil,
 			pkg:    dummyPackage(),
 		},
 		{
-			name:   "installed",
-			output: fmt.Sprintf("%s - v1.0.0\n", dummyPackage().Name),
+			name: "installed",
+			result: []PackageStatus{
+				{Name: "orphan", Version: "v0.1.0", Installed: true},
+				{Name: dummyPackage().Name, Provider: dummyProviderName, Version: "v1.0.0", Installed: true},
+			},
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
+				state.Packages["orphan"] = "v0.1.0"
 				return state
 			}(),
 			err: nil,
@@ -324,7 +489,9 @@ func TestManagerInstalled(t *testing.T) {
 	}
 
 	runOutputTests(t, tests, func(t *testing.T, test *outputTest, manager *ManagerImpl) error {
-		return manager.Installed()
+		packages, err := manager.Installed(context.Background())
+		assert.Equal(t, test.result, packages)
+		return err
 	})
 }
 
@@ -487,6 +654,50 @@ func TestManagerInstall(t *testing.T) {
 				return pkg
 			}(),
 		},
+		{
+			name:        "not-installed-deb",
+			packageName: dummyPackage().Name,
+			output:      "",
+			state:       getDummyState(),
+			err:         nil,
+			provider: &DummyProvider{
+				LatestPackages: map[string]string{
+					dummyPackage().Name: "v1.0.0",
+				},
+				FetchPackages: map[string]string{
+					dummyPackage().Name: getTestPath("files", "dummy-bin.sh.deb"),
+				},
+			},
+			installed: setBoolPointer(true),
+			pkg: func() *Package {
+				pkg := dummyPackage()
+				pkg.ArchiveFormat = "deb"
+				pkg.BinPattern = "(^|/)usr/bin/dummy-bin.sh$"
+				return pkg
+			}(),
+		},
+		{
+			name:        "not-installed-rpm",
+			packageName: dummyPackage().Name,
+			output:      "",
+			state:       getDummyState(),
+			err:         nil,
+			provider: &DummyProvider{
+				LatestPackages: map[string]string{
+					dummyPackage().Name: "v1.0.0",
+				},
+				FetchPackages: map[string]string{
+					dummyPackage().Name: getTestPath("files", "dummy-bin.sh.rpm"),
+				},
+			},
+			installed: setBoolPointer(true),
+			pkg: func() *Package {
+				pkg := dummyPackage()
+				pkg.ArchiveFormat = "rpm"
+				pkg.BinPattern = "(^|/)usr/bin/dummy-bin.sh$"
+				return pkg
+			}(),
+		},
 		{
 			name:        "installed",
 			packageName: dummyPackage().Name,
@@ -507,8 +718,9 @@ func TestManagerInstall(t *testing.T) {
 	}
 
 	runOutputTests(t, tests, func(t *testing.T, test *outputTest, manager *ManagerImpl) error {
-		err := manager.Install(test.packageName, false)
+		result, err := manager.Install(context.Background(), test.packageName, false)
 		if assert.ErrorIs(t, err, test.err) {
+			assert.Equal(t, test.packageName, result.Name)
 			if test.installed != nil {
 				if *test.installed {
 					assert.FileExists(t, path.Join(manager.config.BinFolder, test.packageName))
@@ -526,7 +738,7 @@ func TestManagerAdd(t *testing.T) {
 	pkgName := "testPkg"
 	providerName := "dummy"
 	testURL := fmt.Sprintf("%s/test", providerName)
-	err := manager.Add(pkgName, testURL)
+	err := manager.Add(context.Background(), pkgName, testURL, "", "")
 	assert.NoError(t, err, "add should not return an error")
 	pkgPath := path.Join(manager.config.PackagesFolder, fmt.Sprintf("%s.yaml", pkgName))
 	if assert.FileExists(t, pkgPath, "manager.Add should have created a package file") {
@@ -551,11 +763,11 @@ func TestManagerOutdated(t *testing.T) {
 			err:      nil,
 			state:    getDummyState(),
 			provider: &DummyProvider{},
-			output:   "",
+			result:   []OutdatedEntry{},
 		},
 		{
 			name:     "not-installed",
-			output:   "",
+			result:   []OutdatedEntry{},
 			state:    getDummyState(),
 			provider: &DummyProvider{},
 			err:      nil,
@@ -563,7 +775,7 @@ func TestManagerOutdated(t *testing.T) {
 		},
 		{
 			name:   "installed-not-outdated",
-			output: "",
+			result: []OutdatedEntry{},
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
@@ -581,7 +793,7 @@ func TestManagerOutdated(t *testing.T) {
 		},
 		{
 			name:   "installed-outdated",
-			output: fmt.Sprintf("%s: v1.0.0 => v1.1.0\n", dummyPackage().Name),
+			result: []OutdatedEntry{{Name: dummyPackage().Name, Current: "v1.0.0", Latest: "v1.1.0"}},
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
@@ -599,7 +811,7 @@ func TestManagerOutdated(t *testing.T) {
 		},
 		{
 			name:   "installed-missing-provider",
-			output: "",
+			result: []OutdatedEntry{},
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
@@ -611,7 +823,7 @@ func TestManagerOutdated(t *testing.T) {
 		},
 		{
 			name:   "installed-error-fetch-provider",
-			output: "",
+			result: []OutdatedEntry{},
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
@@ -624,7 +836,9 @@ func TestManagerOutdated(t *testing.T) {
 	}
 
 	runOutputTests(t, tests, func(t *testing.T, test *outputTest, manager *ManagerImpl) error {
-		return manager.Outdated()
+		entries, err := manager.Outdated(context.Background())
+		assert.Equal(t, test.result, entries)
+		return err
 	})
 }
 
@@ -638,12 +852,13 @@ func TestManagerUpdate(t *testing.T) {
 			state:       getDummyState(),
 			provider:    &DummyProvider{},
 			output:      "",
+			result:      []InstallResult{},
 		},
 		{
 			name:        "specific-packages",
 			packageName: dummyPackage().Name,
 			pkg:         dummyPackage(),
-			err:         nil,
+			err:         ErrProviderFetch,
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
@@ -655,12 +870,15 @@ func TestManagerUpdate(t *testing.T) {
 				},
 			},
 			output: "",
+			result: []InstallResult{
+				{Name: dummyPackage().Name, PreviousVersion: "v1.0.0", Version: "v1.0.0", Error: ErrProviderFetch.Error()},
+			},
 		},
 		{
 			name:        "packages",
 			packageName: "",
 			pkg:         dummyPackage(),
-			err:         nil,
+			err:         ErrProviderFetch,
 			state: func() *StateFile {
 				state := getDummyState()
 				state.Packages[dummyPackage().Name] = "v1.0.0"
@@ -672,6 +890,52 @@ func TestManagerUpdate(t *testing.T) {
 				},
 			},
 			output: "",
+			result: []InstallResult{
+				{Name: dummyPackage().Name, PreviousVersion: "v1.0.0", Version: "v1.0.0", Error: ErrProviderFetch.Error()},
+			},
+		},
+		{
+			name:        "updated",
+			packageName: dummyPackage().Name,
+			pkg:         dummyPackage(),
+			err:         nil,
+			state: func() *StateFile {
+				state := getDummyState()
+				state.Packages[dummyPackage().Name] = "v1.0.0"
+				return state
+			}(),
+			provider: &DummyProvider{
+				LatestPackages: map[string]string{
+					dummyPackage().Name: "v1.1.0",
+				},
+				FetchPackages: map[string]string{
+					dummyPackage().Name: getTestPath("files", "dummy-bin.sh"),
+				},
+			},
+			output: "",
+			result: []InstallResult{
+				{Name: dummyPackage().Name, PreviousVersion: "v1.0.0", Version: "v1.1.0", Changed: true},
+			},
+		},
+		{
+			name:        "up-to-date",
+			packageName: "",
+			pkg:         dummyPackage(),
+			err:         nil,
+			state: func() *StateFile {
+				state := getDummyState()
+				state.Packages[dummyPackage().Name] = "v1.0.0"
+				return state
+			}(),
+			provider: &DummyProvider{
+				LatestPackages: map[string]string{
+					dummyPackage().Name: "v1.0.0",
+				},
+			},
+			output: "",
+			result: []InstallResult{
+				{Name: dummyPackage().Name, PreviousVersion: "v1.0.0", Version: "v1.0.0"},
+			},
 		},
 	}
 
@@ -685,11 +949,140 @@ func TestManagerUpdate(t *testing.T) {
 		if test.packageName != "" {
 			packages = append(packages, test.packageName)
 		}
-		err := manager.Update(packages)
+		results, err := manager.Update(context.Background(), packages)
+		assert.Equal(t, test.result, results)
 		return err
 	})
 }
 
+func TestManagerUpdatePartialFailure(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	for _, name := range []string{"broken", "working"} {
+		pkg := dummyPackage()
+		pkg.Name = name
+		manager.Packages[name] = *pkg
+		manager.StateFile.Packages[name] = "v1.0.0"
+	}
+	manager.Providers[dummyProviderName] = &DummyProvider{
+		LatestPackages: map[string]string{"broken": "v1.1.0", "working": "v1.1.0"},
+		FetchPackages:  map[string]string{"working": getTestPath("files", "dummy-bin.sh")},
+	}
+	results, err := manager.Update(context.Background(), nil)
+	var updateErr *UpdateError
+	if assert.ErrorAs(t, err, &updateErr) {
+		assert.True(t, updateErr.Partial())
+		assert.Equal(t, 1, updateErr.Succeeded)
+		assert.Equal(t, 1, updateErr.Changed)
+		if assert.Len(t, updateErr.Failed, 1) {
+			assert.Equal(t, "broken", updateErr.Failed[0].Name)
+		}
+	}
+	assert.ErrorIs(t, err, ErrProviderFetch)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, ErrProviderFetch.Error(), results[0].Error)
+		assert.True(t, results[1].Changed)
+	}
+	assert.Equal(t, "v1.1.0", manager.StateFile.Packages["working"])
+}
+
+func TestManagerUpdateFailureUpToDate(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	for _, name := range []string{"broken", "current"} {
+		pkg := dummyPackage()
+		pkg.Name = name
+		manager.Packages[name] = *pkg
+		manager.StateFile.Packages[name] = "v1.0.0"
+	}
+	manager.Providers[dummyProviderName] = &DummyProvider{
+		LatestPackages: map[string]string{"broken": "v1.1.0", "current": "v1.0.0"},
+	}
+	_, err := manager.Update(context.Background(), nil)
+	var updateErr *UpdateError
+	if assert.ErrorAs(t, err, &updateErr) {
+		assert.Equal(t, 1, updateErr.Succeeded)
+		assert.Equal(t, 0, updateErr.Changed)
+		assert.False(t, updateErr.Partial(), "packages which are up to date do not make a failed update partial")
+	}
+}
+
+func TestManagerUpdateCancelled(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	pkg := dummyPackage()
+	manager.Packages[pkg.Name] = *pkg
+	manager.StateFile.Packages[pkg.Name] = "v1.0.0"
+	manager.Providers[dummyProviderName] = &DummyProvider{
+		LatestPackages: map[string]string{pkg.Name: "v1.1.0"},
+		FetchPackages:  map[string]string{pkg.Name: getTestPath("files", "dummy-bin.sh")},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := manager.Update(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, results)
+	assert.Equal(t, "v1.0.0", manager.StateFile.Packages[pkg.Name], "cancelled update must not change the state")
+}
+
+// cancellingProvider cancels the context after fetching a package.
+type cancellingProvider struct {
+	*DummyProvider
+	cancel context.CancelFunc
+}
+
+func (provider *cancellingProvider) FetchPackage(ctx context.Context, pkg Package, version string, cacheDir string) (string, error) {
+	defer provider.cancel()
+	return provider.DummyProvider.FetchPackage(ctx, pkg, version, cacheDir)
+}
+
+func TestManagerUpdateCancelledAfterPackage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pkg := dummyPackage()
+	other := dummyPackage()
+	other.Name = "zOther"
+	provider := dummyBinProvider("v1.1.0")
+	provider.LatestPackages[other.Name] = "v1.1.0"
+	provider.FetchPackages[other.Name] = provider.FetchPackages[pkg.Name]
+	manager := getTestManager(t, testManagerOptions{
+		packages: []Package{*pkg, *other},
+		provider: &cancellingProvider{DummyProvider: provider, cancel: cancel},
+		state:    map[string]string{pkg.Name: "v1.0.0", other.Name: "v1.0.0"},
+	})
+	results, err := manager.Update(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	if assert.Len(t, results, 1, "the package finished before the cancellation is returned") {
+		assert.Equal(t, pkg.Name, results[0].Name)
+		assert.True(t, results[0].Changed)
+	}
+	assert.Equal(t, "v1.1.0", manager.StateFile.Packages[pkg.Name])
+	assert.Equal(t, "v1.0.0", manager.StateFile.Packages[other.Name])
+}
+
+func TestFetchFromDownloadURLCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "partial")
+		w.(http.Flusher).Flush()
+		// the client is cancelled in the middle of the download
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	manager := getDummyManagerImpl(t)
+	pkg := dummyPackage()
+	pkg.DownloadURL = server.URL + "/tool"
+	cacheDir := t.TempDir()
+	_, err := manager.FetchFromDownloadURL(ctx, *pkg, "v1.0.0", cacheDir)
+	assert.ErrorIs(t, err, context.Canceled)
+	entries, err := os.ReadDir(cacheDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "partial downloads must be removed")
+}
+
 func TestInPackageList(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -739,6 +1132,7 @@ type outputTest struct {
 	pkg         *Package
 	provider    PackageProvider
 	installed   *bool
+	result      any
 	err         error
 }
 
@@ -763,3 +1157,26 @@ func runOutputTests(t *testing.T, tests []outputTest, outputTestFunc outputTestF
 		})
 	}
 }
+
+func TestManagerInstallVersion(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyReleaseProvider()})
+	pkg := dummyPackage()
+	result, err := manager.Install(context.Background(), pkg.Name+"@v1.0.0", false)
+	assert.NoError(t, err)
+	assert.Equal(t, InstallResult{Name: pkg.Name, Version: "v1.0.0", Changed: true}, result)
+	assert.Equal(t, "v1.0.0", manager.StateFile.Packages[pkg.Name])
+	assert.FileExists(t, path.Join(manager.config.BinFolder, pkg.Name))
+
+	result, err = manager.Install(context.Background(), pkg.Name+"@v1.0.0", false)
+	assert.NoError(t, err)
+	assert.False(t, result.Changed, "the requested version is already installed")
+
+	result, err = manager.Install(context.Background(), pkg.Name+"@v1.1.0", false)
+	assert.NoError(t, err)
+	assert.Equal(t, InstallResult{Name: pkg.Name, Version: "v1.1.0", PreviousVersion: "v1.0.0", Changed: true}, result)
+
+	for _, version := range []string{"../v1", "..", "."} {
+		_, err = manager.Install(context.Background(), pkg.Name+"@"+version, false)
+		assert.ErrorIs(t, err, ErrInvalidInput, version)
+	}
+}
@@ -1,6 +1,7 @@
 package bpm
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
@@ -14,27 +15,44 @@ const (
 )
 
 type PackageProvider interface {
-	GetLatest(pkg Package) (version string, err error)
-	FetchPackage(pkg Package, version string, cacheDir string) (path string, err error)
+	GetLatest(ctx context.Context, pkg Package) (version string, err error)
+	FetchPackage(ctx context.Context, pkg Package, version string, cacheDir string) (path string, err error)
 }
 
 type Package struct {
 	PackageV2 `yaml:",inline"`
+	// name of the registry the package file comes from, empty for local packages
+	Registry string `yaml:"-" json:"registry,omitempty"`
 }
 
 type PackageV2 struct {
-	SchemaVersion int               `yaml:"schema_version" default:"1"`
-	Name          string            `yaml:"name"`
-	Provider      string            `yaml:"provider"`
-	URL           string            `yaml:"url"`
-	GOOS          map[string]string `yaml:"goos"`
-	GOARCH        map[string]string `yaml:"goarch"`
-	AssetPattern  string            `yaml:"asset_pattern" default:"${goos}-${goarch}"`
-	ArchiveFormat string            `yaml:"archive_format" default:""`
-	BinPattern    string            `yaml:"bin_pattern" default:"${name}"`
-	DownloadURL   string            `yaml:"download_url" default:""`
-	TagFilter     string            `yaml:"tag_filter" default:""`
-	PreReleases   bool              `yaml:"pre_releases"`
+	SchemaVersion int    `yaml:"schema_version" json:"schema_version" default:"1"`
+	Name          string `yaml:"name" json:"name"`
+	Description   string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Extends is the name of the template the package is based on
+	Extends       string            `yaml:"extends,omitempty" json:"extends,omitempty"`
+	Provider      string            `yaml:"provider" json:"provider"`
+	URL           string            `yaml:"url" json:"url"`
+	GOOS          map[string]string `yaml:"goos" json:"goos"`
+	GOARCH        map[string]string `yaml:"goarch" json:"goarch"`
+	Libc          map[string]string `yaml:"libc,omitempty" json:"libc,omitempty"`
+	LibcFallback  []string          `yaml:"libc_fallback,omitempty" json:"libc_fallback,omitempty"`
+	AssetPattern  string            `yaml:"asset_pattern" json:"asset_pattern" default:"${goos}-${goarch}"`
+	ArchiveFormat string            `yaml:"archive_format" json:"archive_format" default:""`
+	BinPattern    string            `yaml:"bin_pattern" json:"bin_pattern" default:"${name}"`
+	DownloadURL   string            `yaml:"download_url" json:"download_url" default:""`
+	TagFilter     string            `yaml:"tag_filter" json:"tag_filter" default:""`
+	PreReleases   bool              `yaml:"pre_releases" json:"pre_releases"`
+	InstallType   string            `yaml:"install_type" json:"install_type" default:""`
+	Desktop       bool              `yaml:"desktop_integration" json:"desktop_integration"`
+	// MinReleaseAge in days overrides the min_release_age of the config
+	MinReleaseAge *int `yaml:"min_release_age,omitempty" json:"min_release_age,omitempty"`
+	// UpdatePolicy limits updates to patch, minor or major (default) releases
+	UpdatePolicy string `yaml:"update_policy,omitempty" json:"update_policy,omitempty"`
+	// VersionCommand are the arguments to print the version of the binary (used by adopt)
+	VersionCommand []string `yaml:"version_command,omitempty" json:"version_command,omitempty"`
+	// VersionRegex extracts the version from the output of the version command
+	VersionRegex string `yaml:"version_regex,omitempty" json:"version_regex,omitempty"`
 }
 
 type PackageV1 struct {
@@ -51,8 +69,13 @@ type PackageV1 struct {
 }
 
 func (pkg *Package) SetDefaults() {
-	pkg.GOOS = make(map[string]string)   // strings.ToLower(runtime.GOOS)
-	pkg.GOARCH = make(map[string]string) // strings.ToLower(runtime.GOARCH)
+	// keep the maps of a template
+	if pkg.GOOS == nil {
+		pkg.GOOS = make(map[string]string) // strings.ToLower(runtime.GOOS)
+	}
+	if pkg.GOARCH == nil {
+		pkg.GOARCH = make(map[string]string) // strings.ToLower(runtime.GOARCH)
+	}
 }
 
 func (pkg *Package) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -84,6 +107,12 @@ func (pkg *Package) UnmarshalYAML(unmarshal func(interface{}) error) error {
 type StateFile struct {
 	Version  int
 	Packages map[string]string `yaml:"packages"`
+	// additional files installed for a package (e.g. desktop files)
+	Files map[string][]string `yaml:"files,omitempty"`
+	// sha256 of the installed binaries, used by verify
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+	// packages run through a shim, their installed binaries are in the store
+	Shims []string `yaml:"shims,omitempty"`
 }
 
 type NewPackageProviderFunc = func(logger zerolog.Logger, config *Config) PackageProvider
@@ -91,6 +120,10 @@ type NewPackageProviderFunc = func(logger zerolog.Logger, config *Config) Packag
 var PackageProviders = make(map[string]NewPackageProviderFunc)
 
 func (pkg *Package) patternExpand(pattern string, version string) string {
+	return pkg.patternExpandLibc(pattern, version, hostLibc())
+}
+
+func (pkg *Package) patternExpandLibc(pattern string, version string, libc string) string {
 	mapper := func(placeHolderName string) string {
 		switch placeHolderName {
 		case "goos":
@@ -105,6 +138,8 @@ func (pkg *Package) patternExpand(pattern string, version string) string {
 				return goarch
 			}
 			return runtime.GOARCH
+		case "libc":
+			return pkg.libcValue(libc)
 		case "name":
 			return pkg.Name
 		case "version":
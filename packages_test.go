@@ -14,7 +14,7 @@ const dummyProviderName = "dummy"
 
 func dummyPackage() *Package {
 	return &Package{
-		PackageV2{
+		PackageV2: PackageV2{
 			SchemaVersion: 2,
 			Name:          "testName",
 			GOOS:          make(map[string]string),
@@ -25,6 +25,7 @@ func dummyPackage() *Package {
 }
 
 func TestPackagePatternExpand(t *testing.T) {
+	setTestLibc(t, LibcMusl)
 	pkg := dummyPackage()
 
 	type patternExpandTest struct {
@@ -62,6 +63,10 @@ func TestPackagePatternExpand(t *testing.T) {
 			output:  fmt.Sprintf("name-%s", runtime.GOARCH),
 			version: version,
 		},
+		{
+			input:  "name-${libc}",
+			output: fmt.Sprintf("name-%s", LibcMusl),
+		},
 		{
 			input:  "name-${missing}",
 			output: "name-",
@@ -77,6 +82,7 @@ func TestPackagePatternExpand(t *testing.T) {
 
 	pkg.GOARCH[runtime.GOARCH] = goarchOverride
 	pkg.GOOS[runtime.GOOS] = goosOverride
+	pkg.Libc = map[string]string{LibcMusl: "unknown-linux-musl"}
 
 	tests = []patternExpandTest{
 		{
@@ -89,6 +95,10 @@ func TestPackagePatternExpand(t *testing.T) {
 			output:  fmt.Sprintf("name-%s", goarchOverride),
 			version: version,
 		},
+		{
+			input:  "name-${libc}",
+			output: "name-unknown-linux-musl",
+		},
 	}
 	for _, test := range tests {
 		assert.Equal(t, pkg.patternExpand(test.input, test.version), test.output)
@@ -0,0 +1,215 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProjectFileName is the name of the project manifest which is searched from the working directory upwards.
+const ProjectFileName = ".bpm.yaml"
+
+// ProjectFile is the manifest of a project with the exact tool versions it needs.
+type ProjectFile struct {
+	// package name: version
+	Tools map[string]string `yaml:"tools"`
+}
+
+// findProject returns the path of the nearest project manifest in dir or one of its parents.
+func findProject(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, ProjectFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w: %s in %s or its parents", ErrProjectNotFound, ProjectFileName, dir)
+		}
+		dir = parent
+	}
+}
+
+// loadProject loads the nearest project manifest of dir.
+func (manager *ManagerImpl) loadProject(dir string) (string, *ProjectFile, error) {
+	path, err := findProject(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	project := &ProjectFile{}
+	err = loadYaml(path, project)
+	if err != nil {
+		return path, nil, fmt.Errorf("%w: %s: %s", ErrProjectLoad, path, err)
+	}
+	for _, name := range sortedKeys(project.Tools) {
+		version := project.Tools[name]
+		// package files are not loaded for the prompt hooks and shims, the store is checked instead
+		if _, ok := manager.Packages[name]; !ok && !manager.stateOnly {
+			return path, nil, fmt.Errorf("%w: %s: %w: %s", ErrProjectLoad, path, ErrPackageNotFound, name)
+		}
+		if !validVersion(version) {
+			return path, nil, fmt.Errorf("%w: %s: invalid version %q of %s", ErrProjectLoad, path, version, name)
+		}
+	}
+	return path, project, nil
+}
+
+// validVersion reports if the version can be used as folder name in the store.
+func validVersion(version string) bool {
+	return version != "" && version != "." && version != ".." && filepath.Base(version) == version
+}
+
+// storeDir returns the folder of the version of the package in the store.
+// The binary inside of it has the name of the package, so the folder can be added to PATH.
+func (manager *ManagerImpl) storeDir(name string, version string) string {
+	return filepath.Join(manager.config.StoreFolder, name, version)
+}
+
+// Project returns the tools of the nearest project manifest of dir and where they are stored.
+func (manager *ManagerImpl) Project(ctx context.Context, dir string) (ProjectStatus, error) {
+	path, project, err := manager.loadProject(dir)
+	status := ProjectStatus{Path: path, Tools: []ProjectTool{}}
+	if err != nil {
+		return status, err
+	}
+	for _, name := range sortedKeys(project.Tools) {
+		tool := ProjectTool{
+			Name:    name,
+			Version: project.Tools[name],
+			Dir:     manager.storeDir(name, project.Tools[name]),
+		}
+		tool.Installed = isExecutableFile(filepath.Join(tool.Dir, name))
+		status.Tools = append(status.Tools, tool)
+	}
+	return status, nil
+}
+
+// InstallProject installs the tool versions of the nearest project manifest of dir into the store.
+// The bin folder and the installed versions of the state are not changed.
+// Tools which cannot be installed are skipped and an *UpdateError with all failures is returned.
+func (manager *ManagerImpl) InstallProject(ctx context.Context, dir string) ([]InstallResult, error) {
+	results := []InstallResult{}
+	status, err := manager.Project(ctx, dir)
+	if err != nil {
+		return results, err
+	}
+	installErr := &UpdateError{Operation: "install"}
+	for _, tool := range status.Tools {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := InstallResult{Name: tool.Name, Version: tool.Version}
+		if tool.Installed {
+			manager.logger.Debug().Str("pkg", tool.Name).Msgf("version %s is already in the store", tool.Version)
+			results = append(results, result)
+			installErr.Succeeded++
+			continue
+		}
+		result, err = manager.installStore(ctx, tool, result)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		} else if err != nil {
+			manager.logger.Error().Str("pkg", tool.Name).Msgf("cannot install package: %s. Skipping...", err)
+			result.Error = err.Error()
+			installErr.Failed = append(installErr.Failed, &PackageError{Name: tool.Name, Err: err})
+		} else {
+			installErr.Succeeded++
+			installErr.Changed++
+		}
+		results = append(results, result)
+	}
+	if len(installErr.Failed) > 0 {
+		return results, installErr
+	}
+	return results, nil
+}
+
+// installStore fetches the version of the tool and copies the binary into the store.
+func (manager *ManagerImpl) installStore(ctx context.Context, tool ProjectTool, result InstallResult) (InstallResult, error) {
+	pkg := manager.Packages[tool.Name]
+	provider, ok := manager.Providers[pkg.Provider]
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+	}
+	target := filepath.Join(tool.Dir, pkg.Name)
+	if manager.config.DryRun {
+		result, err := manager.planInstall(ctx, provider, &pkg, tool.Version, result)
+		result.Target = target
+		return result, err
+	}
+
+	var err error
+	manager.tmpDir, err = os.MkdirTemp("", "bpm-*")
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		os.RemoveAll(manager.tmpDir)
+		manager.tmpDir = ""
+	}()
+	path, err := manager.fetchBinary(ctx, provider, &pkg, tool.Version)
+	if err != nil {
+		return result, err
+	}
+	err = os.MkdirAll(tool.Dir, 0o755)
+	if err != nil {
+		return result, err
+	}
+	err = manager.copyBinary(&pkg, path, target+".tmp", target, false)
+	if err != nil {
+		return result, err
+	}
+	result.Target = target
+	result.Changed = true
+	return result, nil
+}
+
+// PathDirs returns the store folders of the tools which have to be put first on PATH.
+// It fails if a tool version is not installed.
+func (status ProjectStatus) PathDirs() ([]string, error) {
+	dirs := []string{}
+	missing := []string{}
+	for _, tool := range status.Tools {
+		if !tool.Installed {
+			missing = append(missing, fmt.Sprintf("%s %s", tool.Name, tool.Version))
+			continue
+		}
+		dirs = append(dirs, tool.Dir)
+	}
+	if len(missing) > 0 {
+		return dirs, fmt.Errorf("%w: %s (run install in the project)", ErrPackageNotInstalled, strings.Join(missing, ", "))
+	}
+	return dirs, nil
+}
+
+// ProjectEnviron returns env with the store folders of the project tools first on PATH.
+func ProjectEnviron(status ProjectStatus, env []string) ([]string, error) {
+	dirs, err := status.PathDirs()
+	if err != nil || len(dirs) == 0 {
+		return env, err
+	}
+	path := strings.Join(dirs, string(os.PathListSeparator))
+	result := make([]string, 0, len(env)+1)
+	found := false
+	for _, value := range env {
+		if current, ok := strings.CutPrefix(value, "PATH="); ok {
+			found = true
+			if current != "" {
+				value = "PATH=" + path + string(os.PathListSeparator) + current
+			} else {
+				value = "PATH=" + path
+			}
+		}
+		result = append(result, value)
+	}
+	if !found {
+		result = append(result, "PATH="+path)
+	}
+	return result, nil
+}
@@ -0,0 +1,189 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeProjectFile(t *testing.T, dir string, content string) string {
+	path := filepath.Join(dir, ProjectFileName)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	assert.NoError(t, os.MkdirAll(nested, 0o755))
+
+	_, err := findProject(nested)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	path := writeProjectFile(t, root, "tools: {}\n")
+	found, err := findProject(nested)
+	assert.NoError(t, err)
+	assert.Equal(t, path, found)
+
+	// the nearest manifest wins
+	nearest := writeProjectFile(t, filepath.Join(root, "a"), "tools: {}\n")
+	found, err = findProject(nested)
+	assert.NoError(t, err)
+	assert.Equal(t, nearest, found)
+}
+
+func TestManagerProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{
+			name:    "valid",
+			content: "tools:\n  testName: v1.0.0\n",
+		},
+		{
+			name:    "unknown-package",
+			content: "tools:\n  testName: v1.0.0\n  missing: v1.0.0\n",
+			err:     ErrPackageNotFound,
+		},
+		{
+			name:    "invalid-version",
+			content: "tools:\n  testName: ../v1.0.0\n",
+			err:     ErrProjectLoad,
+		},
+		{
+			name:    "parent-version",
+			content: "tools:\n  testName: ..\n",
+			err:     ErrProjectLoad,
+		},
+		{
+			name:    "current-version",
+			content: "tools:\n  testName: .\n",
+			err:     ErrProjectLoad,
+		},
+		{
+			name:    "broken",
+			content: "tools: [\n",
+			err:     ErrProjectLoad,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("")})
+			dir := t.TempDir()
+			path := writeProjectFile(t, dir, test.content)
+			status, err := manager.Project(context.Background(), dir)
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, ProjectStatus{
+				Path: path,
+				Tools: []ProjectTool{{
+					Name:    "testName",
+					Version: "v1.0.0",
+					Dir:     filepath.Join(manager.config.StoreFolder, "testName", "v1.0.0"),
+				}},
+			}, status)
+		})
+	}
+}
+
+func TestManagerProjectStateOnly(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("")})
+	manager.stateOnly = true
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "tools:\n  missing: v1.0.0\n")
+	status, err := manager.Project(context.Background(), dir)
+	assert.NoError(t, err, "the package files are not loaded")
+	assert.Equal(t, []ProjectTool{{
+		Name:    "missing",
+		Version: "v1.0.0",
+		Dir:     filepath.Join(manager.config.StoreFolder, "missing", "v1.0.0"),
+	}}, status.Tools)
+}
+
+func TestManagerInstallProject(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("")})
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "tools:\n  testName: v1.0.0\n")
+	target := filepath.Join(manager.config.StoreFolder, "testName", "v1.0.0", "testName")
+
+	results, err := manager.InstallProject(context.Background(), dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []InstallResult{{Name: "testName", Version: "v1.0.0", Target: target, Changed: true}}, results)
+	expected, err := os.ReadFile(getTestPath("files", "dummy-bin.sh"))
+	assert.NoError(t, err)
+	content, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, content)
+	assert.True(t, isExecutableFile(target))
+	// the store does not change the installed packages
+	assert.NotContains(t, manager.StateFile.Packages, "testName")
+	assert.NoFileExists(t, filepath.Join(manager.config.BinFolder, "testName"))
+
+	results, err = manager.InstallProject(context.Background(), dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []InstallResult{{Name: "testName", Version: "v1.0.0"}}, results, "installed versions are kept")
+
+	status, err := manager.Project(context.Background(), dir)
+	assert.NoError(t, err)
+	dirs, err := status.PathDirs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Dir(target)}, dirs)
+}
+
+func TestManagerInstallProjectFailed(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("")})
+	manager.Providers[dummyProviderName] = &DummyProvider{}
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "tools:\n  testName: v1.0.0\n")
+
+	results, err := manager.InstallProject(context.Background(), dir)
+	updateErr := &UpdateError{}
+	assert.ErrorAs(t, err, &updateErr)
+	assert.Equal(t, "install", updateErr.Operation)
+	assert.Len(t, results, 1)
+	assert.NotEmpty(t, results[0].Error)
+}
+
+func TestManagerInstallProjectDryRun(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("")})
+	manager.config.DryRun = true
+	dir := t.TempDir()
+	writeProjectFile(t, dir, "tools:\n  testName: v1.0.0\n")
+
+	results, err := manager.InstallProject(context.Background(), dir)
+	assert.NoError(t, err)
+	target := filepath.Join(manager.config.StoreFolder, "testName", "v1.0.0", "testName")
+	assert.Equal(t, target, results[0].Target)
+	assert.NoFileExists(t, target)
+}
+
+func TestProjectEnviron(t *testing.T) {
+	status := ProjectStatus{Tools: []ProjectTool{
+		{Name: "a", Version: "v1", Dir: "/store/a/v1", Installed: true},
+		{Name: "b", Version: "v2", Dir: "/store/b/v2", Installed: true},
+	}}
+	env, err := ProjectEnviron(status, []string{"HOME=/home/user", "PATH=/usr/bin"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HOME=/home/user", "PATH=/store/a/v1:/store/b/v2:/usr/bin"}, env)
+
+	env, err = ProjectEnviron(status, []string{"HOME=/home/user"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HOME=/home/user", "PATH=/store/a/v1:/store/b/v2"}, env)
+
+	env, err = ProjectEnviron(ProjectStatus{}, []string{"PATH=/usr/bin"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/usr/bin"}, env, "an empty project does not change PATH")
+
+	status.Tools[1].Installed = false
+	_, err = ProjectEnviron(status, []string{"PATH=/usr/bin"})
+	assert.ErrorIs(t, err, ErrPackageNotInstalled)
+	assert.ErrorContains(t, err, "b v2")
+}
@@ -0,0 +1,297 @@
+package bpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	RegistryTypeGit  = "git"
+	RegistryTypeHTTP = "http"
+)
+
+// RegistryConfig is a remote source of package files.
+type RegistryConfig struct {
+	Name string `yaml:"name"`
+	// git repository or http(s) url of an index file
+	URL string `yaml:"url"`
+	// git or http, detected from the url if empty (urls ending with .yaml or .yml are http indexes)
+	Type string `yaml:"type"`
+	// branch or tag of a git registry, the default branch if empty
+	Ref string `yaml:"ref"`
+	// folder inside of a git registry containing the package files
+	Path string `yaml:"path"`
+}
+
+// registryIndex is the index file of a http registry.
+// The packages are kept as written, they are decoded when the registry is loaded.
+type registryIndex struct {
+	Packages []yaml.Node `yaml:"packages"`
+}
+
+// registryType returns the configured or detected type of the registry.
+func (registry *RegistryConfig) registryType() string {
+	if registry.Type != "" {
+		return registry.Type
+	}
+	if strings.HasSuffix(registry.URL, ".yaml") || strings.HasSuffix(registry.URL, ".yml") {
+		return RegistryTypeHTTP
+	}
+	return RegistryTypeGit
+}
+
+// registryFolder returns the folder the registry is fetched into.
+func (manager *ManagerImpl) registryFolder(registry *RegistryConfig) string {
+	return filepath.Join(manager.config.StateFolder, "registries", registry.Name)
+}
+
+// registryPackagesFolder returns the folder containing the package files of the registry.
+func (manager *ManagerImpl) registryPackagesFolder(registry *RegistryConfig) string {
+	if registry.registryType() == RegistryTypeGit {
+		return filepath.Join(manager.registryFolder(registry), registry.Path)
+	}
+	return manager.registryFolder(registry)
+}
+
+// Registries returns the configured registries with the number of fetched packages.
+func (manager *ManagerImpl) Registries(ctx context.Context) ([]RegistryStatus, error) {
+	statuses := []RegistryStatus{}
+	for i := range manager.config.Registries {
+		statuses = append(statuses, manager.registryStatus(&manager.config.Registries[i]))
+	}
+	return statuses, nil
+}
+
+func (manager *ManagerImpl) registryStatus(registry *RegistryConfig) RegistryStatus {
+	status := RegistryStatus{
+		Name: registry.Name,
+		Type: registry.registryType(),
+		URL:  registry.URL,
+	}
+	for _, pkg := range manager.Packages {
+		if pkg.Registry == registry.Name {
+			status.Packages++
+		}
+	}
+	return status
+}
+
+// UpdateRegistries fetches the given registries (all if no names are given) into the state folder
+// and reloads the packages. Registries which cannot be fetched keep their previous files.
+func (manager *ManagerImpl) UpdateRegistries(ctx context.Context, names []string) ([]RegistryStatus, error) {
+	results := []RegistryStatus{}
+	errs := []error{}
+	for _, name := range names {
+		if manager.registry(name) == nil {
+			return results, fmt.Errorf("%w: unknown registry %s", ErrRegistry, name)
+		}
+	}
+	for i := range manager.config.Registries {
+		registry := &manager.config.Registries[i]
+		if len(names) > 0 && !manager.inPackageList(registry.Name, names) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if manager.config.DryRun {
+			results = append(results, manager.registryStatus(registry))
+			continue
+		}
+		err := manager.updateRegistry(ctx, registry)
+		if err != nil {
+			manager.logger.Error().Str("registry", registry.Name).Msgf("cannot update registry: %s", err)
+			errs = append(errs, &PackageError{Name: registry.Name, Err: err})
+		}
+		results = append(results, RegistryStatus{Name: registry.Name, Type: registry.registryType(), URL: registry.URL})
+		if err != nil {
+			results[len(results)-1].Error = err.Error()
+		}
+	}
+	if !manager.config.DryRun {
+		manager.reloadRegistryPackages()
+		for i := range results {
+			if registry := manager.registry(results[i].Name); registry != nil {
+				results[i].Packages = manager.registryStatus(registry).Packages
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return results, fmt.Errorf("%w: %w", ErrRegistry, errors.Join(errs...))
+	}
+	return results, nil
+}
+
+func (manager *ManagerImpl) registry(name string) *RegistryConfig {
+	for i := range manager.config.Registries {
+		if manager.config.Registries[i].Name == name {
+			return &manager.config.Registries[i]
+		}
+	}
+	return nil
+}
+
+func (manager *ManagerImpl) updateRegistry(ctx context.Context, registry *RegistryConfig) error {
+	if registry.Name == "" || filepath.Base(registry.Name) != registry.Name {
+		return fmt.Errorf("%w: invalid registry name %q", ErrRegistry, registry.Name)
+	}
+	err := os.MkdirAll(filepath.Dir(manager.registryFolder(registry)), 0o755)
+	if err != nil {
+		return err
+	}
+	switch registry.registryType() {
+	case RegistryTypeGit:
+		return manager.updateGitRegistry(ctx, registry)
+	case RegistryTypeHTTP:
+		return manager.updateHTTPRegistry(ctx, registry)
+	default:
+		return fmt.Errorf("%w: unknown registry type %q", ErrRegistry, registry.Type)
+	}
+}
+
+// updateGitRegistry clones the repository or fetches the ref and resets the checkout to it.
+func (manager *ManagerImpl) updateGitRegistry(ctx context.Context, registry *RegistryConfig) error {
+	folder := manager.registryFolder(registry)
+	if _, err := os.Stat(filepath.Join(folder, ".git")); err != nil {
+		args := []string{"clone", "--depth", "1"}
+		if registry.Ref != "" {
+			args = append(args, "--branch", registry.Ref)
+		}
+		// remove leftovers of a failed clone
+		os.RemoveAll(folder)
+		return manager.git(ctx, append(args, "--", registry.URL, folder)...)
+	}
+	ref := registry.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	err := manager.git(ctx, "-C", folder, "fetch", "--depth", "1", "--", registry.URL, ref)
+	if err != nil {
+		return err
+	}
+	return manager.git(ctx, "-C", folder, "reset", "--hard", "FETCH_HEAD")
+}
+
+func (manager *ManagerImpl) git(ctx context.Context, args ...string) error {
+	manager.logger.Debug().Msgf("run git %s", strings.Join(args, " "))
+	output, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: git %s: %s: %s", ErrRegistry, args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// updateHTTPRegistry downloads the index and writes its packages into a new folder
+// which replaces the previous one.
+func (manager *ManagerImpl) updateHTTPRegistry(ctx context.Context, registry *RegistryConfig) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, registry.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s returned %s", ErrRegistry, registry.URL, resp.Status)
+	}
+	index := registryIndex{}
+	err = yaml.NewDecoder(resp.Body).Decode(&index)
+	if err != nil {
+		return fmt.Errorf("%w: invalid index %s: %w", ErrRegistry, registry.URL, err)
+	}
+
+	folder := manager.registryFolder(registry)
+	tmpFolder, err := os.MkdirTemp(filepath.Dir(folder), registry.Name+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpFolder)
+	for i := range index.Packages {
+		pkg := struct {
+			Name string `yaml:"name"`
+		}{}
+		err = index.Packages[i].Decode(&pkg)
+		if err != nil || !validPackageName(pkg.Name) {
+			return fmt.Errorf("%w: invalid package name %q in %s", ErrRegistry, pkg.Name, registry.URL)
+		}
+		err = dumpYaml(filepath.Join(tmpFolder, pkg.Name+".yaml"), &index.Packages[i])
+		if err != nil {
+			return err
+		}
+	}
+	err = os.RemoveAll(folder)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFolder, folder)
+}
+
+// validPackageName reports if the name of a registry package can be used as file name in the bin folder.
+func validPackageName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
+
+// loadRegistryPackages adds the packages of all fetched registries which are not defined locally.
+// Earlier registries take precedence. Broken package files of registries are skipped.
+func (manager *ManagerImpl) loadRegistryPackages() {
+	for i := range manager.config.Registries {
+		registry := &manager.config.Registries[i]
+		logger := manager.logger.With().Str("registry", registry.Name).Logger()
+		folder := manager.registryPackagesFolder(registry)
+		err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if info.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(info.Name(), ".yaml") {
+				return nil
+			}
+			pkg, err := manager.loadPackageFile(path)
+			if err != nil {
+				logger.Warn().Msgf("skip package file %s: %s", path, err)
+				return nil
+			}
+			if !validPackageName(pkg.Name) {
+				logger.Warn().Msgf("skip package file %s: invalid package name %q", path, pkg.Name)
+				return nil
+			}
+			if _, ok := manager.Packages[pkg.Name]; ok {
+				logger.Debug().Msgf("package %s is already defined", pkg.Name)
+				return nil
+			}
+			pkg.Registry = registry.Name
+			manager.Packages[pkg.Name] = pkg
+			return nil
+		})
+		if os.IsNotExist(err) {
+			logger.Debug().Msg("registry is not fetched yet (run registry update)")
+		} else if err != nil {
+			logger.Warn().Msgf("cannot load registry packages: %s", err)
+		}
+	}
+}
+
+// reloadRegistryPackages replaces the registry packages after an update.
+func (manager *ManagerImpl) reloadRegistryPackages() {
+	for name, pkg := range manager.Packages {
+		if pkg.Registry != "" {
+			delete(manager.Packages, name)
+		}
+	}
+	manager.loadRegistryPackages()
+}
@@ -0,0 +1,166 @@
+package bpm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRegistryIndex = `packages:
+  - schema_version: 2
+    name: ripgrep
+    provider: github.com
+    url: github.com/BurntSushi/ripgrep
+  - schema_version: 2
+    name: testName
+    provider: github.com
+    url: github.com/example/registry-local
+`
+
+func TestRegistryType(t *testing.T) {
+	tests := []struct {
+		registry RegistryConfig
+		result   string
+	}{
+		{registry: RegistryConfig{URL: "https://example.com/index.yaml"}, result: RegistryTypeHTTP},
+		{registry: RegistryConfig{URL: "https://example.com/index.yml"}, result: RegistryTypeHTTP},
+		{registry: RegistryConfig{URL: "https://github.com/example/packages.git"}, result: RegistryTypeGit},
+		{registry: RegistryConfig{URL: "https://example.com/index", Type: RegistryTypeHTTP}, result: RegistryTypeHTTP},
+	}
+	for _, test := range tests {
+		t.Run(test.registry.URL, func(t *testing.T) {
+			assert.Equal(t, test.result, test.registry.registryType())
+		})
+	}
+}
+
+func TestManagerUpdateHTTPRegistry(t *testing.T) {
+	index := testRegistryIndex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if index == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(index))
+	}))
+	defer server.Close()
+	manager := getTestManager(t, testManagerOptions{registries: []RegistryConfig{{Name: "team", URL: server.URL + "/index.yaml"}}})
+
+	results, err := manager.UpdateRegistries(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []RegistryStatus{{Name: "team", Type: RegistryTypeHTTP, URL: server.URL + "/index.yaml", Packages: 1}}, results)
+	if assert.Contains(t, manager.Packages, "ripgrep") {
+		assert.Equal(t, "team", manager.Packages["ripgrep"].Registry)
+		assert.Equal(t, "github.com/BurntSushi/ripgrep", manager.Packages["ripgrep"].URL)
+	}
+	assert.Equal(t, dummyProviderName, manager.Packages[dummyPackage().Name].Provider, "local packages win")
+	assert.Empty(t, manager.Packages[dummyPackage().Name].Registry)
+
+	// failed updates keep the previous packages
+	index = ""
+	results, err = manager.UpdateRegistries(context.Background(), []string{"team"})
+	assert.ErrorIs(t, err, ErrRegistry)
+	if assert.Len(t, results, 1) {
+		assert.NotEmpty(t, results[0].Error)
+		assert.Equal(t, 1, results[0].Packages)
+	}
+	assert.Contains(t, manager.Packages, "ripgrep")
+
+	_, err = manager.UpdateRegistries(context.Background(), []string{"unknown"})
+	assert.ErrorIs(t, err, ErrRegistry)
+}
+
+func TestManagerUpdateHTTPRegistryInvalidName(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("packages:\n  - schema_version: 2\n    name: ../evil\n"))
+	}))
+	defer server.Close()
+	manager := getTestManager(t, testManagerOptions{registries: []RegistryConfig{{Name: "team", URL: server.URL + "/index.yaml"}}})
+	_, err := manager.UpdateRegistries(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrRegistry)
+	assert.NoFileExists(t, filepath.Join(manager.config.StateFolder, "evil.yaml"))
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	args = append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %s: %s", args, err, output)
+	}
+}
+
+func TestManagerUpdateGitRegistry(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	runGit(t, repo, "init", "-q", "-b", "main")
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, "packages"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "packages", "ripgrep.yaml"), []byte("schema_version: 2\nname: ripgrep\nprovider: github.com\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "README.yaml"), []byte("not a package"), 0o644))
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "add ripgrep")
+
+	manager := getTestManager(t, testManagerOptions{registries: []RegistryConfig{{Name: "team", URL: "file://" + repo, Ref: "main", Path: "packages"}}})
+	results, err := manager.UpdateRegistries(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, 1, results[0].Packages)
+	}
+	assert.Contains(t, manager.Packages, "ripgrep")
+
+	// the second update fetches new commits
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "packages", "fd.yaml"), []byte("schema_version: 2\nname: fd\nprovider: github.com\n"), 0o644))
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "-q", "-m", "add fd")
+	results, err = manager.UpdateRegistries(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, 2, results[0].Packages)
+	}
+	assert.Equal(t, "team", manager.Packages["fd"].Registry)
+}
+
+func TestManagerLoadRegistryPackages(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{registries: []RegistryConfig{
+		{Name: "first", URL: "https://example.com/first.yaml"},
+		{Name: "second", URL: "https://example.com/second.yaml"},
+		{Name: "missing", URL: "https://example.com/missing.yaml"},
+	}})
+	for _, registry := range []string{"first", "second"} {
+		folder := filepath.Join(manager.config.StateFolder, "registries", registry)
+		assert.NoError(t, os.MkdirAll(folder, 0o755))
+		for _, name := range []string{"shared", registry, dummyPackage().Name} {
+			content := "schema_version: 2\nname: " + name + "\nprovider: " + registry + "\n"
+			assert.NoError(t, os.WriteFile(filepath.Join(folder, name+".yaml"), []byte(content), 0o644))
+		}
+		assert.NoError(t, os.WriteFile(filepath.Join(folder, "old.yaml"), []byte("name: old\n"), 0o644))
+		content := "schema_version: 2\nname: ../escape\nprovider: " + registry + "\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(folder, "escape.yaml"), []byte(content), 0o644))
+	}
+	manager.loadRegistryPackages()
+	assert.Equal(t, "first", manager.Packages["shared"].Registry, "earlier registries take precedence")
+	assert.Equal(t, "second", manager.Packages["second"].Registry)
+	assert.Equal(t, dummyProviderName, manager.Packages[dummyPackage().Name].Provider, "local packages take precedence")
+	assert.NotContains(t, manager.Packages, "old", "broken package files are skipped")
+	assert.NotContains(t, manager.Packages, "../escape", "package names must not leave the bin folder")
+
+	statuses, err := manager.Registries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 0}, []int{statuses[0].Packages, statuses[1].Packages, statuses[2].Packages})
+}
+
+func TestManagerUpdateRegistriesDryRun(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{registries: []RegistryConfig{{Name: "team", URL: "https://invalid.example/index.yaml"}}})
+	manager.config.DryRun = true
+	results, err := manager.UpdateRegistries(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.NoDirExists(t, filepath.Join(manager.config.StateFolder, "registries", "team"))
+}
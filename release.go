@@ -0,0 +1,216 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	UpdatePolicyPatch = "patch"
+	UpdatePolicyMinor = "minor"
+	UpdatePolicyMajor = "major"
+)
+
+var (
+	// timeNow returns the current time. It is a variable to be replaced in tests.
+	timeNow = time.Now
+)
+
+// Release is a single release of a package.
+type Release struct {
+	Version string
+	// zero if the provider does not know the publish date
+	Published time.Time
+}
+
+// ReleaseLister is implemented by providers that can list all releases of a package.
+// The manager uses it to hold back releases younger than the minimum release age.
+type ReleaseLister interface {
+	// ListReleases returns the releases matching the tag filter and pre release setting, newest first.
+	ListReleases(ctx context.Context, pkg Package) ([]Release, error)
+}
+
+// releaseSelection is the release to install, the newest release held back because it is too young
+// and the newest release not allowed by the update policy.
+type releaseSelection struct {
+	Version  string
+	HeldBack *Release
+	Blocked  *Release
+}
+
+// minReleaseAge returns the minimum age of a release. The package setting overrides the config.
+func (pkg *Package) minReleaseAge(config *Config) time.Duration {
+	days := 0
+	if config != nil {
+		days = config.MinReleaseAge
+	}
+	if pkg.MinReleaseAge != nil {
+		days = *pkg.MinReleaseAge
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// releaseAgeReached reports if a release published at published is old enough.
+// Releases without publish date are always accepted.
+func releaseAgeReached(published time.Time, minAge time.Duration) bool {
+	if minAge <= 0 || published.IsZero() {
+		return true
+	}
+	return !timeNow().Before(published.Add(minAge))
+}
+
+// updateAllowed reports if the update from current to version is allowed by the update policy.
+// Versions which are not semantic versions cannot be compared and are always allowed.
+func (pkg *Package) updateAllowed(current string, version string) (bool, error) {
+	switch pkg.UpdatePolicy {
+	case "", UpdatePolicyMajor:
+		return true, nil
+	case UpdatePolicyMinor, UpdatePolicyPatch:
+	default:
+		return false, fmt.Errorf("%w: %q for %s (use %s, %s or %s)", ErrInvalidUpdatePolicy,
+			pkg.UpdatePolicy, pkg.Name, UpdatePolicyPatch, UpdatePolicyMinor, UpdatePolicyMajor)
+	}
+	if current == "" {
+		return true, nil
+	}
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return true, nil
+	}
+	newVersion, err := semver.NewVersion(version)
+	if err != nil || !newVersion.GreaterThan(currentVersion) {
+		return true, nil
+	}
+	if newVersion.Major() != currentVersion.Major() {
+		return false, nil
+	}
+	if pkg.UpdatePolicy == UpdatePolicyPatch && newVersion.Minor() != currentVersion.Minor() {
+		return false, nil
+	}
+	return true, nil
+}
+
+// newerVersion reports if version is newer than current.
+// Versions which are not semantic versions cannot be compared and are newer if they differ.
+func newerVersion(current string, version string) bool {
+	if current == version {
+		return false
+	}
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return true
+	}
+	newVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return true
+	}
+	return newVersion.GreaterThan(currentVersion)
+}
+
+// selectRelease returns the newest release of the package which is old enough and allowed by
+// the update policy of the package. The update policy is compared against current, an empty
+// current (e.g. on install) allows all releases. Releases below current are never selected,
+// current is returned instead.
+// Providers which cannot list releases have to apply the minimum age in GetLatest.
+// The releases are only listed if a minimum age or an update policy applies, listing needs more requests.
+func (manager *ManagerImpl) selectRelease(ctx context.Context, provider PackageProvider, pkg Package, current string) (releaseSelection, error) {
+	minAge := pkg.minReleaseAge(manager.config)
+	restricted := minAge > 0 || (current != "" && pkg.UpdatePolicy != "" && pkg.UpdatePolicy != UpdatePolicyMajor)
+	lister, ok := provider.(ReleaseLister)
+	if !ok || !restricted {
+		version, err := provider.GetLatest(ctx, pkg)
+		if err != nil {
+			return releaseSelection{}, err
+		}
+		if current != "" && !newerVersion(current, version) {
+			return releaseSelection{Version: current}, nil
+		}
+		allowed, err := pkg.updateAllowed(current, version)
+		if err != nil {
+			return releaseSelection{}, err
+		}
+		if !allowed {
+			return releaseSelection{Blocked: &Release{Version: version}},
+				fmt.Errorf("%w: %s of %s is not allowed by update policy %s", ErrNoEligibleRelease, version, pkg.Name, pkg.UpdatePolicy)
+		}
+		return releaseSelection{Version: version}, nil
+	}
+	releases, err := lister.ListReleases(ctx, pkg)
+	if err != nil {
+		return releaseSelection{}, err
+	}
+	selection := releaseSelection{}
+	for i, release := range releases {
+		if current != "" && !newerVersion(current, release.Version) {
+			// never select a release below the installed version, e.g. one installed explicitly before it was old enough
+			selection.Version = current
+			return selection, nil
+		}
+		allowed, err := pkg.updateAllowed(current, release.Version)
+		if err != nil {
+			return selection, err
+		}
+		if !allowed {
+			if selection.Blocked == nil {
+				selection.Blocked = &releases[i]
+			}
+			continue
+		}
+		if !releaseAgeReached(release.Published, minAge) {
+			if selection.HeldBack == nil {
+				selection.HeldBack = &releases[i]
+			}
+			continue
+		}
+		selection.Version = release.Version
+		return selection, nil
+	}
+	switch {
+	case selection.HeldBack != nil && selection.Blocked != nil:
+		return selection, fmt.Errorf("%w: the releases of %s are younger than %s or not allowed by update policy %s", ErrNoEligibleRelease, pkg.Name, minAge, pkg.UpdatePolicy)
+	case selection.HeldBack != nil:
+		return selection, fmt.Errorf("%w: all allowed releases of %s are younger than %s", ErrNoEligibleRelease, pkg.Name, minAge)
+	case selection.Blocked != nil:
+		return selection, fmt.Errorf("%w: no release of %s is allowed by update policy %s", ErrNoEligibleRelease, pkg.Name, pkg.UpdatePolicy)
+	default:
+		return selection, fmt.Errorf("%w: %s has no releases", ErrNoEligibleRelease, pkg.Name)
+	}
+}
+
+// Versions returns the released versions of the package, newest first.
+func (manager *ManagerImpl) Versions(ctx context.Context, name string) ([]string, error) {
+	pkg, ok := manager.Packages[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+	}
+	provider, ok := manager.Providers[pkg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+	}
+	return releaseVersions(ctx, provider, pkg)
+}
+
+// releaseVersions returns the released versions of the package from the provider, newest first.
+// Providers which cannot list releases only return the latest version.
+func releaseVersions(ctx context.Context, provider PackageProvider, pkg Package) ([]string, error) {
+	lister, ok := provider.(ReleaseLister)
+	if !ok {
+		version, err := provider.GetLatest(ctx, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return []string{version}, nil
+	}
+	releases, err := lister.ListReleases(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(releases))
+	for _, release := range releases {
+		versions = append(versions, release.Version)
+	}
+	return versions, nil
+}
@@ -0,0 +1,461 @@
+package bpm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+// setTestNow replaces the current time for the duration of the test.
+func setTestNow(t *testing.T, now time.Time) {
+	backup := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = backup
+	})
+}
+
+// DummyReleaseProvider is a DummyProvider which can list releases.
+type DummyReleaseProvider struct {
+	DummyProvider
+	// name: releases newest first
+	Releases map[string][]Release
+	// number of ListReleases calls
+	Listed int
+}
+
+func (provider *DummyReleaseProvider) ListReleases(ctx context.Context, pkg Package) ([]Release, error) {
+	provider.Listed++
+	releases, ok := provider.Releases[pkg.Name]
+	if !ok {
+		return nil, ErrProviderFetch
+	}
+	return releases, nil
+}
+
+// dummyReleaseProvider returns a provider with the test releases of the dummy package.
+func dummyReleaseProvider() *DummyReleaseProvider {
+	name := dummyPackage().Name
+	return &DummyReleaseProvider{
+		DummyProvider: DummyProvider{
+			FetchPackages: map[string]string{name: getTestPath("files", "dummy-bin.sh")},
+		},
+		Releases: map[string][]Release{name: testReleases()},
+	}
+}
+
+// GetLatest returns the newest release, it does not apply the minimum age.
+func (provider *DummyReleaseProvider) GetLatest(ctx context.Context, pkg Package) (string, error) {
+	releases, ok := provider.Releases[pkg.Name]
+	if !ok || len(releases) == 0 {
+		return provider.DummyProvider.GetLatest(ctx, pkg)
+	}
+	return releases[0].Version, nil
+}
+
+func testReleases() []Release {
+	return []Release{
+		{Version: "v1.2.0", Published: testNow.Add(-24 * time.Hour)},
+		{Version: "v1.1.0", Published: testNow.Add(-5 * 24 * time.Hour)},
+		{Version: "v1.0.0", Published: testNow.Add(-30 * 24 * time.Hour)},
+	}
+}
+
+func setIntPointer(i int) *int {
+	return &i
+}
+
+func TestMinReleaseAge(t *testing.T) {
+	pkg := dummyPackage()
+	config := &Config{MinReleaseAge: 3}
+	assert.Equal(t, 72*time.Hour, pkg.minReleaseAge(config))
+	pkg.MinReleaseAge = setIntPointer(0)
+	assert.Equal(t, time.Duration(0), pkg.minReleaseAge(config), "package setting overrides the config")
+	pkg.MinReleaseAge = setIntPointer(7)
+	assert.Equal(t, 7*24*time.Hour, pkg.minReleaseAge(nil))
+}
+
+func TestReleaseAgeReached(t *testing.T) {
+	setTestNow(t, testNow)
+	assert.True(t, releaseAgeReached(testNow, 0))
+	assert.True(t, releaseAgeReached(time.Time{}, time.Hour), "unknown publish dates are accepted")
+	assert.False(t, releaseAgeReached(testNow.Add(-time.Hour), 2*time.Hour))
+	assert.True(t, releaseAgeReached(testNow.Add(-2*time.Hour), 2*time.Hour))
+}
+
+func TestManagerSelectRelease(t *testing.T) {
+	setTestNow(t, testNow)
+	tests := []struct {
+		name     string
+		minAge   int
+		version  string
+		heldBack string
+		err      error
+	}{
+		{name: "no-min-age", minAge: 0, version: "v1.2.0"},
+		{name: "hold-back-newest", minAge: 3, version: "v1.1.0", heldBack: "v1.2.0"},
+		{name: "hold-back-two", minAge: 7, version: "v1.0.0", heldBack: "v1.2.0"},
+		{name: "all-too-young", minAge: 60, heldBack: "v1.2.0", err: ErrNoEligibleRelease},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.config.MinReleaseAge = test.minAge
+			pkg := dummyPackage()
+			provider := &DummyReleaseProvider{Releases: map[string][]Release{pkg.Name: testReleases()}}
+			selection, err := manager.selectRelease(context.Background(), provider, *pkg, "")
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.version, selection.Version)
+			assert.Equal(t, test.minAge > 0, provider.Listed > 0, "the releases are only listed with a minimum age")
+			if test.heldBack == "" {
+				assert.Nil(t, selection.HeldBack)
+			} else if assert.NotNil(t, selection.HeldBack) {
+				assert.Equal(t, test.heldBack, selection.HeldBack.Version)
+			}
+		})
+	}
+}
+
+func TestManagerOutdatedHeldBack(t *testing.T) {
+	setTestNow(t, testNow)
+	tests := []struct {
+		name      string
+		installed string
+		minAge    int
+		result    []OutdatedEntry
+	}{
+		{
+			name:      "held-back",
+			installed: "v1.0.0",
+			minAge:    3,
+			result: []OutdatedEntry{{
+				Name:       dummyPackage().Name,
+				Current:    "v1.0.0",
+				Latest:     "v1.1.0",
+				HeldBack:   "v1.2.0",
+				EligibleAt: func() *time.Time { eligible := testNow.Add(2 * 24 * time.Hour); return &eligible }(),
+			}},
+		},
+		{
+			name:      "only-held-back",
+			installed: "v1.1.0",
+			minAge:    3,
+			result: []OutdatedEntry{{
+				Name:       dummyPackage().Name,
+				Current:    "v1.1.0",
+				Latest:     "v1.1.0",
+				HeldBack:   "v1.2.0",
+				EligibleAt: func() *time.Time { eligible := testNow.Add(2 * 24 * time.Hour); return &eligible }(),
+			}},
+		},
+		{
+			name:      "all-too-young",
+			installed: "v1.0.0",
+			minAge:    60,
+			result: []OutdatedEntry{{
+				Name:       dummyPackage().Name,
+				Current:    "v1.0.0",
+				Latest:     "v1.0.0",
+				HeldBack:   "v1.2.0",
+				EligibleAt: func() *time.Time { eligible := testNow.Add(59 * 24 * time.Hour); return &eligible }(),
+			}},
+		},
+		{
+			name:      "up-to-date",
+			installed: "v1.2.0",
+			minAge:    0,
+			result:    []OutdatedEntry{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.StateFile = getDummyState()
+			manager.config.MinReleaseAge = test.minAge
+			pkg := dummyPackage()
+			manager.Packages[pkg.Name] = *pkg
+			manager.StateFile.Packages[pkg.Name] = test.installed
+			manager.Providers[dummyProviderName] = &DummyReleaseProvider{Releases: map[string][]Release{pkg.Name: testReleases()}}
+			entries, err := manager.Outdated(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, entries)
+		})
+	}
+}
+
+func TestManagerUpdateMinReleaseAge(t *testing.T) {
+	setTestNow(t, testNow)
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	pkg := dummyPackage()
+	pkg.MinReleaseAge = setIntPointer(3)
+	manager.Packages[pkg.Name] = *pkg
+	manager.StateFile.Packages[pkg.Name] = "v1.0.0"
+	manager.Providers[dummyProviderName] = &DummyReleaseProvider{
+		DummyProvider: DummyProvider{
+			FetchPackages: map[string]string{pkg.Name: getTestPath("files", "dummy-bin.sh")},
+		},
+		Releases: map[string][]Release{pkg.Name: testReleases()},
+	}
+	results, err := manager.Update(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "v1.1.0", results[0].Version, "the newest release is too young")
+	}
+	assert.Equal(t, "v1.1.0", manager.StateFile.Packages[pkg.Name])
+}
+
+func TestNewerVersion(t *testing.T) {
+	assert.True(t, newerVersion("v1.0.0", "v1.1.0"))
+	assert.False(t, newerVersion("v1.1.0", "v1.0.0"))
+	assert.False(t, newerVersion("v1.0.0", "v1.0.0"))
+	assert.False(t, newerVersion("nightly", "nightly"))
+	assert.True(t, newerVersion("nightly", "v1.0.0"), "versions which cannot be compared are newer")
+}
+
+func TestManagerSelectReleaseNoDowngrade(t *testing.T) {
+	setTestNow(t, testNow)
+	manager := getDummyManagerImpl(t)
+	manager.config.MinReleaseAge = 3
+	pkg := dummyPackage()
+	provider := &DummyReleaseProvider{Releases: map[string][]Release{pkg.Name: testReleases()}}
+	selection, err := manager.selectRelease(context.Background(), provider, *pkg, "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.0", selection.Version, "the installed release is too young but must not be downgraded")
+	assert.Nil(t, selection.HeldBack)
+
+	latestOnly := &DummyProvider{LatestPackages: map[string]string{pkg.Name: "v1.0.0"}}
+	selection, err = manager.selectRelease(context.Background(), latestOnly, *pkg, "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.0", selection.Version, "an older latest release is not selected")
+}
+
+func TestManagerUpdateNoDowngrade(t *testing.T) {
+	setTestNow(t, testNow)
+	pkg := dummyPackage()
+	manager := getTestManager(t, testManagerOptions{
+		provider: dummyReleaseProvider(),
+		state:    map[string]string{pkg.Name: "v1.2.0"},
+		config:   "min_release_age: 3\n",
+	})
+	entries, err := manager.Outdated(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	results, err := manager.Update(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.False(t, results[0].Changed)
+	}
+	assert.Equal(t, "v1.2.0", manager.StateFile.Packages[pkg.Name])
+}
+
+func TestUpdateAllowed(t *testing.T) {
+	tests := []struct {
+		policy  string
+		current string
+		version string
+		allowed bool
+		err     error
+	}{
+		{policy: "", current: "v1.0.0", version: "v2.0.0", allowed: true},
+		{policy: UpdatePolicyMajor, current: "v1.0.0", version: "v2.0.0", allowed: true},
+		{policy: UpdatePolicyMinor, current: "v1.0.0", version: "v1.3.1", allowed: true},
+		{policy: UpdatePolicyMinor, current: "v1.0.0", version: "v2.0.0", allowed: false},
+		{policy: UpdatePolicyPatch, current: "v1.0.0", version: "v1.0.5", allowed: true},
+		{policy: UpdatePolicyPatch, current: "v1.0.0", version: "v1.1.0", allowed: false},
+		{policy: UpdatePolicyPatch, current: "v1.0.0", version: "v2.0.0", allowed: false},
+		{policy: UpdatePolicyPatch, current: "", version: "v2.0.0", allowed: true},
+		{policy: UpdatePolicyPatch, current: "v1.1.0", version: "v1.0.0", allowed: true},
+		{policy: UpdatePolicyPatch, current: "nightly", version: "v2.0.0", allowed: true},
+		{policy: "weekly", current: "v1.0.0", version: "v1.0.1", err: ErrInvalidUpdatePolicy},
+	}
+	for _, test := range tests {
+		t.Run(test.policy+"-"+test.current+"-"+test.version, func(t *testing.T) {
+			pkg := dummyPackage()
+			pkg.UpdatePolicy = test.policy
+			allowed, err := pkg.updateAllowed(test.current, test.version)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.allowed, allowed)
+		})
+	}
+}
+
+func policyReleases() []Release {
+	return []Release{
+		{Version: "v2.0.0", Published: testNow.Add(-10 * 24 * time.Hour)},
+		{Version: "v1.2.0", Published: testNow.Add(-20 * 24 * time.Hour)},
+		{Version: "v1.1.1", Published: testNow.Add(-25 * 24 * time.Hour)},
+		{Version: "v1.1.0", Published: testNow.Add(-30 * 24 * time.Hour)},
+	}
+}
+
+func TestManagerSelectReleasePolicy(t *testing.T) {
+	setTestNow(t, testNow)
+	tests := []struct {
+		name    string
+		policy  string
+		current string
+		version string
+		blocked string
+	}{
+		{name: "major", policy: UpdatePolicyMajor, current: "v1.1.0", version: "v2.0.0"},
+		{name: "minor", policy: UpdatePolicyMinor, current: "v1.1.0", version: "v1.2.0", blocked: "v2.0.0"},
+		{name: "patch", policy: UpdatePolicyPatch, current: "v1.1.0", version: "v1.1.1", blocked: "v2.0.0"},
+		{name: "install", policy: UpdatePolicyPatch, current: "", version: "v2.0.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			pkg := dummyPackage()
+			pkg.UpdatePolicy = test.policy
+			provider := &DummyReleaseProvider{Releases: map[string][]Release{pkg.Name: policyReleases()}}
+			selection, err := manager.selectRelease(context.Background(), provider, *pkg, test.current)
+			assert.NoError(t, err)
+			assert.Equal(t, test.version, selection.Version)
+			if test.blocked == "" {
+				assert.Nil(t, selection.Blocked)
+			} else if assert.NotNil(t, selection.Blocked) {
+				assert.Equal(t, test.blocked, selection.Blocked.Version)
+			}
+		})
+	}
+}
+
+func TestManagerSelectReleaseErrors(t *testing.T) {
+	setTestNow(t, testNow)
+	tests := []struct {
+		name    string
+		policy  string
+		minAge  int
+		message string
+	}{
+		{name: "too-young", minAge: 60, message: "all allowed releases of testName are younger than 1440h0m0s"},
+		{name: "policy", policy: UpdatePolicyPatch, message: "no release of testName is allowed by update policy patch"},
+		{name: "both", policy: UpdatePolicyMinor, minAge: 60, message: "the releases of testName are younger than 1440h0m0s or not allowed by update policy minor"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.config.MinReleaseAge = test.minAge
+			pkg := dummyPackage()
+			pkg.UpdatePolicy = test.policy
+			releases := []Release{
+				{Version: "v2.0.0", Published: testNow.Add(-10 * 24 * time.Hour)},
+				{Version: "v1.5.0", Published: testNow.Add(-20 * 24 * time.Hour)},
+			}
+			provider := &DummyReleaseProvider{Releases: map[string][]Release{pkg.Name: releases}}
+			_, err := manager.selectRelease(context.Background(), provider, *pkg, "v1.0.0")
+			assert.ErrorIs(t, err, ErrNoEligibleRelease)
+			assert.ErrorContains(t, err, test.message)
+		})
+	}
+}
+
+func TestManagerSelectReleasePolicyLatestOnly(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	pkg := dummyPackage()
+	pkg.UpdatePolicy = UpdatePolicyMinor
+	provider := &DummyProvider{LatestPackages: map[string]string{pkg.Name: "v2.0.0"}}
+	selection, err := manager.selectRelease(context.Background(), provider, *pkg, "v1.0.0")
+	assert.ErrorIs(t, err, ErrNoEligibleRelease)
+	if assert.NotNil(t, selection.Blocked) {
+		assert.Equal(t, "v2.0.0", selection.Blocked.Version)
+	}
+}
+
+func TestManagerOutdatedPolicy(t *testing.T) {
+	setTestNow(t, testNow)
+	tests := []struct {
+		name      string
+		installed string
+		result    []OutdatedEntry
+	}{
+		{
+			name:      "minor-and-major",
+			installed: "v1.1.0",
+			result: []OutdatedEntry{{
+				Name:    dummyPackage().Name,
+				Current: "v1.1.0",
+				Latest:  "v1.2.0",
+				Blocked: "v2.0.0",
+			}},
+		},
+		{
+			name:      "only-major",
+			installed: "v1.2.0",
+			result: []OutdatedEntry{{
+				Name:    dummyPackage().Name,
+				Current: "v1.2.0",
+				Latest:  "v1.2.0",
+				Blocked: "v2.0.0",
+			}},
+		},
+		{
+			name:      "up-to-date",
+			installed: "v2.0.0",
+			result:    []OutdatedEntry{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager := getDummyManagerImpl(t)
+			manager.StateFile = getDummyState()
+			pkg := dummyPackage()
+			pkg.UpdatePolicy = UpdatePolicyMinor
+			manager.Packages[pkg.Name] = *pkg
+			manager.StateFile.Packages[pkg.Name] = test.installed
+			manager.Providers[dummyProviderName] = &DummyReleaseProvider{Releases: map[string][]Release{pkg.Name: policyReleases()}}
+			entries, err := manager.Outdated(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, entries)
+		})
+	}
+}
+
+func TestManagerUpdatePolicy(t *testing.T) {
+	setTestNow(t, testNow)
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	pkg := dummyPackage()
+	pkg.UpdatePolicy = UpdatePolicyPatch
+	manager.Packages[pkg.Name] = *pkg
+	manager.StateFile.Packages[pkg.Name] = "v1.1.0"
+	manager.Providers[dummyProviderName] = &DummyReleaseProvider{
+		DummyProvider: DummyProvider{
+			FetchPackages: map[string]string{pkg.Name: getTestPath("files", "dummy-bin.sh")},
+		},
+		Releases: map[string][]Release{pkg.Name: policyReleases()},
+	}
+	results, err := manager.Update(context.Background(), nil)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "v1.1.1", results[0].Version, "update stops at the highest patch release")
+	}
+	assert.Equal(t, "v1.1.1", manager.StateFile.Packages[pkg.Name])
+
+	// an explicit install approves the major update
+	result, err := manager.Install(context.Background(), pkg.Name, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0", result.Version)
+	assert.Equal(t, "v2.0.0", manager.StateFile.Packages[pkg.Name])
+}
+
+func TestManagerVersions(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyReleaseProvider()})
+	pkg := dummyPackage()
+	versions, err := manager.Versions(context.Background(), pkg.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1.2.0", "v1.1.0", "v1.0.0"}, versions)
+
+	manager.Providers[dummyProviderName] = &DummyProvider{LatestPackages: map[string]string{pkg.Name: "v2.0.0"}}
+	versions, err = manager.Versions(context.Background(), pkg.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v2.0.0"}, versions, "providers without release list return the latest version")
+
+	_, err = manager.Versions(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrPackageNotFound)
+}
@@ -0,0 +1,185 @@
+package bpm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+)
+
+const (
+	rpmLeadSize       = 96
+	rpmHeaderSize     = 16
+	rpmIndexEntrySize = 16
+	cpioHeaderSize    = 110
+	cpioTrailer       = "TRAILER!!!"
+	// limit for the rpm metadata headers, they are read into memory
+	rpmMaxHeaderSize = 64 << 20
+)
+
+var (
+	rpmLeadMagic   = []byte{0xed, 0xab, 0xee, 0xdb}
+	rpmHeaderMagic = []byte{0x8e, 0xad, 0xe8, 0x01}
+)
+
+// skipRpmHeader reads over a rpm header structure (signature or main header).
+// If pad is set the header is padded to a multiple of 8 bytes.
+func skipRpmHeader(reader io.Reader, pad bool) error {
+	header := make([]byte, rpmHeaderSize)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return fmt.Errorf("%w: truncated rpm header: %s", ErrArchiveFormat, err)
+	}
+	if !bytes.Equal(header[0:4], rpmHeaderMagic) {
+		return fmt.Errorf("%w: invalid rpm header magic", ErrArchiveFormat)
+	}
+	entries := int64(binary.BigEndian.Uint32(header[8:12]))
+	dataSize := int64(binary.BigEndian.Uint32(header[12:16]))
+	size := entries*rpmIndexEntrySize + dataSize
+	if size > rpmMaxHeaderSize {
+		return fmt.Errorf("%w: rpm header is larger than %d bytes", ErrArchiveLimit, rpmMaxHeaderSize)
+	}
+	if pad && (rpmHeaderSize+size)%8 != 0 {
+		size += 8 - (rpmHeaderSize+size)%8
+	}
+	_, err = io.CopyN(io.Discard, reader, size)
+	if err != nil {
+		return fmt.Errorf("%w: truncated rpm header: %s", ErrArchiveFormat, err)
+	}
+	return nil
+}
+
+// rpmWalker walks over the entries of the cpio payload of a rpm package.
+func rpmWalker(reader io.Reader, limits ExtractLimits) archiveWalkFunc {
+	return func(visit archiveVisitFunc) error {
+		bufReader := bufio.NewReader(reader)
+		lead := make([]byte, rpmLeadSize)
+		_, err := io.ReadFull(bufReader, lead)
+		if err != nil || !bytes.Equal(lead[0:4], rpmLeadMagic) {
+			return fmt.Errorf("%w: not a rpm package", ErrArchiveFormat)
+		}
+		// signature header
+		err = skipRpmHeader(bufReader, true)
+		if err != nil {
+			return err
+		}
+		// main header
+		err = skipRpmHeader(bufReader, false)
+		if err != nil {
+			return err
+		}
+		payload, err := decompressReader(bufReader)
+		if err != nil {
+			return err
+		}
+		return cpioWalker(payload, limits)(visit)
+	}
+}
+
+// cpioWalker walks over a cpio archive in the "new ascii" format (with or without checksum).
+func cpioWalker(reader io.Reader, limits ExtractLimits) archiveWalkFunc {
+	return func(visit archiveVisitFunc) error {
+		guard := newArchiveGuard(limits)
+		bufReader := bufio.NewReader(reader)
+		header := make([]byte, cpioHeaderSize)
+		for {
+			_, err := io.ReadFull(bufReader, header)
+			if err != nil {
+				return fmt.Errorf("%w: truncated cpio header: %s", ErrArchiveFormat, err)
+			}
+			magic := string(header[0:6])
+			if magic != "070701" && magic != "070702" {
+				return fmt.Errorf("%w: unsupported cpio format", ErrArchiveFormat)
+			}
+			fields := make([]int64, 13)
+			for i := range fields {
+				value, err := strconv.ParseUint(string(header[6+i*8:14+i*8]), 16, 32)
+				if err != nil {
+					return fmt.Errorf("%w: invalid cpio header", ErrArchiveFormat)
+				}
+				fields[i] = int64(value)
+			}
+			mode := fields[1]
+			size := fields[6]
+			nameSize := fields[11]
+			if nameSize <= 0 || nameSize > 4096 {
+				return fmt.Errorf("%w: invalid cpio name size", ErrArchiveFormat)
+			}
+
+			rawName := make([]byte, nameSize)
+			_, err = io.ReadFull(bufReader, rawName)
+			if err != nil {
+				return fmt.Errorf("%w: truncated cpio name: %s", ErrArchiveFormat, err)
+			}
+			err = cpioSkipPadding(bufReader, cpioHeaderSize+nameSize)
+			if err != nil {
+				return err
+			}
+			rawName = bytes.TrimRight(rawName, "\x00")
+			if string(rawName) == cpioTrailer {
+				return nil
+			}
+
+			entry := archiveEntry{
+				name: string(rawName),
+				size: size,
+			}
+			err = guard.entry(entry.name, size)
+			if err != nil {
+				return err
+			}
+			content := io.LimitReader(bufReader, size)
+			perm := fs.FileMode(mode).Perm()
+			done := false
+			switch mode & 0o170000 {
+			case 0o100000:
+				entry.mode = perm
+				done, err = visit(entry, guard.reader(entry.name, content))
+			case 0o040000:
+				entry.mode = fs.ModeDir | perm
+				done, err = visit(entry, content)
+			case 0o120000:
+				entry.mode = fs.ModeSymlink | 0o777
+				done, err = visit(entry, content)
+			}
+			if err != nil || done {
+				return err
+			}
+
+			_, err = io.Copy(io.Discard, content)
+			if err != nil {
+				return err
+			}
+			err = cpioSkipPadding(bufReader, size)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// cpioSkipPadding skips the padding after a block of the given size (4 byte alignment).
+func cpioSkipPadding(reader *bufio.Reader, size int64) error {
+	if size%4 == 0 {
+		return nil
+	}
+	_, err := reader.Discard(int(4 - size%4))
+	if err != nil {
+		return fmt.Errorf("%w: truncated cpio archive: %s", ErrArchiveFormat, err)
+	}
+	return nil
+}
+
+func (manager *ManagerImpl) extractRpm(pkg *Package, version string, sourceFile string) (string, error) {
+	file, err := os.Open(sourceFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return manager.extractMatching(pkg, version, rpmWalker(file, manager.config.Extract))
+}
@@ -0,0 +1,103 @@
+package bpm
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildTestCpioEntry(name string, mode int64, content string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x", 1, mode, 0, 0, 1, 0, len(content), 0, 0, 0, 0, len(name)+1, 0)
+	buf.WriteString(name)
+	buf.WriteByte(0)
+	for buf.Len()%4 != 0 {
+		buf.WriteByte(0)
+	}
+	buf.WriteString(content)
+	for buf.Len()%4 != 0 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+func buildTestCpio(entries ...[]byte) []byte {
+	entries = append(entries, buildTestCpioEntry(cpioTrailer, 0, ""))
+	return bytes.Join(entries, nil)
+}
+
+func TestCpioWalker(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		output  string
+		err     error
+	}{
+		{
+			name: "regular",
+			content: buildTestCpio(
+				buildTestCpioEntry("./usr/bin", 0o40755, ""),
+				buildTestCpioEntry("./usr/bin/other", 0o100755, "other"),
+				buildTestCpioEntry("./usr/bin/tool", 0o100755, "binary"),
+			),
+			output: "binary",
+		},
+		{
+			name: "symlink-is-skipped",
+			content: buildTestCpio(
+				buildTestCpioEntry("./usr/bin/tool", 0o120777, "/etc/passwd"),
+			),
+			err: ErrArchiveNoMatch,
+		},
+		{
+			name: "traversal",
+			content: buildTestCpio(
+				buildTestCpioEntry("../usr/bin/tool", 0o100755, "binary"),
+			),
+			err: ErrArchiveUnsafePath,
+		},
+		{
+			name:    "truncated",
+			content: buildTestCpioEntry("./usr/bin/other", 0o100755, "other"),
+			err:     ErrArchiveFormat,
+		},
+		{
+			name:    "no-cpio",
+			content: []byte("this is not a cpio archive, but it is long enough for a header to be read from it......................."),
+			err:     ErrArchiveFormat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputPath := t.TempDir() + "/output"
+			err := extractMatchingFile(cpioWalker(bytes.NewReader(test.content), ExtractLimits{}), regexp.MustCompile("(^|/)usr/bin/tool$"), outputPath)
+			if assert.ErrorIs(t, err, test.err) && test.err == nil {
+				content, err := os.ReadFile(outputPath)
+				assert.NoError(t, err)
+				assert.Equal(t, test.output, string(content))
+			}
+		})
+	}
+}
+
+func TestRpmWalker(t *testing.T) {
+	content, err := os.ReadFile(getTestPath("files", "dummy-bin.sh.rpm"))
+	if err != nil {
+		t.Fatalf("cannot read rpm test file: %s", err)
+	}
+	outputPath := t.TempDir() + "/output"
+	err = extractMatchingFile(rpmWalker(bytes.NewReader(content), ExtractLimits{}), regexp.MustCompile("dummy-bin.sh$"), outputPath)
+	assert.NoError(t, err)
+	assert.FileExists(t, outputPath)
+
+	err = extractMatchingFile(rpmWalker(bytes.NewReader(content[:100]), ExtractLimits{}), regexp.MustCompile("dummy-bin.sh$"), outputPath)
+	assert.ErrorIs(t, err, ErrArchiveFormat)
+
+	err = extractMatchingFile(rpmWalker(bytes.NewReader([]byte("no rpm")), ExtractLimits{}), regexp.MustCompile("dummy-bin.sh$"), outputPath)
+	assert.ErrorIs(t, err, ErrArchiveFormat)
+}
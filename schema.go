@@ -0,0 +1,107 @@
+package bpm
+
+import (
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var yamlNodeType = reflect.TypeOf(yaml.Node{})
+
+// schemaEnums are the allowed values of fields, identified by the yaml path of the field.
+// The same lists are used by lint.
+func schemaEnums() map[string][]string {
+	return map[string][]string{
+		"archive_format":  ArchiveFormats,
+		"install_type":    InstallTypes,
+		"update_policy":   UpdatePolicies,
+		"provider":        sortedKeys(PackageProviders),
+		"registries.type": {RegistryTypeGit, RegistryTypeHTTP},
+	}
+}
+
+// PackageSchema returns the JSON Schema of package files.
+func PackageSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(PackageV2{}), "", schemaEnums())
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "bpm package"
+	schema["required"] = []string{"schema_version", "name", "provider"}
+	properties := schema["properties"].(map[string]any)
+	properties["schema_version"] = map[string]any{"const": PackageSchemaVersion}
+	return schema
+}
+
+// ConfigSchema returns the JSON Schema of the config file.
+func ConfigSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Config{}), "", schemaEnums())
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = "bpm config"
+	// templates are partial packages
+	template := typeSchema(reflect.TypeOf(PackageV2{}), "", schemaEnums())
+	delete(template, "required")
+	properties := schema["properties"].(map[string]any)
+	properties["templates"] = map[string]any{
+		"type":                 "object",
+		"additionalProperties": template,
+	}
+	return schema
+}
+
+// typeSchema returns the schema of t. Structs are described by their yaml fields,
+// path is the yaml path of t used to look up enums.
+func typeSchema(t reflect.Type, path string, enums map[string][]string) map[string]any {
+	if t == yamlNodeType {
+		return map[string]any{}
+	}
+	schema := map[string]any{}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), path, enums)
+	case reflect.String:
+		schema["type"] = "string"
+		if values := enums[path]; len(values) > 0 {
+			schema["enum"] = values
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = typeSchema(t.Elem(), path, enums)
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = typeSchema(t.Elem(), path+".*", enums)
+	case reflect.Struct:
+		schema["type"] = "object"
+		schema["additionalProperties"] = false
+		properties := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			fieldSchema := typeSchema(field.Type, fieldPath, enums)
+			if value := field.Tag.Get("default"); value != "" && fieldSchema["type"] == "string" {
+				fieldSchema["default"] = value
+			}
+			properties[name] = fieldSchema
+		}
+		schema["properties"] = properties
+	}
+	return schema
+}
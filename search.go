@@ -0,0 +1,100 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	// maximum number of repositories checked per provider in a remote search
+	searchRemoteLimit = 10
+	SearchSourceLocal = "local"
+)
+
+// RepositorySearcher is implemented by providers that can search repositories.
+// The results are checked for release assets matching the host platform by the manager.
+type RepositorySearcher interface {
+	SearchRepositories(ctx context.Context, term string, limit int) ([]SearchResult, error)
+}
+
+// Search returns the loaded packages with term in the name or description. With remote the
+// repositories of all providers supporting search are added if their latest release has an asset
+// for this platform. Remote results can be added with `add <name> <url>`.
+func (manager *ManagerImpl) Search(ctx context.Context, term string, remote bool) ([]SearchResult, error) {
+	results := []SearchResult{}
+	lowerTerm := strings.ToLower(term)
+	knownURLs := map[string]bool{}
+	for _, name := range manager.sortedPackageNames() {
+		pkg := manager.Packages[name]
+		knownURLs[pkg.URL] = true
+		if !strings.Contains(strings.ToLower(pkg.Name), lowerTerm) && !strings.Contains(strings.ToLower(pkg.Description), lowerTerm) {
+			continue
+		}
+		source := pkg.Registry
+		if source == "" {
+			source = SearchSourceLocal
+		}
+		_, installed := manager.StateFile.Packages[name]
+		results = append(results, SearchResult{
+			Name:        pkg.Name,
+			Description: pkg.Description,
+			Source:      source,
+			URL:         pkg.URL,
+			Installed:   installed,
+		})
+	}
+	if !remote {
+		return results, nil
+	}
+	for _, providerName := range sortedKeys(manager.Providers) {
+		searcher, ok := manager.Providers[providerName].(RepositorySearcher)
+		if !ok {
+			continue
+		}
+		found, err := searcher.SearchRepositories(ctx, term, searchRemoteLimit)
+		if err != nil {
+			return results, fmt.Errorf("%w: search %s: %w", ErrProvider, providerName, err)
+		}
+		for _, result := range found {
+			if knownURLs[result.URL] {
+				continue
+			}
+			asset, err := manager.platformAsset(ctx, providerName, result)
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			} else if err != nil {
+				manager.logger.Debug().Msgf("skip %s: %s", result.URL, err)
+				continue
+			}
+			result.Source = providerName
+			result.Asset = asset
+			results = append(results, result)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		// local and registry packages first
+		return results[i].Asset == "" && results[j].Asset != ""
+	})
+	return results, nil
+}
+
+// platformAsset returns the best asset of the latest release of the repository for this platform.
+func (manager *ManagerImpl) platformAsset(ctx context.Context, providerName string, result SearchResult) (string, error) {
+	assetProvider, ok := manager.Providers[providerName].(AssetProvider)
+	if !ok {
+		return "", fmt.Errorf("%w: provider %s cannot list assets", ErrProvider, providerName)
+	}
+	pkg := Package{PackageV2: PackageV2{Name: result.Name, URL: result.URL, Provider: providerName}}
+	version, assets, err := assetProvider.GetLatestAssets(ctx, pkg)
+	if err != nil {
+		return "", err
+	}
+	candidates := rankAssets(assets, runtime.GOOS, runtime.GOARCH, hostLibc())
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("%w: no asset of release %s matches %s/%s", ErrProviderFetch, version, runtime.GOOS, runtime.GOARCH)
+	}
+	return candidates[0].Name, nil
+}
@@ -0,0 +1,119 @@
+package bpm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// DummySearchProvider is a DummyProvider which can search repositories.
+type DummySearchProvider struct {
+	DummyProvider
+	Repositories []SearchResult
+	Err          error
+}
+
+func (provider *DummySearchProvider) SearchRepositories(ctx context.Context, term string, limit int) ([]SearchResult, error) {
+	return provider.Repositories, provider.Err
+}
+
+// searchTestPackages are the local packages of the search tests, ripgrep is installed.
+var searchTestPackages = []Package{
+	{PackageV2: PackageV2{Name: "ripgrep", Description: "recursive grep", URL: "github.com/BurntSushi/ripgrep"}},
+	{PackageV2: PackageV2{Name: "fd", Description: "simple find alternative", URL: "github.com/sharkdp/fd"}, Registry: "team"},
+	{PackageV2: PackageV2{Name: "bat", Description: "cat clone", URL: "github.com/sharkdp/bat"}},
+}
+
+// searchTestProvider returns a provider with the repositories found by the search tests.
+func searchTestProvider() *DummySearchProvider {
+	platformAssets := []string{
+		"tool-linux-amd64.tar.gz", "tool-linux-arm64.tar.gz",
+		"tool-darwin-amd64.tar.gz", "tool-darwin-arm64.tar.gz",
+		"tool-windows-amd64.zip", "tool-windows-arm64.zip",
+	}
+	return &DummySearchProvider{
+		DummyProvider: DummyProvider{
+			LatestPackages: map[string]string{"ugrep": "v7.0.0", "grepper": "v1.0.0"},
+			Assets: map[string][]string{
+				"ugrep":   platformAssets,
+				"grepper": {"grepper-freebsd-riscv64.tar.gz", "grepper.sha256"},
+			},
+		},
+		Repositories: []SearchResult{
+			{Name: "ripgrep", Description: "already known", URL: "github.com/BurntSushi/ripgrep"},
+			{Name: "ugrep", Description: "ultra fast grep", URL: "github.com/Genivia/ugrep"},
+			{Name: "grepper", Description: "no matching asset", URL: "github.com/example/grepper"},
+			{Name: "sourceonly", Description: "no release", URL: "github.com/example/sourceonly"},
+		},
+	}
+}
+
+func TestManagerSearch(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{
+		packages: searchTestPackages,
+		provider: searchTestProvider(),
+		state:    map[string]string{"ripgrep": "v14.1.0"},
+	})
+	tests := []struct {
+		name   string
+		term   string
+		remote bool
+		result []SearchResult
+	}{
+		{
+			name: "name",
+			term: "RIP",
+			result: []SearchResult{
+				{Name: "ripgrep", Description: "recursive grep", Source: SearchSourceLocal, URL: "github.com/BurntSushi/ripgrep", Installed: true},
+			},
+		},
+		{
+			name: "description",
+			term: "find",
+			result: []SearchResult{
+				{Name: "fd", Description: "simple find alternative", Source: "team", URL: "github.com/sharkdp/fd"},
+			},
+		},
+		{
+			name:   "none",
+			term:   "unknown",
+			result: []SearchResult{},
+		},
+		{
+			name:   "remote",
+			term:   "grep",
+			remote: true,
+			result: []SearchResult{
+				{Name: "ripgrep", Description: "recursive grep", Source: SearchSourceLocal, URL: "github.com/BurntSushi/ripgrep", Installed: true},
+				{Name: "ugrep", Description: "ultra fast grep", Source: dummyProviderName, URL: "github.com/Genivia/ugrep", Asset: searchTestAsset(t, manager)},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results, err := manager.Search(context.Background(), test.term, test.remote)
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, results)
+		})
+	}
+}
+
+// searchTestAsset returns the asset of ugrep selected for this platform.
+func searchTestAsset(t *testing.T, manager *ManagerImpl) string {
+	asset, err := manager.platformAsset(context.Background(), dummyProviderName, SearchResult{Name: "ugrep", URL: "github.com/Genivia/ugrep"})
+	assert.NoError(t, err)
+	return asset
+}
+
+func TestManagerSearchRemoteError(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{
+		packages: searchTestPackages,
+		provider: searchTestProvider(),
+		state:    map[string]string{"ripgrep": "v14.1.0"},
+	})
+	manager.Providers[dummyProviderName].(*DummySearchProvider).Err = ErrProviderFetch
+	results, err := manager.Search(context.Background(), "grep", true)
+	assert.ErrorIs(t, err, ErrProvider)
+	assert.Len(t, results, 1, "local results are returned")
+}
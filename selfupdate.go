@@ -0,0 +1,170 @@
+package bpm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// SelfURL is the repository with the releases of bpm (in the url format of package files)
+	SelfURL = "github.com/jduepmeier/binary-package-manager"
+	// selfChecksums is the checksum file of the releases (see .goreleaser.yml)
+	selfChecksums = "checksums.txt"
+)
+
+// selfPackage returns the package of bpm itself. The archives are named by goreleaser
+// (binary-package-manager_<version>_<goos>_<goarch>.tar.gz).
+func selfPackage() Package {
+	pkg := Package{}
+	pkg.SchemaVersion = PackageSchemaVersion
+	pkg.Name = "bpm"
+	pkg.Provider = "github.com"
+	pkg.URL = SelfURL
+	// arm binaries are built for armv6 and armv7, armv6 runs on both
+	pkg.GOARCH = map[string]string{"arm": "armv6"}
+	pkg.AssetPattern = `^binary-package-manager_[^_]+_${goos}_${goarch}\.tar\.gz$`
+	pkg.ArchiveFormat = "tar.gz"
+	pkg.BinPattern = `^bpm(\.exe)?$`
+	pkg.InstallType = InstallTypeBinary
+	return pkg
+}
+
+// SelfUpdate replaces the bpm binary at executable with the newest release if it differs from
+// the current version. The archive is verified against the checksums of the release.
+// Development builds are only replaced with force.
+func (manager *ManagerImpl) SelfUpdate(ctx context.Context, current string, executable string, force bool) (result InstallResult, err error) {
+	pkg := selfPackage()
+	result = InstallResult{Name: pkg.Name, PreviousVersion: current, Version: current}
+	provider, ok := manager.Providers[pkg.Provider]
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrProviderNotFound, pkg.Provider)
+	}
+	selection, err := manager.selectRelease(ctx, provider, pkg, "")
+	if err != nil {
+		return result, err
+	}
+	version := selection.Version
+	// goreleaser injects the version without the v prefix of the tag
+	if strings.TrimPrefix(version, "v") == strings.TrimPrefix(current, "v") && !force {
+		manager.logger.Info().Msgf("bpm %s is up to date :)", current)
+		return result, nil
+	}
+	if current == "dev" && !force {
+		return result, fmt.Errorf("%w: %s is a development build, use --force to replace it with %s", ErrSelfUpdate, executable, version)
+	}
+	if manager.config.DryRun {
+		result, err = manager.planInstall(ctx, provider, &pkg, version, result)
+		result.Target = executable
+		return result, err
+	}
+
+	manager.tmpDir, err = os.MkdirTemp("", "bpm-*")
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		os.RemoveAll(manager.tmpDir)
+		manager.tmpDir = ""
+	}()
+	archive, err := provider.FetchPackage(ctx, pkg, version, manager.tmpDir)
+	if err != nil {
+		return result, err
+	}
+	err = manager.verifySelfArchive(ctx, provider, pkg, version, archive)
+	if err != nil {
+		return result, err
+	}
+	path, err := manager.extractPackage(&pkg, version, archive)
+	if err != nil {
+		return result, err
+	}
+	err = replaceExecutable(executable, func(tmpFile string) error {
+		return manager.copyBinary(&pkg, path, tmpFile, executable, force)
+	})
+	if err != nil {
+		return result, fmt.Errorf("%w: cannot replace %s: %w", ErrSelfUpdate, executable, err)
+	}
+	result.Version = version
+	result.Target = executable
+	result.Changed = true
+	return result, nil
+}
+
+// verifySelfArchive compares the sha256 of the archive with the checksum file of the release.
+func (manager *ManagerImpl) verifySelfArchive(ctx context.Context, provider PackageProvider, pkg Package, version string, archive string) error {
+	checksumPkg := pkg
+	checksumPkg.AssetPattern = "^" + strings.ReplaceAll(selfChecksums, ".", `\.`) + "$"
+	checksumPkg.ArchiveFormat = ""
+	checksumDir := filepath.Join(manager.tmpDir, "checksums")
+	err := os.MkdirAll(checksumDir, 0o755)
+	if err != nil {
+		return err
+	}
+	path, err := provider.FetchPackage(ctx, checksumPkg, version, checksumDir)
+	if err != nil {
+		return fmt.Errorf("%w: cannot fetch %s of %s: %w", ErrSelfUpdate, selfChecksums, version, err)
+	}
+	expected, err := readChecksum(path, filepath.Base(archive))
+	if err != nil {
+		return err
+	}
+	checksum, err := fileChecksum(archive)
+	if err != nil {
+		return err
+	}
+	if checksum != expected {
+		return fmt.Errorf("%w: checksum of %s is %s, %s expects %s", ErrSelfUpdate, filepath.Base(archive), checksum, selfChecksums, expected)
+	}
+	manager.logger.Debug().Msgf("checksum of %s matches %s", filepath.Base(archive), selfChecksums)
+	return nil
+}
+
+// readChecksum returns the sha256 of the file name in a checksum file (lines of "<sha256>  <name>").
+func readChecksum(path string, name string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%w: %s is not listed in %s", ErrSelfUpdate, name, selfChecksums)
+}
+
+// replaceExecutable calls install with a tmp file next to executable which install renames to executable.
+// Windows cannot replace a running executable, it is moved aside first and restored on failure.
+func replaceExecutable(executable string, install func(tmpFile string) error) error {
+	tmpFile := executable + ".new"
+	if runtime.GOOS != "windows" {
+		err := install(tmpFile)
+		if err != nil {
+			os.Remove(tmpFile)
+		}
+		return err
+	}
+	oldFile := executable + ".old"
+	os.Remove(oldFile)
+	err := os.Rename(executable, oldFile)
+	if err != nil {
+		return err
+	}
+	err = install(tmpFile)
+	if err != nil {
+		os.Remove(tmpFile)
+		os.Rename(oldFile, executable)
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package bpm
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const selfTestBinary = "#!/bin/sh\necho bpm v1.2.0\n"
+
+// DummySelfProvider serves a release archive of bpm and its checksum file.
+type DummySelfProvider struct {
+	DummyProvider
+	archive   string
+	checksums string
+	fetched   int
+}
+
+func (provider *DummySelfProvider) FetchPackage(ctx context.Context, pkg Package, version string, cacheDir string) (string, error) {
+	provider.fetched++
+	name := filepath.Base(provider.archive)
+	if pkg.AssetPattern == "^checksums\\.txt$" {
+		path := filepath.Join(cacheDir, "checksums.txt")
+		return path, os.WriteFile(path, []byte(provider.checksums), 0o644)
+	}
+	if !regexp.MustCompile(pkg.patternExpand(pkg.AssetPattern, version)).MatchString(name) {
+		return "", fmt.Errorf("%w: no matching asset found", ErrProviderFetch)
+	}
+	content, err := os.ReadFile(provider.archive)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(cacheDir, name)
+	return path, os.WriteFile(path, content, 0o644)
+}
+
+// writeSelfArchive writes the release archive of the host platform like goreleaser names it.
+func writeSelfArchive(t *testing.T) string {
+	goarch := runtime.GOARCH
+	if goarch == "arm" {
+		goarch = "armv6"
+	}
+	path := filepath.Join(t.TempDir(), fmt.Sprintf("binary-package-manager_1.2.0_%s_%s.tar.gz", runtime.GOOS, goarch))
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range map[string]string{"README.md": "# bpm\n", "bpm": selfTestBinary} {
+		err = tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		assert.NoError(t, err)
+		_, err = tarWriter.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tarWriter.Close())
+	assert.NoError(t, gzipWriter.Close())
+	return path
+}
+
+// getSelfProvider returns a provider with the release v1.2.0 of bpm and the executable of v1.1.0.
+func getSelfProvider(t *testing.T) (*DummySelfProvider, string) {
+	archive := writeSelfArchive(t)
+	checksum, err := fileChecksum(archive)
+	assert.NoError(t, err)
+	provider := &DummySelfProvider{
+		DummyProvider: DummyProvider{LatestPackages: map[string]string{"bpm": "v1.2.0"}},
+		archive:       archive,
+		checksums:     fmt.Sprintf("0000  binary-package-manager_1.2.0_plan9_386.tar.gz\n%s  %s\n", checksum, filepath.Base(archive)),
+	}
+	executable := filepath.Join(t.TempDir(), "bpm")
+	err = os.WriteFile(executable, []byte("#!/bin/sh\necho bpm v1.1.0\n"), 0o755)
+	assert.NoError(t, err)
+	return provider, executable
+}
+
+func TestManagerSelfUpdate(t *testing.T) {
+	provider, executable := getSelfProvider(t)
+	manager := getTestManager(t, testManagerOptions{})
+	manager.Providers["github.com"] = provider
+	result, err := manager.SelfUpdate(context.Background(), "1.1.0", executable, false)
+	assert.NoError(t, err)
+	assert.Equal(t, InstallResult{Name: "bpm", PreviousVersion: "1.1.0", Version: "v1.2.0", Changed: true, Target: executable}, result)
+	content, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	assert.Equal(t, selfTestBinary, string(content))
+	info, err := os.Stat(executable)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+	assert.NoFileExists(t, executable+".new")
+	assert.Empty(t, manager.StateFile.Packages, "bpm itself is not a package of the state")
+}
+
+func TestSelfPackageRepository(t *testing.T) {
+	owner, repoName, err := (&GithubProvider{}).repository(selfPackage())
+	assert.NoError(t, err)
+	assert.Equal(t, "jduepmeier", owner)
+	assert.Equal(t, "binary-package-manager", repoName)
+}
+
+func TestManagerSelfUpdateUpToDate(t *testing.T) {
+	provider, executable := getSelfProvider(t)
+	manager := getTestManager(t, testManagerOptions{})
+	manager.Providers["github.com"] = provider
+	result, err := manager.SelfUpdate(context.Background(), "1.2.0", executable, false)
+	assert.NoError(t, err)
+	assert.False(t, result.Changed)
+	assert.Equal(t, 0, provider.fetched)
+}
+
+func TestManagerSelfUpdateDevelopmentBuild(t *testing.T) {
+	provider, executable := getSelfProvider(t)
+	manager := getTestManager(t, testManagerOptions{})
+	manager.Providers["github.com"] = provider
+	_, err := manager.SelfUpdate(context.Background(), "dev", executable, false)
+	assert.ErrorIs(t, err, ErrSelfUpdate)
+
+	result, err := manager.SelfUpdate(context.Background(), "dev", executable, true)
+	assert.NoError(t, err)
+	assert.True(t, result.Changed)
+}
+
+func TestManagerSelfUpdateChecksum(t *testing.T) {
+	tests := []struct {
+		name      string
+		checksums string
+	}{
+		{name: "mismatch", checksums: "%[2]s  %[1]s\n"},
+		{name: "missing", checksums: "0000  other.tar.gz\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, executable := getSelfProvider(t)
+			manager := getTestManager(t, testManagerOptions{})
+			manager.Providers["github.com"] = provider
+			provider.checksums = fmt.Sprintf(test.checksums, filepath.Base(provider.archive), "abcd")
+			_, err := manager.SelfUpdate(context.Background(), "1.1.0", executable, false)
+			assert.ErrorIs(t, err, ErrSelfUpdate)
+			content, err := os.ReadFile(executable)
+			assert.NoError(t, err)
+			assert.Equal(t, "#!/bin/sh\necho bpm v1.1.0\n", string(content), "the binary is not replaced")
+		})
+	}
+}
+
+func TestManagerSelfUpdateDryRun(t *testing.T) {
+	provider, executable := getSelfProvider(t)
+	manager := getTestManager(t, testManagerOptions{})
+	manager.Providers["github.com"] = provider
+	manager.config.DryRun = true
+	provider.FetchPackages = map[string]string{"bpm": provider.archive}
+	result, err := manager.SelfUpdate(context.Background(), "1.1.0", executable, false)
+	assert.NoError(t, err)
+	assert.Equal(t, executable, result.Target)
+	assert.True(t, result.Changed)
+	assert.Equal(t, 0, provider.fetched)
+	content, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\necho bpm v1.1.0\n", string(content))
+}
+
+func TestReadChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checksums.txt")
+	err := os.WriteFile(path, []byte("AB12  a.tar.gz\ncd34 *b.tar.gz\n"), 0o644)
+	assert.NoError(t, err)
+	checksum, err := readChecksum(path, "a.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "ab12", checksum)
+	checksum, err = readChecksum(path, "b.tar.gz")
+	assert.NoError(t, err)
+	assert.Equal(t, "cd34", checksum, "binary mode marker")
+	_, err = readChecksum(path, "c.tar.gz")
+	assert.ErrorIs(t, err, ErrSelfUpdate)
+}
@@ -0,0 +1,234 @@
+package bpm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	ShimSourceEnv     = "env"
+	ShimSourceProject = "project"
+	ShimSourceGlobal  = "global"
+
+	// shimHeader marks files in the bin folder written by bpm
+	shimHeader = "# bpm shim, generated by `bpm shim add`"
+)
+
+// ShimVersionEnv returns the environment variable overriding the version a shim runs (e.g. BPM_KUBECTL_VERSION).
+func ShimVersionEnv(name string) string {
+	return "BPM_" + strings.ToUpper(strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)) + "_VERSION"
+}
+
+// ShellQuote quotes value for posix shells.
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// isShimmed reports if the package is run through a shim. The global version of
+// shimmed packages is installed into the store instead of the bin folder.
+func (manager *ManagerImpl) isShimmed(name string) bool {
+	return slices.Contains(manager.StateFile.Shims, name)
+}
+
+// installTarget returns the path the version of the package is installed to.
+func (manager *ManagerImpl) installTarget(pkg *Package, version string) string {
+	if manager.isShimmed(pkg.Name) {
+		return filepath.Join(manager.storeDir(pkg.Name, version), pkg.Name)
+	}
+	return filepath.Join(manager.config.BinFolder, pkg.Name)
+}
+
+// Shims returns the shimmed packages with their global version.
+func (manager *ManagerImpl) Shims(ctx context.Context) ([]ShimStatus, error) {
+	shims := []ShimStatus{}
+	for _, name := range manager.StateFile.Shims {
+		shims = append(shims, manager.shimStatus(name))
+	}
+	return shims, nil
+}
+
+func (manager *ManagerImpl) shimStatus(name string) ShimStatus {
+	status := ShimStatus{
+		Name:   name,
+		Source: ShimSourceGlobal,
+		Shim:   filepath.Join(manager.config.BinFolder, name),
+	}
+	if version, ok := manager.StateFile.Packages[name]; ok {
+		status.Version = version
+		status.Path = filepath.Join(manager.storeDir(name, version), name)
+	}
+	return status
+}
+
+// AddShims replaces the binaries of the packages in the bin folder with shims. The installed
+// binary is moved into the store and stays the global default of the shim.
+func (manager *ManagerImpl) AddShims(ctx context.Context, names []string) ([]ShimStatus, error) {
+	shims := []ShimStatus{}
+	for _, name := range names {
+		if _, ok := manager.Packages[name]; !ok {
+			return shims, fmt.Errorf("%w: %s", ErrPackageNotFound, name)
+		}
+	}
+	script, err := manager.shimScript()
+	if err != nil {
+		return shims, err
+	}
+	for _, name := range names {
+		status := manager.shimStatus(name)
+		if manager.config.DryRun {
+			shims = append(shims, status)
+			continue
+		}
+		if status.Path == "" && !isShim(status.Shim) {
+			// do not replace binaries which are not installed by bpm
+			if _, err := os.Stat(status.Shim); err == nil {
+				return shims, fmt.Errorf("%w: %s exists but is not installed, adopt it first", ErrShim, status.Shim)
+			}
+		}
+		if !manager.isShimmed(name) && status.Path != "" {
+			// keep the installed binary as global version
+			err = os.MkdirAll(filepath.Dir(status.Path), 0o755)
+			if err != nil {
+				return shims, err
+			}
+			err = os.Rename(status.Shim, status.Path)
+			if err != nil {
+				return shims, fmt.Errorf("%w: cannot move %s into the store: %s", ErrShim, name, err)
+			}
+		}
+		err = writeShim(status.Shim, fmt.Sprintf(script, ShellQuote(name)))
+		if err != nil {
+			return shims, err
+		}
+		if !manager.isShimmed(name) {
+			manager.StateFile.Shims = append(manager.StateFile.Shims, name)
+			slices.Sort(manager.StateFile.Shims)
+		}
+		shims = append(shims, status)
+	}
+	return shims, nil
+}
+
+// RemoveShims replaces the shims with a copy of the global version of the packages.
+// The store keeps the versions for projects.
+func (manager *ManagerImpl) RemoveShims(ctx context.Context, names []string) ([]ShimStatus, error) {
+	shims := []ShimStatus{}
+	for _, name := range names {
+		if !manager.isShimmed(name) {
+			return shims, fmt.Errorf("%w: %s is not shimmed", ErrShim, name)
+		}
+	}
+	for _, name := range names {
+		status := manager.shimStatus(name)
+		if manager.config.DryRun {
+			shims = append(shims, status)
+			continue
+		}
+		var err error
+		if status.Path != "" {
+			pkg := manager.Packages[name]
+			pkg.Name = name
+			err = manager.copyBinary(&pkg, status.Path, status.Shim+".tmp", status.Shim, true)
+		} else {
+			err = os.Remove(status.Shim)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		}
+		if err != nil {
+			return shims, fmt.Errorf("%w: cannot remove shim of %s: %s", ErrShim, name, err)
+		}
+		manager.StateFile.Shims = slices.DeleteFunc(manager.StateFile.Shims, func(shim string) bool {
+			return shim == name
+		})
+		shims = append(shims, status)
+	}
+	return shims, nil
+}
+
+// ResolveShim returns the version a shim of the package runs in dir: the version of the
+// environment variable, of the nearest project manifest or the globally installed version.
+func (manager *ManagerImpl) ResolveShim(ctx context.Context, name string, dir string) (ShimStatus, error) {
+	status := ShimStatus{Name: name, Shim: filepath.Join(manager.config.BinFolder, name)}
+	if version := os.Getenv(ShimVersionEnv(name)); version != "" {
+		status.Version = version
+		status.Source = ShimSourceEnv
+	} else if version, project := manager.projectVersion(name, dir); version != "" {
+		status.Version = version
+		status.Source = ShimSourceProject
+		status.Project = project
+	} else if version, ok := manager.StateFile.Packages[name]; ok {
+		status.Version = version
+		status.Source = ShimSourceGlobal
+	} else {
+		return status, fmt.Errorf("%w: %s has no global version and no project version in %s", ErrPackageNotInstalled, name, dir)
+	}
+	if !validVersion(status.Version) {
+		return status, fmt.Errorf("%w: invalid version %q of %s", ErrShim, status.Version, name)
+	}
+	status.Path = filepath.Join(manager.storeDir(name, status.Version), name)
+	if !isExecutableFile(status.Path) {
+		return status, fmt.Errorf("%w: %s %s (%s) is not in the store, run install", ErrPackageNotInstalled, name, status.Version, status.Source)
+	}
+	return status, nil
+}
+
+// projectVersion returns the version of the package in the nearest project manifest of dir.
+// Other tools of the manifest are not checked, so that a broken entry does not break all shims.
+func (manager *ManagerImpl) projectVersion(name string, dir string) (string, string) {
+	path, err := findProject(dir)
+	if err != nil {
+		return "", ""
+	}
+	project := &ProjectFile{}
+	err = loadYaml(path, project)
+	if err != nil {
+		manager.logger.Warn().Msgf("cannot load project manifest %s: %s", path, err)
+		return "", ""
+	}
+	return project.Tools[name], path
+}
+
+// shimScript returns the shim script calling this bpm binary with the config of the manager.
+// The package name has to be inserted with fmt.Sprintf.
+func (manager *ManagerImpl) shimScript() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("%w: cannot find the bpm binary: %s", ErrShim, err)
+	}
+	args := []string{ShellQuote(executable)}
+	if manager.config.Path != "" {
+		args = append(args, "--config", ShellQuote(manager.config.Path))
+	}
+	script := "#!/bin/sh\n" + shimHeader + "\nexec " + strings.ReplaceAll(strings.Join(args, " "), "%", "%%") + " shim-exec %s -- \"$@\"\n"
+	return script, nil
+}
+
+// isShim reports if the file at path is a shim written by bpm.
+func isShim(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(content), shimHeader)
+}
+
+// writeShim writes the shim script atomically.
+func writeShim(path string, script string) error {
+	tmpFile := path + ".tmp"
+	err := os.WriteFile(tmpFile, []byte(script), 0o755)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpFile, path)
+	if err != nil {
+		os.Remove(tmpFile)
+	}
+	return err
+}
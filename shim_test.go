@@ -0,0 +1,147 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShimVersionEnv(t *testing.T) {
+	assert.Equal(t, "BPM_KUBECTL_VERSION", ShimVersionEnv("kubectl"))
+	assert.Equal(t, "BPM_GIT_LFS_VERSION", ShimVersionEnv("git-lfs"))
+}
+
+func TestManagerAddShims(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.0.0"), install: []string{"testName"}})
+	manager.config.Path = "/etc/bpm/config.yaml"
+	binPath := filepath.Join(manager.config.BinFolder, "testName")
+	storePath := filepath.Join(manager.config.StoreFolder, "testName", "v1.0.0", "testName")
+
+	shims, err := manager.AddShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	assert.Equal(t, []ShimStatus{{Name: "testName", Version: "v1.0.0", Source: ShimSourceGlobal, Shim: binPath, Path: storePath}}, shims)
+	assert.Equal(t, []string{"testName"}, manager.StateFile.Shims)
+	assert.True(t, isExecutableFile(storePath), "the installed binary is moved into the store")
+	assert.True(t, isShim(binPath))
+	content, err := os.ReadFile(binPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), " --config '/etc/bpm/config.yaml' shim-exec 'testName' -- \"$@\"\n")
+
+	// adding it again only rewrites the shim
+	_, err = manager.AddShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"testName"}, manager.StateFile.Shims)
+	assert.True(t, isExecutableFile(storePath))
+
+	// the installation of shimmed packages goes into the store
+	manager.Providers[dummyProviderName].(*DummyProvider).LatestPackages["testName"] = "v2.0.0"
+	_, err = manager.Update(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.True(t, isShim(binPath), "the shim is kept on update")
+	assert.True(t, isExecutableFile(filepath.Join(manager.config.StoreFolder, "testName", "v2.0.0", "testName")))
+	issues, err := manager.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.NotContains(t, issueSummary(issues), "checksum error testName testName", "the checksum of the store binary is recorded")
+}
+
+func TestManagerAddShimsErrors(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("")})
+	_, err := manager.AddShims(context.Background(), []string{"missing"})
+	assert.ErrorIs(t, err, ErrPackageNotFound)
+
+	// binaries not installed by bpm are not replaced
+	writeBinary(t, manager, "testName", "#!/bin/sh\necho foreign\n")
+	_, err = manager.AddShims(context.Background(), []string{"testName"})
+	assert.ErrorIs(t, err, ErrShim)
+	assert.False(t, isShim(filepath.Join(manager.config.BinFolder, "testName")))
+	assert.Empty(t, manager.StateFile.Shims)
+}
+
+func TestManagerRemoveShims(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.0.0"), install: []string{"testName"}})
+	binPath := filepath.Join(manager.config.BinFolder, "testName")
+	_, err := manager.RemoveShims(context.Background(), []string{"testName"})
+	assert.ErrorIs(t, err, ErrShim)
+
+	_, err = manager.AddShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	_, err = manager.RemoveShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	assert.Empty(t, manager.StateFile.Shims)
+	assert.False(t, isShim(binPath))
+	expected, err := os.ReadFile(getTestPath("files", "dummy-bin.sh"))
+	assert.NoError(t, err)
+	content, err := os.ReadFile(binPath)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, content, "the global version is copied back")
+	assert.True(t, isExecutableFile(filepath.Join(manager.config.StoreFolder, "testName", "v1.0.0", "testName")), "the store keeps the version")
+}
+
+func TestManagerRemoveShimmedPackage(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.0.0"), install: []string{"testName"}})
+	_, err := manager.AddShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	_, err = manager.Remove(context.Background(), "testName")
+	assert.NoError(t, err)
+	assert.Empty(t, manager.StateFile.Shims)
+	assert.NoFileExists(t, filepath.Join(manager.config.BinFolder, "testName"))
+}
+
+func TestManagerRemoveShimmedPackageWithoutShim(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.0.0"), install: []string{"testName"}})
+	_, err := manager.AddShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(filepath.Join(manager.config.BinFolder, "testName")))
+	_, err = manager.Remove(context.Background(), "testName")
+	assert.ErrorIs(t, err, ErrPackageRemove)
+	assert.NotContains(t, manager.StateFile.Packages, "testName")
+	assert.Empty(t, manager.StateFile.Shims, "the shim entry is removed with the package")
+}
+
+func TestManagerResolveShim(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{provider: dummyBinProvider("v1.0.0"), install: []string{"testName"}})
+	_, err := manager.AddShims(context.Background(), []string{"testName"})
+	assert.NoError(t, err)
+	project := t.TempDir()
+	projectPath := writeProjectFile(t, project, "tools:\n  testName: v0.9.0\n")
+	nested := filepath.Join(project, "sub")
+	assert.NoError(t, os.MkdirAll(nested, 0o755))
+	storePath := func(version string) string {
+		return filepath.Join(manager.config.StoreFolder, "testName", version, "testName")
+	}
+
+	shim, err := manager.ResolveShim(context.Background(), "testName", t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, ShimSourceGlobal, shim.Source)
+	assert.Equal(t, storePath("v1.0.0"), shim.Path)
+
+	_, err = manager.ResolveShim(context.Background(), "testName", nested)
+	assert.ErrorIs(t, err, ErrPackageNotInstalled, "the project version is not installed yet")
+	_, err = manager.InstallProject(context.Background(), project)
+	assert.NoError(t, err)
+	shim, err = manager.ResolveShim(context.Background(), "testName", nested)
+	assert.NoError(t, err)
+	assert.Equal(t, ShimStatus{
+		Name:    "testName",
+		Version: "v0.9.0",
+		Source:  ShimSourceProject,
+		Project: projectPath,
+		Shim:    filepath.Join(manager.config.BinFolder, "testName"),
+		Path:    storePath("v0.9.0"),
+	}, shim)
+
+	t.Setenv(ShimVersionEnv("testName"), "v1.0.0")
+	shim, err = manager.ResolveShim(context.Background(), "testName", nested)
+	assert.NoError(t, err)
+	assert.Equal(t, ShimSourceEnv, shim.Source)
+	assert.Equal(t, storePath("v1.0.0"), shim.Path)
+
+	for _, version := range []string{"../../v1.0.0", "..", "."} {
+		t.Setenv(ShimVersionEnv("testName"), version)
+		_, err = manager.ResolveShim(context.Background(), "testName", nested)
+		assert.ErrorIs(t, err, ErrShim, version)
+	}
+}
@@ -0,0 +1,172 @@
+package bpm
+
+import (
+	"sort"
+	"time"
+)
+
+// PackageStatus describes a configured or installed package.
+type PackageStatus struct {
+	Name     string `yaml:"name" json:"name"`
+	Provider string `yaml:"provider" json:"provider"`
+	// installed version, empty if the package is not installed
+	Version   string `yaml:"version" json:"version"`
+	Installed bool   `yaml:"installed" json:"installed"`
+	// registry of the package file, empty for local packages
+	Registry string `yaml:"registry,omitempty" json:"registry,omitempty"`
+}
+
+// PackageInfo is the package configuration together with the installed version.
+type PackageInfo struct {
+	Package `yaml:",inline"`
+	// installed version, empty if the package is not installed
+	Version string `yaml:"version" json:"version"`
+}
+
+// OutdatedEntry is an installed package with a newer version available.
+type OutdatedEntry struct {
+	Name    string `yaml:"name" json:"name"`
+	Current string `yaml:"current" json:"current"`
+	Latest  string `yaml:"latest" json:"latest"`
+	// newest release which is held back by min_release_age
+	HeldBack   string     `yaml:"held_back,omitempty" json:"held_back,omitempty"`
+	EligibleAt *time.Time `yaml:"eligible_at,omitempty" json:"eligible_at,omitempty"`
+	// newest release not allowed by the update_policy, needs to be installed by hand
+	Blocked string `yaml:"blocked,omitempty" json:"blocked,omitempty"`
+}
+
+// Updatable reports if an eligible update is available.
+func (entry OutdatedEntry) Updatable() bool {
+	return entry.Latest != entry.Current
+}
+
+// AdoptResult is the outcome of adopting an existing binary.
+type AdoptResult struct {
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version" json:"version"`
+	// how the version was detected (command or checksum)
+	Method string `yaml:"method,omitempty" json:"method,omitempty"`
+	Path   string `yaml:"path" json:"path"`
+	Error  string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// SearchResult is a package found by search.
+type SearchResult struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// local, the registry name or the provider of a remote repository
+	Source    string `yaml:"source" json:"source"`
+	URL       string `yaml:"url" json:"url"`
+	Installed bool   `yaml:"installed" json:"installed"`
+	// best matching release asset for this platform (remote results only)
+	Asset string `yaml:"asset,omitempty" json:"asset,omitempty"`
+}
+
+// RegistryStatus describes a configured registry.
+type RegistryStatus struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	URL  string `yaml:"url" json:"url"`
+	// number of packages loaded from the registry
+	Packages int    `yaml:"packages" json:"packages"`
+	Error    string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// VerifyIssue is a problem found by verify.
+type VerifyIssue struct {
+	// check which found the issue (e.g. checksum, path or orphan)
+	Check    string `yaml:"check" json:"check"`
+	Severity string `yaml:"severity" json:"severity"`
+	Package  string `yaml:"package,omitempty" json:"package,omitempty"`
+	Path     string `yaml:"path,omitempty" json:"path,omitempty"`
+	Message  string `yaml:"message" json:"message"`
+}
+
+// ProjectStatus is the project manifest with the tools it needs.
+type ProjectStatus struct {
+	// path of the project manifest
+	Path  string        `yaml:"path" json:"path"`
+	Tools []ProjectTool `yaml:"tools" json:"tools"`
+}
+
+// ProjectTool is a tool version of a project manifest.
+type ProjectTool struct {
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version" json:"version"`
+	// store folder of the version containing the binary
+	Dir       string `yaml:"dir" json:"dir"`
+	Installed bool   `yaml:"installed" json:"installed"`
+}
+
+// ShimStatus is a shimmed package with the version the shim runs.
+type ShimStatus struct {
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version" json:"version"`
+	// env, project or global
+	Source string `yaml:"source" json:"source"`
+	// project manifest the version comes from
+	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+	// path of the shim in the bin folder
+	Shim string `yaml:"shim" json:"shim"`
+	// binary in the store the shim runs
+	Path string `yaml:"path" json:"path"`
+}
+
+// LintIssue is a problem found in a package file by lint.
+type LintIssue struct {
+	Path string `yaml:"path" json:"path"`
+	// line of the problem in the file, 0 if unknown
+	Line int `yaml:"line,omitempty" json:"line,omitempty"`
+	// field of the package with the problem
+	Field   string `yaml:"field,omitempty" json:"field,omitempty"`
+	Message string `yaml:"message" json:"message"`
+}
+
+// InstallResult is the outcome of installing or updating a package.
+type InstallResult struct {
+	Name string `yaml:"name" json:"name"`
+	// version before the operation, empty if the package was not installed
+	PreviousVersion string `yaml:"previous_version" json:"previous_version"`
+	Version         string `yaml:"version" json:"version"`
+	// true if a new version was installed (or would be installed in dry run mode)
+	Changed bool `yaml:"changed" json:"changed"`
+	// planned download and install target, only set in dry run mode
+	DownloadURL string `yaml:"download_url,omitempty" json:"download_url,omitempty"`
+	Target      string `yaml:"target,omitempty" json:"target,omitempty"`
+	// error message if the package failed
+	Error string `yaml:"error,omitempty" json:"error,omitempty"`
+	// release notes between the previous and the new version (update --show-notes)
+	Notes []ReleaseNote `yaml:"notes,omitempty" json:"notes,omitempty"`
+}
+
+// RemoveResult is the outcome of removing a package.
+type RemoveResult struct {
+	Name    string   `yaml:"name" json:"name"`
+	Version string   `yaml:"version" json:"version"`
+	Files   []string `yaml:"files" json:"files"`
+}
+
+// packageStatus returns the status of the package with the given name.
+func (manager *ManagerImpl) packageStatus(name string) PackageStatus {
+	status := PackageStatus{
+		Name:     name,
+		Provider: manager.Packages[name].Provider,
+		Registry: manager.Packages[name].Registry,
+	}
+	status.Version, status.Installed = manager.StateFile.Packages[name]
+	return status
+}
+
+// sortedPackageNames returns the names of all configured packages in a stable order.
+func (manager *ManagerImpl) sortedPackageNames() []string {
+	return sortedKeys(manager.Packages)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,95 @@
+package bpm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// packageHeader contains the fields of a package file needed before decoding it.
+type packageHeader struct {
+	Extends string `yaml:"extends"`
+}
+
+// templatesFolder returns the folder containing the template files.
+func (manager *ManagerImpl) templatesFolder() string {
+	return filepath.Join(manager.config.StateFolder, "templates")
+}
+
+// loadPackageFile loads the package file at path. If the package extends a template
+// the file is decoded on top of the template, so every field of the file overrides
+// the template field (maps like goos and goarch are merged).
+func (manager *ManagerImpl) loadPackageFile(path string) (Package, error) {
+	node := yaml.Node{}
+	err := loadYaml(path, &node)
+	if err != nil {
+		return Package{}, err
+	}
+	return manager.decodePackage(&node)
+}
+
+func (manager *ManagerImpl) decodePackage(node *yaml.Node) (Package, error) {
+	pkg := Package{}
+	header := packageHeader{}
+	err := node.Decode(&header)
+	if err != nil {
+		return pkg, fmt.Errorf("%w: %s", ErrPackageLoadError, err)
+	}
+	if header.Extends != "" {
+		err = manager.applyTemplate(&pkg.PackageV2, header.Extends, nil)
+		if err != nil {
+			return pkg, err
+		}
+	}
+	err = node.Decode(&pkg)
+	return pkg, err
+}
+
+// applyTemplate decodes the template name (and the templates it extends) into pkg.
+func (manager *ManagerImpl) applyTemplate(pkg *PackageV2, name string, seen []string) error {
+	if slices.Contains(seen, name) {
+		return fmt.Errorf("%w: template cycle %s -> %s", ErrPackageTemplate, strings.Join(seen, " -> "), name)
+	}
+	node, err := manager.template(name)
+	if err != nil {
+		return err
+	}
+	header := packageHeader{}
+	err = node.Decode(&header)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrPackageTemplate, name, err)
+	}
+	if header.Extends != "" {
+		err = manager.applyTemplate(pkg, header.Extends, append(seen, name))
+		if err != nil {
+			return err
+		}
+	}
+	err = node.Decode(pkg)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrPackageTemplate, name, err)
+	}
+	return nil
+}
+
+// template returns the template from the config or, if not defined there, from the templates folder.
+func (manager *ManagerImpl) template(name string) (*yaml.Node, error) {
+	if node, ok := manager.config.Templates[name]; ok {
+		return &node, nil
+	}
+	if filepath.Base(name) != name {
+		return nil, fmt.Errorf("%w: invalid template name %q", ErrPackageTemplate, name)
+	}
+	node := yaml.Node{}
+	err := loadYaml(filepath.Join(manager.templatesFolder(), name+".yaml"), &node)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: template %s not found", ErrPackageTemplate, name)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrPackageTemplate, name, err)
+	}
+	return &node, nil
+}
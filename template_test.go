@@ -0,0 +1,100 @@
+package bpm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTemplateConfig = `templates:
+  goreleaser:
+    asset_pattern: "${name}_${version}_${goos}_${goarch}.tar.gz"
+    archive_format: tar.gz
+    goos:
+      linux: Linux
+      darwin: Darwin
+    goarch:
+      amd64: x86_64
+      arm64: arm64
+  cycle-a:
+    extends: cycle-b
+  cycle-b:
+    extends: cycle-a
+`
+
+// testFolderTemplates are the templates folder files of the template tests, folder templates can extend config templates.
+var testFolderTemplates = map[string]string{
+	"goreleaser-zip": "extends: goreleaser\nasset_pattern: \"${name}_${goos}_${goarch}.zip\"\narchive_format: zip\n",
+}
+
+func writePackageFile(t *testing.T, manager *ManagerImpl, name string, content string) string {
+	path := filepath.Join(manager.config.PackagesFolder, name+".yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestManagerLoadPackageFileTemplate(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{config: testTemplateConfig, templates: testFolderTemplates})
+	path := writePackageFile(t, manager, "tool", `schema_version: 2
+name: tool
+provider: github.com
+url: github.com/example/tool
+extends: goreleaser
+goarch:
+  arm64: aarch64
+`)
+	pkg, err := manager.loadPackageFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "goreleaser", pkg.Extends)
+	assert.Equal(t, "tool", pkg.Name)
+	assert.Equal(t, "${name}_${version}_${goos}_${goarch}.tar.gz", pkg.AssetPattern, "template values are not replaced by defaults")
+	assert.Equal(t, "tar.gz", pkg.ArchiveFormat)
+	assert.Equal(t, "${name}", pkg.BinPattern, "defaults are applied to fields missing in the template")
+	assert.Equal(t, map[string]string{"linux": "Linux", "darwin": "Darwin"}, pkg.GOOS)
+	assert.Equal(t, map[string]string{"amd64": "x86_64", "arm64": "aarch64"}, pkg.GOARCH, "maps are merged, the package wins")
+}
+
+func TestManagerLoadPackageFileTemplateChain(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{config: testTemplateConfig, templates: testFolderTemplates})
+	path := writePackageFile(t, manager, "tool", `schema_version: 2
+name: tool
+extends: goreleaser-zip
+archive_format: ""
+`)
+	pkg, err := manager.loadPackageFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "${name}_${goos}_${goarch}.zip", pkg.AssetPattern)
+	assert.Equal(t, "", pkg.ArchiveFormat, "explicit empty values override the template")
+	assert.Equal(t, "Linux", pkg.GOOS["linux"])
+}
+
+func TestManagerLoadPackageFileTemplateErrors(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{config: testTemplateConfig, templates: testFolderTemplates})
+	tests := []struct {
+		name    string
+		extends string
+		err     error
+	}{
+		{name: "missing", extends: "missing", err: ErrPackageTemplate},
+		{name: "cycle", extends: "cycle-a", err: ErrPackageTemplate},
+		{name: "invalid-name", extends: "../packages/tool", err: ErrPackageTemplate},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writePackageFile(t, manager, test.name, "schema_version: 2\nname: tool\nextends: "+test.extends+"\n")
+			_, err := manager.loadPackageFile(path)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestManagerLoadStateTemplates(t *testing.T) {
+	manager := getTestManager(t, testManagerOptions{config: testTemplateConfig, templates: testFolderTemplates})
+	manager.StateFile = getDummyState()
+	assert.NoError(t, manager.SaveState())
+	writePackageFile(t, manager, "tool", "schema_version: 2\nname: tool\nextends: goreleaser\n")
+	assert.NoError(t, manager.LoadState())
+	assert.Equal(t, "tar.gz", manager.Packages["tool"].ArchiveFormat)
+}
@@ -0,0 +1,234 @@
+package bpm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	VerifySeverityError   = "error"
+	VerifySeverityWarning = "warning"
+
+	VerifyCheckMissing     = "missing"
+	VerifyCheckChecksum    = "checksum"
+	VerifyCheckPath        = "path"
+	VerifyCheckShadowed    = "shadowed"
+	VerifyCheckDuplicate   = "duplicate"
+	VerifyCheckPackageFile = "package_file"
+	VerifyCheckMigration   = "migration"
+	VerifyCheckOrphan      = "orphan"
+)
+
+// recordChecksum stores the sha256 of the installed binary in the state.
+func (manager *ManagerImpl) recordChecksum(name string, path string) error {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if manager.StateFile.Checksums == nil {
+		manager.StateFile.Checksums = make(map[string]string)
+	}
+	manager.StateFile.Checksums[name] = checksum
+	return nil
+}
+
+// Verify checks the installation for drift and problems: installed binaries which are missing
+// or modified, a bin folder which is not in PATH, installed binaries shadowed by or duplicated in
+// other PATH entries, package files which cannot be loaded or need a migration and state entries
+// of packages without package file. The issues are sorted by check and package.
+func (manager *ManagerImpl) Verify(ctx context.Context) ([]VerifyIssue, error) {
+	issues := manager.verifyInstalled()
+	issues = append(issues, manager.verifyPath()...)
+	packageIssues, err := manager.verifyPackageFiles()
+	if err != nil {
+		return issues, err
+	}
+	issues = append(issues, packageIssues...)
+	issues = append(issues, manager.verifyOrphans()...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Check != issues[j].Check {
+			return issues[i].Check < issues[j].Check
+		}
+		return issues[i].Package < issues[j].Package
+	})
+	return issues, nil
+}
+
+// verifyInstalled compares the installed binaries and additional files with the state.
+func (manager *ManagerImpl) verifyInstalled() []VerifyIssue {
+	issues := []VerifyIssue{}
+	for _, name := range sortedKeys(manager.StateFile.Packages) {
+		pkg := Package{}
+		pkg.Name = name
+		path := manager.binPath(&pkg)
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			issues = append(issues, VerifyIssue{
+				Check:    VerifyCheckMissing,
+				Severity: VerifySeverityError,
+				Package:  name,
+				Path:     path,
+				Message:  fmt.Sprintf("installed binary cannot be read: %s", err),
+			})
+		} else if expected, ok := manager.StateFile.Checksums[name]; !ok {
+			issues = append(issues, VerifyIssue{
+				Check:    VerifyCheckChecksum,
+				Severity: VerifySeverityWarning,
+				Package:  name,
+				Path:     path,
+				Message:  "no checksum recorded (reinstall the package to record it)",
+			})
+		} else if checksum != expected {
+			issues = append(issues, VerifyIssue{
+				Check:    VerifyCheckChecksum,
+				Severity: VerifySeverityError,
+				Package:  name,
+				Path:     path,
+				Message:  "binary was modified after installation",
+			})
+		}
+		for _, file := range manager.StateFile.Files[name] {
+			if _, err := os.Stat(file); err != nil {
+				issues = append(issues, VerifyIssue{
+					Check:    VerifyCheckMissing,
+					Severity: VerifySeverityWarning,
+					Package:  name,
+					Path:     file,
+					Message:  fmt.Sprintf("installed file is missing: %s", err),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// verifyPath checks that the bin folder is in PATH and the installed binaries are not
+// shadowed by (earlier) or duplicated in (later) other PATH entries.
+func (manager *ManagerImpl) verifyPath() []VerifyIssue {
+	issues := []VerifyIssue{}
+	binFolder := cleanDir(manager.config.BinFolder)
+	binIndex := -1
+	dirs := []string{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		dir = cleanDir(dir)
+		if dir == binFolder {
+			if binIndex < 0 {
+				binIndex = len(dirs)
+			}
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	if binIndex < 0 {
+		issues = append(issues, VerifyIssue{
+			Check:    VerifyCheckPath,
+			Severity: VerifySeverityWarning,
+			Path:     manager.config.BinFolder,
+			Message:  "bin folder is not in PATH",
+		})
+	}
+	for _, name := range sortedKeys(manager.StateFile.Packages) {
+		for i, dir := range dirs {
+			path := filepath.Join(dir, name)
+			if !isExecutableFile(path) {
+				continue
+			}
+			if binIndex >= 0 && i < binIndex {
+				issues = append(issues, VerifyIssue{
+					Check:    VerifyCheckShadowed,
+					Severity: VerifySeverityError,
+					Package:  name,
+					Path:     path,
+					Message:  "comes before the bin folder in PATH and shadows the installed binary",
+				})
+			} else {
+				issues = append(issues, VerifyIssue{
+					Check:    VerifyCheckDuplicate,
+					Severity: VerifySeverityWarning,
+					Package:  name,
+					Path:     path,
+					Message:  "another binary with the same name is in PATH",
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// verifyPackageFiles loads every package file and reports files which cannot be loaded or need a migration.
+func (manager *ManagerImpl) verifyPackageFiles() ([]VerifyIssue, error) {
+	issues := []VerifyIssue{}
+	err := filepath.Walk(manager.config.PackagesFolder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".yaml") {
+			return nil
+		}
+		_, err = manager.loadPackageFile(path)
+		switch {
+		case errors.Is(err, ErrMigrateNeeded):
+			issues = append(issues, VerifyIssue{
+				Check:    VerifyCheckMigration,
+				Severity: VerifySeverityWarning,
+				Path:     path,
+				Message:  "package file uses an old schema version, run migrate",
+			})
+		case err != nil:
+			issues = append(issues, VerifyIssue{
+				Check:    VerifyCheckPackageFile,
+				Severity: VerifySeverityError,
+				Path:     path,
+				Message:  fmt.Sprintf("cannot load package file: %s", err),
+			})
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return issues, nil
+	}
+	return issues, err
+}
+
+// verifyOrphans reports state entries of packages without package file.
+func (manager *ManagerImpl) verifyOrphans() []VerifyIssue {
+	issues := []VerifyIssue{}
+	for _, name := range sortedKeys(manager.StateFile.Packages) {
+		if _, ok := manager.Packages[name]; ok {
+			continue
+		}
+		issues = append(issues, VerifyIssue{
+			Check:    VerifyCheckOrphan,
+			Severity: VerifySeverityWarning,
+			Package:  name,
+			Message:  "installed package has no package file",
+		})
+	}
+	return issues
+}
+
+// cleanDir returns the cleaned absolute path of dir with resolved symlinks if possible.
+func cleanDir(dir string) string {
+	dir = expandPath(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	return filepath.Clean(dir)
+}
+
+// isExecutableFile reports if path is a regular file with an executable bit.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
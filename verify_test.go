@@ -0,0 +1,98 @@
+package bpm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// issueSummary returns check, package and basename of the path of all issues for easier comparison.
+func issueSummary(issues []VerifyIssue) []string {
+	summary := []string{}
+	for _, issue := range issues {
+		summary = append(summary, strings.Join([]string{issue.Check, issue.Severity, issue.Package, filepath.Base(issue.Path)}, " "))
+	}
+	return summary
+}
+
+func TestManagerInstallRecordsChecksum(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	pkg := dummyPackage()
+	manager.Packages[pkg.Name] = *pkg
+	manager.Providers[dummyProviderName] = &DummyProvider{
+		LatestPackages: map[string]string{pkg.Name: "v1.0.0"},
+		FetchPackages:  map[string]string{pkg.Name: getTestPath("files", "dummy-bin.sh")},
+	}
+	_, err := manager.Install(context.Background(), pkg.Name, false)
+	assert.NoError(t, err)
+	checksum, err := fileChecksum(getTestPath("files", "dummy-bin.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, checksum, manager.StateFile.Checksums[pkg.Name])
+
+	_, err = manager.Remove(context.Background(), pkg.Name)
+	assert.NoError(t, err)
+	assert.NotContains(t, manager.StateFile.Checksums, pkg.Name)
+}
+
+func TestManagerVerify(t *testing.T) {
+	manager := getDummyManagerImpl(t)
+	manager.StateFile = getDummyState()
+	for _, name := range []string{"ok", "modified", "unverified", "missing", "shadowed", "orphan"} {
+		pkg := dummyPackage()
+		pkg.Name = name
+		if name != "orphan" {
+			manager.Packages[name] = *pkg
+		}
+		manager.StateFile.Packages[name] = "v1.0.0"
+		if name == "missing" {
+			continue
+		}
+		path := writeBinary(t, manager, name, "#!/bin/sh\necho "+name+"\n")
+		if name != "unverified" {
+			assert.NoError(t, manager.recordChecksum(name, path))
+		}
+	}
+	writeBinary(t, manager, "modified", "#!/bin/sh\necho changed\n")
+	manager.StateFile.Files = map[string][]string{"ok": {filepath.Join(manager.config.DataFolder, "ok.desktop")}}
+
+	before := t.TempDir()
+	after := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(before, "shadowed"), []byte("#!/bin/sh\n"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(after, "ok"), []byte("#!/bin/sh\n"), 0o755))
+	// not executable files are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(after, "modified"), []byte("data"), 0o644))
+
+	assert.NoError(t, os.WriteFile(filepath.Join(manager.config.PackagesFolder, "broken.yaml"), []byte("name: [broken\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(manager.config.PackagesFolder, "old.yaml"), []byte("schema_version: 1\nname: old\n"), 0o644))
+
+	t.Run("bin-folder-in-path", func(t *testing.T) {
+		t.Setenv("PATH", strings.Join([]string{before, manager.config.BinFolder, after}, string(os.PathListSeparator)))
+		issues, err := manager.Verify(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"checksum error modified modified",
+			"checksum warning unverified unverified",
+			"duplicate warning ok ok",
+			"migration warning  old.yaml",
+			"missing error missing missing",
+			"missing warning ok ok.desktop",
+			"orphan warning orphan .",
+			"package_file error  broken.yaml",
+			"shadowed error shadowed shadowed",
+		}, issueSummary(issues))
+	})
+
+	t.Run("bin-folder-not-in-path", func(t *testing.T) {
+		t.Setenv("PATH", before)
+		issues, err := manager.Verify(context.Background())
+		assert.NoError(t, err)
+		summary := issueSummary(issues)
+		assert.Contains(t, summary, "path warning  bin")
+		assert.Contains(t, summary, "duplicate warning shadowed shadowed", "without bin folder in PATH other binaries are duplicates")
+	})
+}